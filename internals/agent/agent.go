@@ -0,0 +1,67 @@
+// Package agent provides a daemon that keeps an unlocked credential in memory and
+// uses it on behalf of other processes of the same user, which connect to it over
+// a Unix socket. Like ssh-agent, the agent never hands out the credential itself.
+// The agent can also hold small items, such as a cached passphrase, in memory.
+package agent
+
+import (
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// EnvSocket is the environment variable that contains the path to the socket of a running agent.
+const EnvSocket = "SECRETHUB_AGENT_SOCK"
+
+// Errors
+var (
+	errAgent = errio.Namespace("agent")
+
+	ErrPeerNotAllowed      = errAgent.Code("peer_not_allowed").Error("connections are only accepted from processes of the user running the agent")
+	ErrUnknownOperation    = errAgent.Code("unknown_operation").ErrorPref("unknown operation: %s")
+	ErrMissingCiphertext   = errAgent.Code("missing_ciphertext").Error("no ciphertext to unwrap")
+	ErrMissingPlaintext    = errAgent.Code("missing_plaintext").Error("no plaintext to wrap")
+	ErrItemNotFound        = errAgent.Code("item_not_found").Error("item not found in the agent")
+	ErrSocketDirNotPrivate = errAgent.Code("socket_dir_not_private").ErrorPref("the directory %s of the agent socket must be owned by the current user and must not be accessible by other users")
+	ErrNotSupported        = errAgent.Code("not_supported").Error("the agent is not supported on this platform, because it cannot verify the user of the processes that connect to it")
+	ErrAgentRunning        = errAgent.Code("already_running").ErrorPref("an agent is already listening on %s")
+	ErrAgentUnreachable    = errAgent.Code("unreachable").ErrorPref("could not connect to the agent: %s")
+	ErrAgentRequestFailed  = errAgent.Code("request_failed").ErrorPref("the agent could not complete the request: %s")
+)
+
+const (
+	operationID     = "id"
+	operationSign   = "sign"
+	operationUnwrap = "unwrap"
+	operationWrap   = "wrap"
+
+	operationGetItem    = "get_item"
+	operationSetItem    = "set_item"
+	operationDeleteItem = "delete_item"
+)
+
+// Credential is an unlocked credential that the agent uses for authentication, encryption and decryption.
+type Credential interface {
+	ID() (string, error)
+	Sign(data []byte) ([]byte, error)
+	SignMethod() string
+	Wrap(plaintext []byte) (*api.EncryptedData, error)
+	Unwrap(ciphertext *api.EncryptedData) ([]byte, error)
+}
+
+// request is sent by a client to the agent. Every request is encoded as a single JSON value.
+type request struct {
+	Operation  string             `json:"operation"`
+	Key        string             `json:"key,omitempty"`
+	Data       []byte             `json:"data,omitempty"`
+	Ciphertext *api.EncryptedData `json:"ciphertext,omitempty"`
+}
+
+// response is sent by the agent for every request.
+type response struct {
+	ID         string             `json:"id,omitempty"`
+	SignMethod string             `json:"sign_method,omitempty"`
+	Data       []byte             `json:"data,omitempty"`
+	Ciphertext *api.EncryptedData `json:"ciphertext,omitempty"`
+	NotFound   bool               `json:"not_found,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
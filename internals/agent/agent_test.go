@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
+)
+
+func newTestSocket(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "secrethub-agent")
+	assert.OK(t, err)
+
+	return filepath.Join(dir, "agent.sock"), func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestAgent(t *testing.T) {
+	socket, cleanup := newTestSocket(t)
+	defer cleanup()
+
+	credential, err := credentials.GenerateRSACredential(1024)
+	assert.OK(t, err)
+
+	l, err := Listen(socket)
+	assert.OK(t, err)
+	defer l.Close()
+
+	go func() {
+		_ = NewServer(credential, 0).Serve(l)
+	}()
+
+	client := NewClient(socket)
+
+	t.Run("id", func(t *testing.T) {
+		expected, err := credential.ID()
+		assert.OK(t, err)
+
+		actual, err := client.ID()
+		assert.OK(t, err)
+		assert.Equal(t, actual, expected)
+		assert.Equal(t, client.SignMethod(), credential.SignMethod())
+	})
+
+	t.Run("sign", func(t *testing.T) {
+		expected, err := credential.Sign([]byte("message"))
+		assert.OK(t, err)
+
+		actual, err := client.Sign([]byte("message"))
+		assert.OK(t, err)
+		assert.Equal(t, actual, expected)
+	})
+
+	t.Run("unwrap", func(t *testing.T) {
+		ciphertext, err := credential.Wrap([]byte("secret"))
+		assert.OK(t, err)
+
+		actual, err := client.Unwrap(ciphertext)
+		assert.OK(t, err)
+		assert.Equal(t, actual, []byte("secret"))
+	})
+
+	t.Run("wrap", func(t *testing.T) {
+		ciphertext, err := client.Wrap([]byte("secret"))
+		assert.OK(t, err)
+
+		actual, err := credential.Unwrap(ciphertext)
+		assert.OK(t, err)
+		assert.Equal(t, actual, []byte("secret"))
+	})
+
+	t.Run("items", func(t *testing.T) {
+		_, err := client.GetItem("key")
+		assert.Equal(t, err, ErrItemNotFound)
+
+		err = client.SetItem("key", []byte("value"))
+		assert.OK(t, err)
+
+		actual, err := client.GetItem("key")
+		assert.OK(t, err)
+		assert.Equal(t, actual, []byte("value"))
+
+		err = client.DeleteItem("key")
+		assert.OK(t, err)
+
+		err = client.DeleteItem("key")
+		assert.Equal(t, err, ErrItemNotFound)
+	})
+
+	t.Run("already running", func(t *testing.T) {
+		_, err := Listen(socket)
+		assert.Equal(t, err, ErrAgentRunning(socket))
+	})
+}
+
+func TestServer_IdleTimeout(t *testing.T) {
+	socket, cleanup := newTestSocket(t)
+	defer cleanup()
+
+	credential, err := credentials.GenerateRSACredential(1024)
+	assert.OK(t, err)
+
+	l, err := Listen(socket)
+	assert.OK(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(credential, 50*time.Millisecond).Serve(l)
+	}()
+
+	select {
+	case err := <-done:
+		assert.OK(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after the idle timeout")
+	}
+
+	_, err = net.Dial("unix", socket)
+	if err == nil {
+		t.Error("expected the agent to stop listening after the idle timeout")
+	}
+}
+
+func TestListen_SocketDir(t *testing.T) {
+	cases := map[string]struct {
+		mode    os.FileMode
+		private bool
+	}{
+		"private": {
+			mode:    0700,
+			private: true,
+		},
+		"readable by group": {
+			mode: 0740,
+		},
+		"accessible by others": {
+			mode: 0701,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			socket, cleanup := newTestSocket(t)
+			defer cleanup()
+
+			dir := filepath.Dir(socket)
+			err := os.Chmod(dir, tc.mode)
+			assert.OK(t, err)
+
+			l, err := Listen(socket)
+			if !tc.private {
+				assert.Equal(t, err, ErrSocketDirNotPrivate(dir))
+				return
+			}
+			assert.OK(t, err)
+			l.Close()
+		})
+	}
+
+	t.Run("created", func(t *testing.T) {
+		socket, cleanup := newTestSocket(t)
+		defer cleanup()
+
+		socket = filepath.Join(filepath.Dir(socket), "agent", "agent.sock")
+		l, err := Listen(socket)
+		assert.OK(t, err)
+		defer l.Close()
+
+		info, err := os.Stat(filepath.Dir(socket))
+		assert.OK(t, err)
+		assert.Equal(t, info.Mode().Perm(), os.FileMode(0700))
+	})
+}
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/auth"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/internals/http"
+)
+
+// Client performs operations with the credential of an agent.
+// It implements the Credential interface.
+type Client struct {
+	socket     string
+	signMethod string
+}
+
+// NewClient creates a client for the agent listening on the given socket.
+func NewClient(socket string) *Client {
+	return &Client{
+		socket: socket,
+	}
+}
+
+// ID returns the identifier of the credential of the agent.
+func (c *Client) ID() (string, error) {
+	resp, err := c.do(request{Operation: operationID})
+	if err != nil {
+		return "", err
+	}
+	c.signMethod = resp.SignMethod
+	return resp.ID, nil
+}
+
+// Sign signs the data with the credential of the agent.
+func (c *Client) Sign(data []byte) ([]byte, error) {
+	resp, err := c.do(request{Operation: operationSign, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// SignMethod returns the signing method of the credential of the agent.
+// It is known after a successful call to ID.
+func (c *Client) SignMethod() string {
+	return c.signMethod
+}
+
+// Unwrap decrypts the ciphertext with the credential of the agent.
+func (c *Client) Unwrap(ciphertext *api.EncryptedData) ([]byte, error) {
+	resp, err := c.do(request{Operation: operationUnwrap, Ciphertext: ciphertext})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// Wrap encrypts the plaintext with the credential of the agent.
+func (c *Client) Wrap(plaintext []byte) (*api.EncryptedData, error) {
+	resp, err := c.do(request{Operation: operationWrap, Data: plaintext})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+// GetItem returns the item stored in the agent under the given key.
+// It returns ErrItemNotFound when no such item is stored.
+func (c *Client) GetItem(key string) ([]byte, error) {
+	resp, err := c.do(request{Operation: operationGetItem, Key: key})
+	if err != nil {
+		return nil, err
+	}
+	if resp.NotFound {
+		return nil, ErrItemNotFound
+	}
+	return resp.Data, nil
+}
+
+// SetItem stores the data in the agent under the given key.
+func (c *Client) SetItem(key string, data []byte) error {
+	_, err := c.do(request{Operation: operationSetItem, Key: key, Data: data})
+	return err
+}
+
+// DeleteItem removes the item stored in the agent under the given key.
+// It returns ErrItemNotFound when no such item is stored.
+func (c *Client) DeleteItem(key string) error {
+	resp, err := c.do(request{Operation: operationDeleteItem, Key: key})
+	if err != nil {
+		return err
+	}
+	if resp.NotFound {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+// Provider returns a credential provider that authenticates and decrypts with the
+// credential of the agent. It returns an error when the agent cannot be reached.
+func (c *Client) Provider() (credentials.Provider, error) {
+	_, err := c.ID()
+	if err != nil {
+		return nil, err
+	}
+	return provider{client: c}, nil
+}
+
+// do sends the request to the agent over a new connection and returns its response.
+func (c *Client) do(req request) (*response, error) {
+	conn, err := net.Dial("unix", c.socket)
+	if err != nil {
+		return nil, ErrAgentUnreachable(err)
+	}
+	defer conn.Close()
+
+	err = json.NewEncoder(conn).Encode(req)
+	if err != nil {
+		return nil, ErrAgentRequestFailed(err)
+	}
+
+	var resp response
+	err = json.NewDecoder(conn).Decode(&resp)
+	if err != nil {
+		return nil, ErrAgentRequestFailed(err)
+	}
+
+	if resp.Error != "" {
+		return nil, ErrAgentRequestFailed(errors.New(resp.Error))
+	}
+	return &resp, nil
+}
+
+// provider implements the credentials.Provider interface for an agent.
+type provider struct {
+	client *Client
+}
+
+// Provide implements the credentials.Provider interface.
+func (p provider) Provide(_ *http.Client) (auth.Authenticator, credentials.Decrypter, error) {
+	return auth.NewHTTPSigner(p.client), p.client, nil
+}
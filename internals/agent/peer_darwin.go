@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// These constants are defined in sys/un.h and sys/ucred.h of macOS.
+const (
+	solLocal      = 0
+	localPeerCred = 0x001
+	xucredVersion = 0
+	xucredNGroups = 16
+)
+
+// xucred is the external representation of the credentials of the peer,
+// as returned by the LOCAL_PEERCRED socket option.
+type xucred struct {
+	Version uint32
+	UID     uint32
+	NGroups int16
+	Groups  [xucredNGroups]uint32
+}
+
+// checkPeer returns an error when the process on the other side of the
+// connection is not running as the same user as the current process.
+func checkPeer(conn net.Conn) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return ErrPeerNotAllowed
+	}
+
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var cred xucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		size := uint32(unsafe.Sizeof(cred))
+		_, _, errno := syscall.Syscall6(syscall.SYS_GETSOCKOPT, fd, solLocal, localPeerCred, uintptr(unsafe.Pointer(&cred)), uintptr(unsafe.Pointer(&size)), 0)
+		if errno != 0 {
+			credErr = errno
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if credErr != nil {
+		return credErr
+	}
+
+	if cred.Version != xucredVersion || int(cred.UID) != os.Getuid() {
+		return ErrPeerNotAllowed
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package agent
+
+import "net"
+
+// checkSocketDir always returns an error, because the user of the processes that
+// connect to the agent cannot be verified on this platform. This makes the agent
+// refuse to start.
+func checkSocketDir(dir string) error {
+	return ErrNotSupported
+}
+
+// checkPeer is not supported on this platform, so every connection is refused.
+func checkPeer(conn net.Conn) error {
+	return ErrPeerNotAllowed
+}
@@ -0,0 +1,211 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Server serves requests for a credential over a listener.
+type Server struct {
+	credential  Credential
+	idleTimeout time.Duration
+	checkPeer   func(net.Conn) error
+
+	mutex sync.Mutex
+	idle  bool
+	timer *time.Timer
+	items map[string][]byte
+}
+
+// NewServer creates a server for the given credential. When no request has been served
+// for the idle timeout, the server stops. An idle timeout of 0 disables the timeout.
+func NewServer(credential Credential, idleTimeout time.Duration) *Server {
+	return &Server{
+		credential:  credential,
+		idleTimeout: idleTimeout,
+		checkPeer:   checkPeer,
+		items:       make(map[string][]byte),
+	}
+}
+
+// Listen creates a Unix socket at the given path that can only be used by the current user.
+// Like ssh-agent, the socket is created in a directory that only the current user can access,
+// so that other users cannot connect to it before its permissions could be restricted. The
+// directory is created when it does not exist yet. A socket left behind by an agent that is
+// no longer running is replaced.
+func Listen(socket string) (net.Listener, error) {
+	dir := filepath.Dir(socket)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkSocketDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err == nil {
+		conn.Close()
+		return nil, ErrAgentRunning(socket)
+	}
+
+	err = os.Remove(socket)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return net.Listen("unix", socket)
+}
+
+// Serve accepts connections on the listener until the listener is closed or the
+// server has been idle for the idle timeout. It returns nil when the server stopped
+// because it was idle.
+func (s *Server) Serve(l net.Listener) error {
+	if s.idleTimeout > 0 {
+		s.mutex.Lock()
+		s.timer = time.AfterFunc(s.idleTimeout, func() {
+			s.mutex.Lock()
+			s.idle = true
+			s.mutex.Unlock()
+			l.Close()
+		})
+		s.mutex.Unlock()
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			idle := s.idle
+			s.mutex.Unlock()
+			if idle {
+				return nil
+			}
+			return err
+		}
+
+		go func() {
+			err := s.serveConn(conn)
+			if err != nil && err != io.EOF {
+				fmt.Fprintf(os.Stderr, "agent: %s\n", err)
+			}
+		}()
+	}
+}
+
+// serveConn handles the requests on a single connection until it is closed
+// or has been idle for the idle timeout.
+func (s *Server) serveConn(conn net.Conn) error {
+	defer conn.Close()
+
+	err := s.checkPeer(conn)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		if s.idleTimeout > 0 {
+			err = conn.SetDeadline(time.Now().Add(s.idleTimeout))
+			if err != nil {
+				return err
+			}
+		}
+
+		var req request
+		err = decoder.Decode(&req)
+		if err != nil {
+			return err
+		}
+
+		s.resetIdleTimer()
+
+		err = encoder.Encode(s.handle(req))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle performs the requested operation with the credential.
+func (s *Server) handle(req request) response {
+	var resp response
+	var err error
+	switch req.Operation {
+	case operationID:
+		resp.ID, err = s.credential.ID()
+		resp.SignMethod = s.credential.SignMethod()
+	case operationSign:
+		resp.Data, err = s.credential.Sign(req.Data)
+	case operationUnwrap:
+		if req.Ciphertext == nil {
+			err = ErrMissingCiphertext
+			break
+		}
+		resp.Data, err = s.credential.Unwrap(req.Ciphertext)
+	case operationWrap:
+		if req.Data == nil {
+			err = ErrMissingPlaintext
+			break
+		}
+		resp.Ciphertext, err = s.credential.Wrap(req.Data)
+	case operationGetItem:
+		resp.Data, resp.NotFound = s.getItem(req.Key)
+	case operationSetItem:
+		s.setItem(req.Key, req.Data)
+	case operationDeleteItem:
+		resp.NotFound = !s.deleteItem(req.Key)
+	default:
+		err = ErrUnknownOperation(req.Operation)
+	}
+
+	if err != nil {
+		return response{Error: err.Error()}
+	}
+	return resp
+}
+
+// getItem returns the item stored under the key and whether it is not found.
+func (s *Server) getItem(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, ok := s.items[key]
+	return data, !ok
+}
+
+// setItem stores the data under the key, replacing any existing item.
+func (s *Server) setItem(key string, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.items[key] = data
+}
+
+// deleteItem removes the item stored under the key and returns whether it existed.
+func (s *Server) deleteItem(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.items[key]
+	delete(s.items, key)
+	return ok
+}
+
+func (s *Server) resetIdleTimer() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.timer != nil {
+		s.timer.Reset(s.idleTimeout)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package agent
+
+import (
+	"os"
+	"syscall"
+)
+
+// checkSocketDir returns an error when the directory is not owned by the current
+// user or when other users have any access to it.
+func checkSocketDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || !info.IsDir() || int(stat.Uid) != os.Getuid() || info.Mode().Perm()&0077 != 0 {
+		return ErrSocketDirNotPrivate(dir)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	ErrUnknownEncoding = errio.Namespace("masker").Code("unknown_encoding").ErrorPref("unknown encoding %s: supported encodings are %s or none")
+)
+
+// minSequenceLength is the minimum length of the lines and encoded variants
+// derived from a secret. Shorter sequences occur too often in regular output
+// to be masked.
+const minSequenceLength = 4
+
+// Encoding is an encoding of which the encoded variants of secrets are masked.
+type Encoding string
+
+// The supported encodings.
+const (
+	EncodingBase64 Encoding = "base64"
+	EncodingURL    Encoding = "url"
+	EncodingJSON   Encoding = "json"
+	EncodingHex    Encoding = "hex"
+)
+
+// AllEncodings contains all supported encodings.
+var AllEncodings = Encodings{EncodingBase64, EncodingURL, EncodingJSON, EncodingHex}
+
+// DefaultEncodings contains the encodings that are masked by default. The url and
+// hex encodings are not masked by default, because the encoded variants of short
+// secrets in them also occur in unrelated output. Masking of encoded secrets can
+// be disabled by parsing the value none.
+var DefaultEncodings = Encodings{EncodingBase64, EncodingJSON}
+
+// encode returns the variants of the secret in the encoding.
+func (e Encoding) encode(secret []byte) [][]byte {
+	switch e {
+	case EncodingBase64:
+		return append(base64Variants(base64.StdEncoding, secret), base64Variants(base64.URLEncoding, secret)...)
+	case EncodingURL:
+		return [][]byte{
+			[]byte(url.QueryEscape(string(secret))),
+			[]byte(url.PathEscape(string(secret))),
+		}
+	case EncodingJSON:
+		return [][]byte{
+			jsonEscape(secret, false),
+			jsonEscape(secret, true),
+		}
+	case EncodingHex:
+		encoded := hex.EncodeToString(secret)
+		return [][]byte{
+			[]byte(encoded),
+			[]byte(strings.ToUpper(encoded)),
+		}
+	}
+	return nil
+}
+
+// base64Variants returns the base64 encoded variants of the secret for all three
+// alignments the secret can have in a base64 encoded stream. The characters that
+// also encode bits of the surrounding data are left out, so that the secret is
+// also matched when it is encoded together with other data.
+func base64Variants(encoding *base64.Encoding, secret []byte) [][]byte {
+	variants := make([][]byte, 0, 3)
+	for offset := 0; offset < 3; offset++ {
+		data := make([]byte, offset+len(secret))
+		copy(data[offset:], secret)
+		encoded := encoding.EncodeToString(data)
+
+		start := (offset*8 + 5) / 6
+		end := (offset + len(secret)) * 8 / 6
+		if end > start {
+			variants = append(variants, []byte(encoded[start:end]))
+		}
+	}
+	return variants
+}
+
+// jsonEscape returns the secret as it is written in a JSON string, without the surrounding quotes.
+func jsonEscape(secret []byte, escapeHTML bool) []byte {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(escapeHTML)
+	// Encoding a string never fails.
+	_ = encoder.Encode(string(secret))
+	return bytes.TrimSuffix(bytes.TrimPrefix(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte(`"`)), []byte(`"`))
+}
+
+// Encodings is a list of encodings that implements the flag.Value interface,
+// so that it can be parsed from a comma-separated flag value.
+type Encodings []Encoding
+
+// ParseEncodings parses a comma-separated list of encodings. The value none
+// results in an empty list.
+func ParseEncodings(value string) (Encodings, error) {
+	encodings := Encodings{}
+	if value == "none" || value == "" {
+		return encodings, nil
+	}
+
+	for _, name := range strings.Split(value, ",") {
+		encoding := Encoding(strings.TrimSpace(name))
+		if !AllEncodings.contains(encoding) {
+			return nil, ErrUnknownEncoding(encoding, AllEncodings)
+		}
+		if !encodings.contains(encoding) {
+			encodings = append(encodings, encoding)
+		}
+	}
+	return encodings, nil
+}
+
+// Set implements the flag.Value interface.
+func (e *Encodings) Set(value string) error {
+	encodings, err := ParseEncodings(value)
+	if err != nil {
+		return err
+	}
+	*e = encodings
+	return nil
+}
+
+// String implements the flag.Value interface.
+func (e Encodings) String() string {
+	if len(e) == 0 {
+		return "none"
+	}
+	names := make([]string, len(e))
+	for i, encoding := range e {
+		names[i] = string(encoding)
+	}
+	return strings.Join(names, ",")
+}
+
+func (e Encodings) contains(encoding Encoding) bool {
+	for _, enc := range e {
+		if enc == encoding {
+			return true
+		}
+	}
+	return false
+}
+
+// Sequences returns the sequences to mask for the given secrets. Next to the secrets
+// themselves, these are the lines of multi-line secrets and the variants of the
+// secrets in the given encodings. Every sequence is only returned once.
+func Sequences(secrets [][]byte, encodings Encodings) [][]byte {
+	seen := make(map[string]bool)
+	var sequences [][]byte
+	add := func(sequence []byte, minLength int) {
+		if len(sequence) < minLength || seen[string(sequence)] {
+			return
+		}
+		seen[string(sequence)] = true
+		sequences = append(sequences, sequence)
+	}
+
+	for _, secret := range secrets {
+		add(secret, 1)
+
+		if bytes.ContainsRune(secret, '\n') {
+			for _, line := range bytes.Split(secret, []byte("\n")) {
+				add(bytes.TrimSpace(line), minSequenceLength)
+			}
+		}
+
+		for _, encoding := range encodings {
+			for _, variant := range encoding.encode(secret) {
+				add(variant, minSequenceLength)
+			}
+		}
+	}
+	return sequences
+}
@@ -0,0 +1,162 @@
+package masker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestParseEncodings(t *testing.T) {
+	cases := map[string]struct {
+		in       string
+		expected Encodings
+		err      error
+	}{
+		"single": {
+			in:       "base64",
+			expected: Encodings{EncodingBase64},
+		},
+		"multiple": {
+			in:       "hex, json",
+			expected: Encodings{EncodingHex, EncodingJSON},
+		},
+		"duplicate": {
+			in:       "url,url",
+			expected: Encodings{EncodingURL},
+		},
+		"none": {
+			in:       "none",
+			expected: Encodings{},
+		},
+		"unknown": {
+			in:  "base64,rot13",
+			err: ErrUnknownEncoding("rot13", AllEncodings),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseEncodings(tc.in)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestSequences(t *testing.T) {
+	cases := map[string]struct {
+		secrets   []string
+		encodings Encodings
+		contains  []string
+		excludes  []string
+	}{
+		"no encodings": {
+			secrets:  []string{"s3cr3t/p@ss"},
+			contains: []string{"s3cr3t/p@ss"},
+			excludes: []string{hex.EncodeToString([]byte("s3cr3t/p@ss"))},
+		},
+		"default encodings": {
+			secrets:   []string{"s3cr3t/p@ss\n"},
+			encodings: DefaultEncodings,
+			contains: []string{
+				"s3cr3t/p@ss\n",
+				base64.StdEncoding.EncodeToString([]byte("s3cr3t/p@ss\n")),
+				`s3cr3t/p@ss\n`,
+			},
+			excludes: []string{
+				url.QueryEscape("s3cr3t/p@ss\n"),
+				hex.EncodeToString([]byte("s3cr3t/p@ss\n")),
+			},
+		},
+		"base64": {
+			secrets:   []string{"s3cr3t/p@ss"},
+			encodings: Encodings{EncodingBase64},
+			contains: []string{
+				strings.TrimRight(base64.StdEncoding.EncodeToString([]byte("s3cr3t/p@ss")), "=")[:14],
+			},
+		},
+		"url": {
+			secrets:   []string{"s3cr3t/p@ss"},
+			encodings: Encodings{EncodingURL},
+			contains:  []string{url.QueryEscape("s3cr3t/p@ss")},
+		},
+		"json": {
+			secrets:   []string{"line1\n\"quoted\" <tag>"},
+			encodings: Encodings{EncodingJSON},
+			contains:  []string{`line1\n\"quoted\" <tag>`, `line1\n\"quoted\" \u003ctag\u003e`},
+		},
+		"hex": {
+			secrets:   []string{"s3cr3t?"},
+			encodings: Encodings{EncodingHex},
+			contains:  []string{"7333637233743f", "7333637233743F"},
+		},
+		"multi-line": {
+			secrets:  []string{"-----BEGIN KEY-----\r\nMIIEvQIBADANBg\r\n-----END KEY-----\r\n"},
+			contains: []string{"-----BEGIN KEY-----", "MIIEvQIBADANBg", "-----END KEY-----"},
+		},
+		"short encoded variants are skipped": {
+			secrets:   []string{"ab"},
+			encodings: Encodings{EncodingBase64},
+			contains:  []string{"ab"},
+			excludes:  []string{"YW", "Fi"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			secrets := make([][]byte, len(tc.secrets))
+			for i, secret := range tc.secrets {
+				secrets[i] = []byte(secret)
+			}
+
+			sequences := Sequences(secrets, tc.encodings)
+
+			seen := make(map[string]bool, len(sequences))
+			for _, sequence := range sequences {
+				assert.Equal(t, seen[string(sequence)], false)
+				seen[string(sequence)] = true
+			}
+			for _, expected := range tc.contains {
+				assert.Equal(t, seen[expected], true)
+			}
+			for _, unexpected := range tc.excludes {
+				assert.Equal(t, seen[unexpected], false)
+			}
+		})
+	}
+}
+
+func TestMaskedWriter_Encodings(t *testing.T) {
+	secret := []byte("s3cr3t/p@ssw0rd")
+
+	cases := map[string]string{
+		"base64":                    base64.StdEncoding.EncodeToString(secret),
+		"base64 url":                base64.URLEncoding.EncodeToString(secret),
+		"base64 with prefix":        base64.StdEncoding.EncodeToString(append([]byte("user:"), secret...)),
+		"base64 with shifted bytes": base64.StdEncoding.EncodeToString(append([]byte("u:"), secret...)),
+		"url":                       url.QueryEscape(string(secret)),
+		"hex":                       hex.EncodeToString(secret),
+		"hex upper case":            strings.ToUpper(hex.EncodeToString(secret)),
+	}
+
+	for name, encoded := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewMaskedWriter(&buf, Sequences([][]byte{secret}, AllEncodings), maskString, time.Millisecond*10)
+
+			go w.Run()
+			_, err := w.Write([]byte("value: " + encoded + "\n"))
+			assert.OK(t, err)
+			assert.OK(t, w.Flush())
+
+			assert.Equal(t, strings.Contains(buf.String(), maskString), true)
+			assert.Equal(t, strings.Contains(buf.String(), encoded), false)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package masker
+
+// matcher is an interface used by MaskedWriter to find matches of sequences to mask.
+type matcher interface {
+	// Read takes in a new byte to match against and returns the length of the
+	// longest sequence that ends at the byte, or 0 if no sequence ends at the byte.
+	Read(byte) int
+	// InProgress returns the number of most recently read bytes that can still
+	// become part of a match.
+	InProgress() int
+	// Reset forgets the matches in progress.
+	Reset()
+}
+
+// ahoCorasick is a matcher that matches all sequences at once using the
+// Aho-Corasick algorithm. Every byte is read in constant amortized time,
+// regardless of the number of sequences. Overlapping matches are all found.
+type ahoCorasick struct {
+	nodes []acNode
+	// root contains the transitions of the root node for every byte,
+	// because most bytes that are read do not continue a match.
+	root  [256]int32
+	state int32
+}
+
+// acNode is a node in the trie of the sequences. A node represents the
+// prefix of one or more sequences that leads from the root to the node.
+type acNode struct {
+	edges []acEdge
+	// fail is the node of the longest proper suffix of the prefix of this
+	// node that is also the prefix of a sequence.
+	fail  int32
+	depth int32
+	// match is the length of the longest sequence that is a suffix of the prefix of this node.
+	match int32
+	// progress is the length of the longest suffix of the prefix of this node that is
+	// the prefix of a longer sequence, so that it can still become part of a match.
+	progress int32
+}
+
+type acEdge struct {
+	b  byte
+	to int32
+}
+
+// newAhoCorasick returns a matcher that matches all non-empty sequences.
+func newAhoCorasick(sequences [][]byte) *ahoCorasick {
+	m := &ahoCorasick{
+		nodes: []acNode{{}},
+	}
+
+	for _, sequence := range sequences {
+		if len(sequence) == 0 {
+			continue
+		}
+
+		node := int32(0)
+		for _, b := range sequence {
+			next, ok := m.nodes[node].next(b)
+			if !ok {
+				next = int32(len(m.nodes))
+				m.nodes = append(m.nodes, acNode{depth: m.nodes[node].depth + 1})
+				m.nodes[node].edges = append(m.nodes[node].edges, acEdge{b: b, to: next})
+			}
+			node = next
+		}
+		m.nodes[node].match = m.nodes[node].depth
+	}
+
+	for _, edge := range m.nodes[0].edges {
+		m.root[edge.b] = edge.to
+	}
+
+	// The fail links are set breadth first, so that the fail links
+	// of all shallower nodes are known when they are followed.
+	queue := make([]int32, 1, len(m.nodes))
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range m.nodes[node].edges {
+			child := edge.to
+			if node != 0 {
+				m.nodes[child].fail = m.transition(m.nodes[node].fail, edge.b)
+			}
+			fail := m.nodes[child].fail
+			if m.nodes[child].match == 0 {
+				m.nodes[child].match = m.nodes[fail].match
+			}
+			if len(m.nodes[child].edges) > 0 {
+				m.nodes[child].progress = m.nodes[child].depth
+			} else {
+				m.nodes[child].progress = m.nodes[fail].progress
+			}
+			queue = append(queue, child)
+		}
+	}
+
+	return m
+}
+
+// next returns the child of the node for the byte, if it exists.
+func (n *acNode) next(b byte) (int32, bool) {
+	for _, edge := range n.edges {
+		if edge.b == b {
+			return edge.to, true
+		}
+	}
+	return 0, false
+}
+
+// transition returns the node that is reached when reading the byte in the given node.
+func (m *ahoCorasick) transition(node int32, b byte) int32 {
+	for node != 0 {
+		next, ok := m.nodes[node].next(b)
+		if ok {
+			return next
+		}
+		node = m.nodes[node].fail
+	}
+	return m.root[b]
+}
+
+// Read takes in a new byte to match against.
+// If the byte completes one or more sequences, the length of the longest of them is returned.
+func (m *ahoCorasick) Read(in byte) int {
+	m.state = m.transition(m.state, in)
+	return int(m.nodes[m.state].match)
+}
+
+// InProgress returns the number of most recently read bytes that form the prefix of a sequence
+// that is not completely read yet.
+//
+// For example, if the sequence is "foobar" and the registered input is "foob", InProgress() returns 4.
+func (m *ahoCorasick) InProgress() int {
+	return int(m.nodes[m.state].progress)
+}
+
+// Reset forgets the current matches.
+func (m *ahoCorasick) Reset() {
+	m.state = 0
+}
@@ -0,0 +1,21 @@
+//go:build go1.18
+// +build go1.18
+
+package masker
+
+import (
+	"testing"
+)
+
+// FuzzAhoCorasick compares the ahoCorasick matcher against the sequenceMatcher
+// and a naive search for two sequences.
+func FuzzAhoCorasick(f *testing.F) {
+	f.Add([]byte("foo"), []byte("bar"), []byte("test foo bar foobar"))
+	f.Add([]byte("foofoobar"), []byte("oof"), []byte("foofoofoobar"))
+	f.Add([]byte("tt"), []byte("t"), []byte("ttattt"))
+	f.Add([]byte("abcd"), []byte("bc"), []byte("abce"))
+
+	f.Fuzz(func(t *testing.T, sequence1, sequence2, input []byte) {
+		compareMatchers(t, [][]byte{sequence1, sequence2}, input)
+	})
+}
@@ -0,0 +1,310 @@
+package masker
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/randchar"
+)
+
+// sequenceMatcher matches a single sequence. MaskedWriter used a sequenceMatcher
+// for every secret before it used the ahoCorasick matcher. It is kept unchanged as a
+// reference to compare the ahoCorasick matcher against. It only shifts a partial
+// match once after a mismatch, so it can keep a partial match that is no longer
+// valid and report a match of bytes that are not the sequence, e.g. "cba" for "bba".
+// This is the only known difference with the ahoCorasick matcher, see
+// TestSequenceMatcher_StalePartialMatch.
+type sequenceMatcher struct {
+	sequence     []byte
+	currentIndex int
+}
+
+// Read takes in a new byte to match against.
+// If the given byte results in a match with sequence, the number of matched bytes is returned.
+func (m *sequenceMatcher) Read(in byte) int {
+	if m.sequence[m.currentIndex] == in {
+		m.currentIndex++
+
+		if m.currentIndex == len(m.sequence) {
+			m.currentIndex = 0
+			return len(m.sequence)
+		}
+		return 0
+	}
+
+	m.currentIndex -= m.findShift()
+	if m.sequence[m.currentIndex] == in {
+		return m.Read(in)
+	}
+	return 0
+}
+
+// findShift checks whether we can also make a partial match by decreasing the currentIndex .
+// For example, if the sequence is foofoobar, if someone inserts foofoofoobar, we still want to match.
+// So after the third f is inserted, the currentIndex is decreased by 3 with the following code.
+func (m *sequenceMatcher) findShift() int {
+	for offset := 1; offset <= m.currentIndex; offset++ {
+		ok := true
+		for i := 0; i < m.currentIndex-offset; i++ {
+			if m.sequence[i] != m.sequence[i+offset] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return offset
+		}
+	}
+	return m.currentIndex
+}
+
+// InProgress returns whether this sequenceMatcher is currently partially matching.
+//
+// For example, if the sequence is "foobar" and the registered input is "foob", InProgress() returns true.
+func (m *sequenceMatcher) InProgress() bool {
+	return m.currentIndex > 0
+}
+
+// Reset forgets the current match.
+func (m *sequenceMatcher) Reset() {
+	m.currentIndex = 0
+}
+
+func TestAhoCorasick(t *testing.T) {
+	cases := map[string]struct {
+		sequences []string
+		input     string
+		expected  string
+	}{
+		"single sequence": {
+			sequences: []string{"test"},
+			input:     "123 test 456",
+			expected:  "    ****    ",
+		},
+		"repeated sequence": {
+			sequences: []string{"test"},
+			input:     "testtest",
+			expected:  "********",
+		},
+		"partial match": {
+			sequences: []string{"foofoobar"},
+			input:     "foofoofoobar",
+			expected:  "   *********",
+		},
+		"overlapping matches": {
+			sequences: []string{"tt"},
+			input:     "ttattt",
+			expected:  "** ***",
+		},
+		"sequence within a sequence": {
+			sequences: []string{"foo", "bar", "testfoobartestfoo"},
+			input:     "testfoobartestfoo bar foo",
+			expected:  "***************** *** ***",
+		},
+		"suffix of another sequence": {
+			sequences: []string{"abcd", "bc"},
+			input:     "abce",
+			expected:  " ** ",
+		},
+		"empty sequence": {
+			sequences: []string{""},
+			input:     "test",
+			expected:  "    ",
+		},
+		"no sequences": {
+			input:    "test",
+			expected: "    ",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sequences := make([][]byte, len(tc.sequences))
+			for i, sequence := range tc.sequences {
+				sequences[i] = []byte(sequence)
+			}
+
+			masked := matchAll(newAhoCorasick(sequences), []byte(tc.input))
+
+			actual := make([]byte, len(masked))
+			for i, m := range masked {
+				actual[i] = ' '
+				if m {
+					actual[i] = '*'
+				}
+			}
+			assert.Equal(t, string(actual), tc.expected)
+		})
+	}
+}
+
+func TestAhoCorasick_InProgress(t *testing.T) {
+	m := newAhoCorasick([][]byte{[]byte("foobar"), []byte("oba")})
+
+	expected := []int{1, 2, 3, 4, 5, 0}
+	for i, b := range []byte("foobar") {
+		m.Read(b)
+		assert.Equal(t, m.InProgress(), expected[i])
+	}
+
+	m = newAhoCorasick([][]byte{[]byte("foo"), []byte("oobaz")})
+	for _, b := range []byte("foob") {
+		m.Read(b)
+	}
+	assert.Equal(t, m.InProgress(), 3)
+
+	m.Reset()
+	assert.Equal(t, m.InProgress(), 0)
+}
+
+// TestAhoCorasick_Random compares the ahoCorasick matcher against the sequenceMatcher
+// and a naive search on random input. The input uses a small alphabet, so that
+// sequences often (partially) overlap.
+func TestAhoCorasick_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	alphabet := []byte("abc")
+	randomBytes := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = alphabet[r.Intn(len(alphabet))]
+		}
+		return b
+	}
+
+	for i := 0; i < 1000; i++ {
+		sequences := make([][]byte, 1+r.Intn(5))
+		for j := range sequences {
+			sequences[j] = randomBytes(1 + r.Intn(6))
+		}
+		compareMatchers(t, sequences, randomBytes(r.Intn(100)))
+	}
+}
+
+// compareMatchers checks that the ahoCorasick matcher masks exactly the bytes
+// that are part of an occurrence of a sequence in the input, which includes all
+// bytes that are masked by the sequenceMatcher. The only exception are the matches
+// of the sequenceMatcher of bytes that are not the sequence, which are not masked.
+func compareMatchers(t testing.TB, sequences [][]byte, input []byte) {
+	masked := matchAll(newAhoCorasick(sequences), input)
+
+	expected := make([]bool, len(input))
+	for _, sequence := range sequences {
+		if len(sequence) == 0 {
+			continue
+		}
+		for i := 0; i+len(sequence) <= len(input); i++ {
+			if bytes.Equal(input[i:i+len(sequence)], sequence) {
+				for j := i; j < i+len(sequence); j++ {
+					expected[j] = true
+				}
+			}
+		}
+	}
+	if fmt.Sprint(masked) != fmt.Sprint(expected) {
+		t.Fatalf("sequences %q in %q: masked %v, expected %v", sequences, input, masked, expected)
+	}
+
+	for _, sequence := range sequences {
+		if len(sequence) == 0 {
+			continue
+		}
+		m := &sequenceMatcher{sequence: sequence}
+		for i, b := range input {
+			n := m.Read(b)
+			if n > 0 && !bytes.Equal(input[i-n+1:i+1], sequence) {
+				// A stale partial match of the sequenceMatcher.
+				continue
+			}
+			for j := i - n + 1; j <= i; j++ {
+				if !masked[j] {
+					t.Fatalf("sequence %q in %q: byte %d masked by the sequenceMatcher is not masked", sequence, input, j)
+				}
+			}
+		}
+	}
+}
+
+// TestSequenceMatcher_StalePartialMatch shows the known difference between the
+// sequenceMatcher and the ahoCorasick matcher: after the mismatch of "c" in "bbc",
+// the sequenceMatcher keeps the partial match "b" and then matches "cba" as "bba".
+func TestSequenceMatcher_StalePartialMatch(t *testing.T) {
+	sequence := []byte("bba")
+	input := []byte("bbcba")
+
+	m := &sequenceMatcher{sequence: sequence}
+	matches := make([]int, len(input))
+	for i, b := range input {
+		matches[i] = m.Read(b)
+	}
+	assert.Equal(t, matches, []int{0, 0, 0, 0, 3})
+	assert.Equal(t, matchAll(newAhoCorasick([][]byte{sequence}), input), []bool{false, false, false, false, false})
+}
+
+// matchAll returns for every byte of the input whether it is part of a match.
+func matchAll(m matcher, input []byte) []bool {
+	masked := make([]bool, len(input))
+	for i, b := range input {
+		n := m.Read(b)
+		for j := i - n + 1; j <= i; j++ {
+			masked[j] = true
+		}
+	}
+	return masked
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	input, err := randchar.NewGenerator(true).Generate(16 * 1024)
+	assert.OK(b, err)
+
+	for _, n := range []int{1, 10, 100, 1000} {
+		sequences := make([][]byte, n)
+		for i := range sequences {
+			sequences[i], err = randchar.NewGenerator(true).Generate(32)
+			assert.OK(b, err)
+		}
+
+		b.Run(fmt.Sprintf("aho-corasick %d sequences", n), func(b *testing.B) {
+			m := newAhoCorasick(sequences)
+			b.SetBytes(int64(len(input)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, c := range input {
+					m.Read(c)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("sequence %d sequences", n), func(b *testing.B) {
+			matchers := make([]*sequenceMatcher, n)
+			for i, sequence := range sequences {
+				matchers[i] = &sequenceMatcher{sequence: sequence}
+			}
+			b.SetBytes(int64(len(input)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, c := range input {
+					for _, m := range matchers {
+						m.Read(c)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewAhoCorasick(b *testing.B) {
+	sequences := make([][]byte, 1000)
+	for i := range sequences {
+		var err error
+		sequences[i], err = randchar.NewGenerator(true).Generate(32)
+		assert.OK(b, err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newAhoCorasick(sequences)
+	}
+}
@@ -7,69 +7,6 @@ import (
 	"time"
 )
 
-// matcher is an interface used by MaskedWriter to find matches of sequences to mask.
-type matcher interface {
-	Read(byte) int
-	InProgress() bool
-	Reset()
-}
-
-type sequenceMatcher struct {
-	sequence     []byte
-	currentIndex int
-}
-
-// Read takes in a new byte to match against.
-// If the given byte results in a match with sequence, the number of matched bytes is returned.
-func (m *sequenceMatcher) Read(in byte) int {
-	if m.sequence[m.currentIndex] == in {
-		m.currentIndex++
-
-		if m.currentIndex == len(m.sequence) {
-			m.currentIndex = 0
-			return len(m.sequence)
-		}
-		return 0
-	}
-
-	m.currentIndex -= m.findShift()
-	if m.sequence[m.currentIndex] == in {
-		return m.Read(in)
-	}
-	return 0
-}
-
-// findShift checks whether we can also make a partial match by decreasing the currentIndex .
-// For example, if the sequence is foofoobar, if someone inserts foofoofoobar, we still want to match.
-// So after the third f is inserted, the currentIndex is decreased by 3 with the following code.
-func (m *sequenceMatcher) findShift() int {
-	for offset := 1; offset <= m.currentIndex; offset++ {
-		ok := true
-		for i := 0; i < m.currentIndex-offset; i++ {
-			if m.sequence[i] != m.sequence[i+offset] {
-				ok = false
-				break
-			}
-		}
-		if ok {
-			return offset
-		}
-	}
-	return m.currentIndex
-}
-
-// InProgress returns whether this sequenceMatcher is currently partially matching.
-//
-// For example, if the sequence is "foobar" and the registered input is "foob", InProgress() returns true.
-func (m *sequenceMatcher) InProgress() bool {
-	return m.currentIndex > 0
-}
-
-// Reset forgets the current match.
-func (m *sequenceMatcher) Reset() {
-	m.currentIndex = 0
-}
-
 // maskByte represents a byte and whether the byte should be masked or not.
 type maskByte struct {
 	byte
@@ -82,11 +19,13 @@ type maskByte struct {
 type MaskedWriter struct {
 	w          io.Writer
 	maskString string
-	matchers   []matcher
+	masks      [][]byte
+	matcher    matcher
 	timeout    time.Duration
 
 	buf             []maskByte
 	incomingBytesCh chan []byte
+	masksCh         chan [][]byte
 	outputTimeoutCh chan struct{}
 	outputCh        chan []maskByte
 	errCh           chan error
@@ -95,24 +34,28 @@ type MaskedWriter struct {
 
 // NewMaskedWriter returns a new MaskedWriter that masks all occurrences of sequences in masks with maskString.
 func NewMaskedWriter(w io.Writer, masks [][]byte, maskString string, timeout time.Duration) *MaskedWriter {
-	matchers := make([]matcher, len(masks))
-	for i, mask := range masks {
-		matchers[i] = &sequenceMatcher{
-			sequence: mask,
-		}
-	}
 	return &MaskedWriter{
 		w:               w,
 		maskString:      maskString,
-		matchers:        matchers,
+		masks:           masks,
+		matcher:         newAhoCorasick(masks),
 		timeout:         timeout,
 		errCh:           make(chan error, 1),
 		outputTimeoutCh: make(chan struct{}, 1),
 		incomingBytesCh: make(chan []byte, 256),
+		masksCh:         make(chan [][]byte),
 		outputCh:        make(chan []maskByte),
 	}
 }
 
+// AddMasks adds sequences to mask in everything written after AddMasks returns
+// and in the bytes that are still buffered because they may be part of a match.
+// The sequences that were already masked are still masked. Run must be running
+// when calling AddMasks.
+func (mw *MaskedWriter) AddMasks(masks [][]byte) {
+	mw.masksCh <- masks
+}
+
 // Write implements Write from io.Writer
 // It is responsible for finding any matches to mask and mark the appropriate bytes as masked.
 // This function never returns an error. These can instead be caught with Flush().
@@ -136,40 +79,47 @@ func (mw *MaskedWriter) Write(p []byte) (n int, err error) {
 func (mw *MaskedWriter) process() {
 	for {
 		select {
+		case masks := <-mw.masksCh:
+			mw.masks = append(mw.masks, masks...)
+			mw.matcher = newAhoCorasick(mw.masks)
+			// Match the bytes that are still buffered against the new masks as well,
+			// so that they are masked and the matches in progress are continued.
+			for i, b := range mw.buf {
+				maskLen := mw.matcher.Read(b.byte)
+				for j := 0; j < maskLen; j++ {
+					mw.buf[i-j].masked = true
+				}
+			}
+			mw.flushBuffer(len(mw.buf) - mw.matcher.InProgress())
 		case <-mw.outputTimeoutCh:
 			// Only flush if there is still nothing send to the output channel.
 			if len(mw.outputCh) == 0 {
-				for _, matcher := range mw.matchers {
-					matcher.Reset()
-				}
-				mw.flushBuffer()
+				mw.matcher.Reset()
+				mw.flushBuffer(len(mw.buf))
 			}
 		case p := <-mw.incomingBytesCh:
 			for _, b := range p {
-				matchInProgress := false
 				mw.buf = append(mw.buf, maskByte{byte: b})
 
-				for _, matcher := range mw.matchers {
-					maskLen := matcher.Read(b)
-					for i := 0; i < maskLen; i++ {
-						mw.buf[len(mw.buf)-1-i].masked = true
-					}
-					matchInProgress = matchInProgress || matcher.InProgress()
-				}
-
-				if !matchInProgress {
-					mw.flushBuffer()
+				maskLen := mw.matcher.Read(b)
+				for i := 0; i < maskLen; i++ {
+					mw.buf[len(mw.buf)-1-i].masked = true
 				}
 			}
+			mw.flushBuffer(len(mw.buf) - mw.matcher.InProgress())
 		}
 	}
 }
 
-func (mw *MaskedWriter) flushBuffer() {
-	tmp := make([]maskByte, len(mw.buf))
+// flushBuffer passes the first n bytes of the buffer to the output channel.
+func (mw *MaskedWriter) flushBuffer(n int) {
+	if n <= 0 {
+		return
+	}
+	tmp := make([]maskByte, n)
 	copy(tmp, mw.buf)
 	mw.outputCh <- tmp
-	mw.buf = mw.buf[:0]
+	mw.buf = append(mw.buf[:0], mw.buf[n:]...)
 }
 
 // Run writes any processed data from the output channel to the underlying io.Writer.
@@ -183,26 +133,23 @@ func (mw *MaskedWriter) Run() {
 	for {
 		select {
 		case output := <-mw.outputCh:
+			out := make([]byte, 0, len(output))
 			for _, b := range output {
-				var err error
 				if b.masked {
 					if !masking {
-						_, err = mw.w.Write([]byte(mw.maskString))
-						if err != nil {
-							mw.errCh <- err
-							return
-						}
+						out = append(out, mw.maskString...)
 					}
 					masking = true
 				} else {
-					_, err = mw.w.Write([]byte{b.byte})
-					if err != nil {
-						mw.errCh <- err
-						return
-					}
+					out = append(out, b.byte)
 					masking = false
 				}
 			}
+			_, err := mw.w.Write(out)
+			if err != nil {
+				mw.errCh <- err
+				return
+			}
 			mw.wg.Add(-len(output))
 		case <-time.After(mw.timeout):
 			// send to the timeout channel if not already done so.
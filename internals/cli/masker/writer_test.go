@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"testing"
 	"time"
 
@@ -320,3 +321,110 @@ func TestNewMaskedWriter_WriteError(t *testing.T) {
 	err = w.Flush()
 	assert.Equal(t, err, expectedErr)
 }
+
+func TestMaskedWriter_AddMasks(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := NewMaskedWriter(&buf, [][]byte{[]byte("foo")}, maskString, time.Millisecond)
+
+	go w.Run()
+	_, err := w.Write([]byte("foo bar\n"))
+	assert.OK(t, err)
+	assert.OK(t, w.Flush())
+
+	w.AddMasks([][]byte{[]byte("bar")})
+
+	_, err = w.Write([]byte("foo bar\n"))
+	assert.OK(t, err)
+	assert.OK(t, w.Flush())
+
+	expected := maskString + " bar\n" + maskString + " " + maskString + "\n"
+	assert.Equal(t, buf.String(), expected)
+}
+
+// notifyWriter writes to w and signals every write on written.
+type notifyWriter struct {
+	w       io.Writer
+	written chan struct{}
+}
+
+func (w notifyWriter) Write(p []byte) (int, error) {
+	defer func() { w.written <- struct{}{} }()
+	return w.w.Write(p)
+}
+
+func TestMaskedWriter_AddMasks_Buffered(t *testing.T) {
+	var buf bytes.Buffer
+	out := notifyWriter{w: &buf, written: make(chan struct{}, 16)}
+
+	w := NewMaskedWriter(out, [][]byte{[]byte("foobar")}, maskString, time.Hour)
+
+	go w.Run()
+	// The foo is buffered, because it can still become foobar.
+	_, err := w.Write([]byte("xfoo"))
+	assert.OK(t, err)
+	<-out.written
+
+	w.AddMasks([][]byte{[]byte("oo")})
+
+	_, err = w.Write([]byte("\n"))
+	assert.OK(t, err)
+	assert.OK(t, w.Flush())
+
+	expected := "xf" + maskString + "\n"
+	assert.Equal(t, buf.String(), expected)
+}
+
+func BenchmarkMaskedWriter(b *testing.B) {
+	input, err := randchar.NewGenerator(true).Generate(16 * 1024)
+	assert.OK(b, err)
+	// The input ends with a newline, so that it never ends in a partial match,
+	// which is only flushed after the timeout.
+	input = append(input, '\n')
+
+	cases := map[string]struct {
+		secrets   int
+		encodings Encodings
+	}{
+		"1 secret":                   {secrets: 1},
+		"10 secrets":                 {secrets: 10},
+		"100 secrets":                {secrets: 100},
+		"10 secrets, all encodings":  {secrets: 10, encodings: AllEncodings},
+		"100 secrets, all encodings": {secrets: 100, encodings: AllEncodings},
+	}
+
+	for name, bc := range cases {
+		b.Run(name, func(b *testing.B) {
+			secrets := make([][]byte, bc.secrets)
+			for i := range secrets {
+				secrets[i], err = randchar.NewGenerator(true).Generate(32)
+				assert.OK(b, err)
+			}
+			masks := Sequences(secrets, bc.encodings)
+
+			w := NewMaskedWriter(ioutil.Discard, masks, maskString, time.Second)
+			go w.Run()
+
+			b.SetBytes(int64(len(input)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := w.Write(input)
+				assert.OK(b, err)
+				assert.OK(b, w.Flush())
+			}
+		})
+	}
+}
+
+func BenchmarkSequences(b *testing.B) {
+	secrets := make([][]byte, 100)
+	for i := range secrets {
+		var err error
+		secrets[i], err = randchar.NewGenerator(true).Generate(32)
+		assert.OK(b, err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		Sequences(secrets, AllEncodings)
+	}
+}
@@ -0,0 +1,96 @@
+// Package pty provides pseudo-terminals to run commands in, so that commands that
+// need a terminal keep working while their output is processed before it is shown.
+package pty
+
+import (
+	"os"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Errors
+var (
+	errPty = errio.Namespace("pty")
+
+	// ErrNotSupported is returned when pseudo-terminals are not available for the platform.
+	ErrNotSupported = errPty.Code("not_supported").Error("pseudo-terminals are not supported on this platform")
+	ErrOpenFailed   = errPty.Code("open_failed").ErrorPref("could not open a pseudo-terminal: %s")
+)
+
+// Terminal is a pseudo-terminal. Commands that are attached to the terminal
+// read their input from it and write their output to it. The output can be
+// read from the Terminal and input can be written to it.
+type Terminal struct {
+	master *os.File
+	slave  *os.File
+}
+
+// Open opens a new pseudo-terminal.
+func Open() (*Terminal, error) {
+	master, slave, err := open()
+	if err == ErrNotSupported {
+		return nil, err
+	} else if err != nil {
+		return nil, ErrOpenFailed(err)
+	}
+	return &Terminal{
+		master: master,
+		slave:  slave,
+	}, nil
+}
+
+// Write writes input for the commands attached to the terminal.
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.master.Write(p)
+}
+
+// InheritSize sets the size of the terminal to the size of the given terminal.
+func (t *Terminal) InheritSize(f *os.File) error {
+	cols, rows, err := terminal.GetSize(int(f.Fd()))
+	if err != nil {
+		return err
+	}
+	return setSize(t.master, rows, cols)
+}
+
+// CloseSlave closes the end of the terminal that is passed to commands. After it is
+// closed, Read returns io.EOF once all commands attached to the terminal have exited
+// and their output has been read. No commands can be attached anymore.
+func (t *Terminal) CloseSlave() error {
+	return t.slave.Close()
+}
+
+// Close closes the terminal.
+func (t *Terminal) Close() error {
+	err := t.slave.Close()
+	masterErr := t.master.Close()
+	if err == nil || isAlreadyClosed(err) {
+		err = masterErr
+	}
+	return err
+}
+
+func isAlreadyClosed(err error) bool {
+	pathErr, ok := err.(*os.PathError)
+	return ok && pathErr.Err == os.ErrClosed
+}
+
+// IsTerminal returns whether the file is a terminal.
+func IsTerminal(f *os.File) bool {
+	return terminal.IsTerminal(int(f.Fd()))
+}
+
+// MakeRaw puts the terminal in raw mode, so that all input is passed on as is,
+// and returns a function that restores the previous mode of the terminal.
+func MakeRaw(f *os.File) (func() error, error) {
+	fd := int(f.Fd())
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return terminal.Restore(fd, state)
+	}, nil
+}
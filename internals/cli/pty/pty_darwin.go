@@ -0,0 +1,56 @@
+package pty
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func open() (*os.File, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name, err := unlockSlave(master)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	slave, err := os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+// unlockSlave grants access to and unlocks the slave of the master and returns its path.
+func unlockSlave(master *os.File) (string, error) {
+	fd := int(master.Fd())
+	err := unix.IoctlSetInt(fd, unix.TIOCPTYGRANT, 0)
+	if err != nil {
+		return "", err
+	}
+
+	err = unix.IoctlSetInt(fd, unix.TIOCPTYUNLK, 0)
+	if err != nil {
+		return "", err
+	}
+
+	name := make([]byte, 128)
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(unix.TIOCPTYGNAME), uintptr(unsafe.Pointer(&name[0])))
+	if errno != 0 {
+		return "", errno
+	}
+
+	end := bytes.IndexByte(name, 0)
+	if end < 0 {
+		end = len(name)
+	}
+	return string(name[:end]), nil
+}
@@ -0,0 +1,41 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+)
+
+func open() (*os.File, *os.File, error) {
+	return nil, nil, ErrNotSupported
+}
+
+// Read reads the output of the commands attached to the terminal.
+// It is never used, as a terminal cannot be opened on this platform.
+func (t *Terminal) Read(p []byte) (int, error) {
+	return t.master.Read(p)
+}
+
+// Attach attaches the command to the terminal.
+// It is never used, as a terminal cannot be opened on this platform.
+func (t *Terminal) Attach(cmd *exec.Cmd) {
+	cmd.Stdin = t.slave
+	cmd.Stdout = t.slave
+	cmd.Stderr = t.slave
+}
+
+// WatchSize does nothing, as a terminal cannot be opened on this platform.
+func (t *Terminal) WatchSize(f *os.File) func() {
+	return func() {}
+}
+
+// IsResizeSignal returns false, as there is no signal for the size of a terminal on this platform.
+func IsResizeSignal(s os.Signal) bool {
+	return false
+}
+
+func setSize(f *os.File, rows, cols int) error {
+	return ErrNotSupported
+}
@@ -0,0 +1,83 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package pty
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Read reads the output of the commands attached to the terminal.
+// It returns io.EOF when the slave is closed and all output has been read.
+func (t *Terminal) Read(p []byte) (int, error) {
+	n, err := t.master.Read(p)
+	if pathErr, ok := err.(*os.PathError); ok && pathErr.Err == syscall.EIO {
+		// Reading the master returns EIO when no process has the slave open anymore.
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// Attach attaches the command to the terminal. The command is started in a new
+// session with the terminal as its controlling terminal, so that the terminal
+// sends it signals like SIGINT and SIGWINCH. Only one command at a time can be
+// attached to the terminal.
+func (t *Terminal) Attach(cmd *exec.Cmd) {
+	cmd.Stdin = t.slave
+	cmd.Stdout = t.slave
+	cmd.Stderr = t.slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid:  true,
+		Setctty: true,
+		// The controlling terminal is the stdin of the command.
+		Ctty: 0,
+	}
+}
+
+// WatchSize resizes the terminal whenever the given terminal is resized,
+// until the returned function is called.
+func (t *Terminal) WatchSize(f *os.File) func() {
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-resized:
+				_ = t.InheritSize(f)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(resized)
+		close(done)
+	}
+}
+
+// IsResizeSignal returns whether the signal is sent when the size of a terminal changes.
+func IsResizeSignal(s os.Signal) bool {
+	return s == syscall.SIGWINCH
+}
+
+func setSize(f *os.File, rows, cols int) error {
+	size := unix.Winsize{
+		Row: uint16(rows),
+		Col: uint16(cols),
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(unix.TIOCSWINSZ), uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package pty
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestTerminal(t *testing.T) {
+	cases := map[string]struct {
+		script   string
+		expected string
+	}{
+		"output": {
+			script:   "echo hello",
+			expected: "hello\r\n",
+		},
+		"stdout and stderr are terminals": {
+			script:   "test -t 0 && test -t 1 && test -t 2 && echo terminal",
+			expected: "terminal\r\n",
+		},
+		"size": {
+			script:   "stty size",
+			expected: "24 80\r\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			terminal, err := Open()
+			assert.OK(t, err)
+			defer terminal.Close()
+
+			err = setSize(terminal.master, 24, 80)
+			assert.OK(t, err)
+
+			cmd := exec.Command("/bin/sh", "-c", tc.script)
+			terminal.Attach(cmd)
+			err = cmd.Start()
+			assert.OK(t, err)
+
+			err = terminal.CloseSlave()
+			assert.OK(t, err)
+
+			output, err := ioutil.ReadAll(terminal)
+			assert.OK(t, err)
+			assert.OK(t, cmd.Wait())
+			assert.Equal(t, string(output), tc.expected)
+		})
+	}
+}
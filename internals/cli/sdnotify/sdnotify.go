@@ -0,0 +1,134 @@
+// Package sdnotify implements the systemd notification protocol (sd_notify), which
+// services use to tell systemd about their state, e.g. that they are ready.
+package sdnotify
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	errSdnotify = errio.Namespace("sdnotify")
+
+	ErrSendFailed   = errSdnotify.Code("send_failed").ErrorPref("could not send a notification to systemd: %s")
+	ErrListenFailed = errSdnotify.Code("listen_failed").ErrorPref("could not listen for notifications: %s")
+)
+
+// Environment variables used by the notification protocol.
+const (
+	// EnvNotifySocket is the path of the socket to send notifications to.
+	EnvNotifySocket = "NOTIFY_SOCKET"
+	// EnvWatchdogUsec is the interval in microseconds in which the watchdog must be notified.
+	EnvWatchdogUsec = "WATCHDOG_USEC"
+	// EnvWatchdogPID is the PID of the process that must notify the watchdog.
+	EnvWatchdogPID = "WATCHDOG_PID"
+)
+
+// Common notifications.
+const (
+	Ready    = "READY=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// maxMessageSize is the maximum size of a notification that is received.
+const maxMessageSize = 4096
+
+// socketAddr returns the address of a notify socket. Paths starting with
+// @ refer to a socket in the abstract namespace.
+func socketAddr(socket string) *net.UnixAddr {
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+	return &net.UnixAddr{Name: socket, Net: "unixgram"}
+}
+
+// Send sends the notification to the socket.
+func Send(socket string, notification string) error {
+	conn, err := net.DialUnix("unixgram", nil, socketAddr(socket))
+	if err != nil {
+		return ErrSendFailed(err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(notification))
+	if err != nil {
+		return ErrSendFailed(err)
+	}
+	return nil
+}
+
+// Proxy receives notifications on its own socket and passes them on to the notify
+// socket of systemd. Systemd only accepts notifications of the main process of a
+// service by default, so notifications of a child process can be passed on by a
+// Proxy that runs in the main process.
+type Proxy struct {
+	target string
+	dir    string
+	conn   *net.UnixConn
+	done   chan struct{}
+}
+
+// NewProxy creates a socket in a new private directory in dir, which passes
+// on the notifications it receives to the target socket.
+func NewProxy(dir string, target string) (*Proxy, error) {
+	socketDir, err := ioutil.TempDir(dir, "secrethub-notify-")
+	if err != nil {
+		return nil, ErrListenFailed(err)
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", socketAddr(filepath.Join(socketDir, "notify")))
+	if err != nil {
+		os.RemoveAll(socketDir)
+		return nil, ErrListenFailed(err)
+	}
+
+	return &Proxy{
+		target: target,
+		dir:    socketDir,
+		conn:   conn,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Socket returns the path of the socket of the proxy.
+func (p *Proxy) Socket() string {
+	return p.conn.LocalAddr().String()
+}
+
+// Run passes on the received notifications until the proxy is closed.
+// Notifications that cannot be passed on are passed to the error handler.
+func (p *Proxy) Run(handleErr func(error)) {
+	defer close(p.done)
+
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, err := p.conn.Read(buf)
+		if err != nil {
+			// The connection is closed.
+			return
+		}
+
+		err = Send(p.target, string(buf[:n]))
+		if err != nil {
+			handleErr(err)
+		}
+	}
+}
+
+// Close stops the proxy and removes its socket.
+func (p *Proxy) Close() error {
+	err := p.conn.Close()
+	<-p.done
+
+	removeErr := os.RemoveAll(p.dir)
+	if err == nil {
+		err = removeErr
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package sdnotify
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+// fakeNotifySocket is a notify socket that records the notifications it receives.
+type fakeNotifySocket struct {
+	path string
+	conn *net.UnixConn
+}
+
+func newFakeNotifySocket(t *testing.T, dir string) *fakeNotifySocket {
+	path := filepath.Join(dir, "systemd-notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.OK(t, err)
+	return &fakeNotifySocket{
+		path: path,
+		conn: conn,
+	}
+}
+
+func (s *fakeNotifySocket) receive(t *testing.T) string {
+	err := s.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	assert.OK(t, err)
+
+	buf := make([]byte, maxMessageSize)
+	n, err := s.conn.Read(buf)
+	assert.OK(t, err)
+	return string(buf[:n])
+}
+
+func TestSend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sdnotify")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	socket := newFakeNotifySocket(t, dir)
+	defer socket.conn.Close()
+
+	err = Send(socket.path, Ready)
+	assert.OK(t, err)
+	assert.Equal(t, socket.receive(t), Ready)
+}
+
+func TestSend_NoSocket(t *testing.T) {
+	err := Send(filepath.Join(os.TempDir(), "sdnotify-does-not-exist"), Ready)
+	assert.Equal(t, err != nil, true)
+}
+
+func TestProxy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sdnotify")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	socket := newFakeNotifySocket(t, dir)
+	defer socket.conn.Close()
+
+	proxy, err := NewProxy(dir, socket.path)
+	assert.OK(t, err)
+	go proxy.Run(func(err error) {
+		t.Error(err)
+	})
+
+	for _, notification := range []string{Ready, "STATUS=Processing\nWATCHDOG=1"} {
+		err = Send(proxy.Socket(), notification)
+		assert.OK(t, err)
+		assert.Equal(t, socket.receive(t), notification)
+	}
+
+	socketPath := proxy.Socket()
+	err = proxy.Close()
+	assert.OK(t, err)
+
+	_, err = os.Stat(socketPath)
+	assert.Equal(t, os.IsNotExist(err), true)
+}
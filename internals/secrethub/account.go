@@ -10,21 +10,23 @@ type AccountCommand struct {
 	io              ui.IO
 	newClient       newClientFunc
 	credentialStore CredentialConfig
+	output          *OutputConfig
 }
 
 // NewAccountCommand creates a new AccountCommand.
-func NewAccountCommand(io ui.IO, newClient newClientFunc, credentialStore CredentialConfig) *AccountCommand {
+func NewAccountCommand(io ui.IO, newClient newClientFunc, credentialStore CredentialConfig, output *OutputConfig) *AccountCommand {
 	return &AccountCommand{
 		io:              io,
 		newClient:       newClient,
 		credentialStore: credentialStore,
+		output:          output,
 	}
 }
 
 // Register registers the command and its sub-commands on the provided Registerer.
 func (cmd *AccountCommand) Register(r command.Registerer) {
 	clause := r.Command("account", "Manage your personal account.")
-	NewAccountInspectCommand(cmd.io, cmd.newClient).Register(clause)
+	NewAccountInspectCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 	NewAccountInitCommand(cmd.io, cmd.newClient, cmd.credentialStore).Register(clause)
 	NewAccountEmailVerifyCommand(cmd.io, cmd.newClient).Register(clause)
 }
@@ -1,9 +1,6 @@
 package secrethub
 
 import (
-	"fmt"
-
-	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
@@ -15,14 +12,16 @@ type AccountInspectCommand struct {
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
+	output        *OutputConfig
 }
 
 // NewAccountInspectCommand creates a new AccountInspectCommand.
-func NewAccountInspectCommand(io ui.IO, newClient newClientFunc) *AccountInspectCommand {
+func NewAccountInspectCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *AccountInspectCommand {
 	return &AccountInspectCommand{
 		io:            io,
 		newClient:     newClient,
 		timeFormatter: NewTimeFormatter(true),
+		output:        output,
 	}
 }
 
@@ -45,24 +44,17 @@ func (cmd *AccountInspectCommand) Run() error {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newOutputUser(user, cmd.timeFormatter))
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintln(cmd.io.Stdout(), output)
-
-	return nil
+	return cmd.output.print(cmd.io.Stdout(), newOutputUser(user, cmd.timeFormatter))
 }
 
 // outputUser is a user friendly JSON representation of a user account.
 type outputUser struct {
-	Username         string
-	FullName         string
-	Email            string `json:",omitempty"`
-	EmailVerified    bool   `json:",omitempty"`
-	CreatedAt        string `json:",omitempty"`
-	PublicAccountKey []byte `json:",omitempty"`
+	Username         string `json:"Username" yaml:"username"`
+	FullName         string `json:"FullName" yaml:"full_name"`
+	Email            string `json:"Email,omitempty" yaml:"email,omitempty"`
+	EmailVerified    bool   `json:"EmailVerified,omitempty" yaml:"email_verified,omitempty"`
+	CreatedAt        string `json:"CreatedAt,omitempty" yaml:"created_at,omitempty"`
+	PublicAccountKey []byte `json:"PublicAccountKey,omitempty" yaml:"public_account_key,omitempty"`
 }
 
 func newOutputUser(user *api.User, timeFormatter TimeFormatter) *outputUser {
@@ -9,21 +9,23 @@ import (
 type ACLCommand struct {
 	io        ui.IO
 	newClient newClientFunc
+	output    *OutputConfig
 }
 
 // NewACLCommand creates a new ACLCommand.
-func NewACLCommand(io ui.IO, newClient newClientFunc) *ACLCommand {
+func NewACLCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *ACLCommand {
 	return &ACLCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
 // Register registers the command and its sub-commands on the provided Registerer.
 func (cmd *ACLCommand) Register(r command.Registerer) {
 	clause := r.Command("acl", "Manage access rules on directories.")
-	NewACLCheckCommand(cmd.io, cmd.newClient).Register(clause)
-	NewACLListCommand(cmd.io, cmd.newClient).Register(clause)
+	NewACLCheckCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
+	NewACLListCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 	NewACLRmCommand(cmd.io, cmd.newClient).Register(clause)
 	NewACLSetCommand(cmd.io, cmd.newClient).Register(clause)
 }
@@ -3,7 +3,6 @@ package secrethub
 import (
 	"fmt"
 	"sort"
-	"text/tabwriter"
 
 	"github.com/secrethub/secrethub-go/pkg/secretpath"
 
@@ -19,13 +18,15 @@ type ACLCheckCommand struct {
 	accountName api.AccountName
 	io          ui.IO
 	newClient   newClientFunc
+	output      *OutputConfig
 }
 
 // NewACLCheckCommand creates a new ACLCheckCommand.
-func NewACLCheckCommand(io ui.IO, newClient newClientFunc) *ACLCheckCommand {
+func NewACLCheckCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *ACLCheckCommand {
 	return &ACLCheckCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -59,22 +60,25 @@ func (cmd *ACLCheckCommand) Run() error {
 
 	sort.Sort(api.SortAccessLevels(levels))
 
-	tabWriter := tabwriter.NewWriter(cmd.io.Stdout(), 0, 4, 4, ' ', 0)
-	fmt.Fprintf(tabWriter, "%s\t%s\n", "PERMISSIONS", "ACCOUNT")
-
+	table := newOutputTable(4, "PERMISSIONS", "ACCOUNT")
 	for _, level := range levels {
-		fmt.Fprintf(tabWriter, "%s\t%s\n",
-			level.Permission,
-			level.Account.Name,
+		table.add(
+			accessLevelOutput{
+				Permission: level.Permission.String(),
+				Account:    level.Account.Name.String(),
+			},
+			level.Permission.String(),
+			level.Account.Name.String(),
 		)
 	}
 
-	err = tabWriter.Flush()
-	if err != nil {
-		return err
-	}
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
 
-	return nil
+// accessLevelOutput is the structured output format of an account's access level.
+type accessLevelOutput struct {
+	Permission string `json:"permission" yaml:"permission"`
+	Account    string `json:"account" yaml:"account"`
 }
 
 func (cmd *ACLCheckCommand) listLevels() ([]*api.AccessLevel, error) {
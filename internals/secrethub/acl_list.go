@@ -1,9 +1,8 @@
 package secrethub
 
 import (
-	"fmt"
 	"sort"
-	"text/tabwriter"
+	"time"
 
 	"github.com/secrethub/secrethub-go/internals/api/uuid"
 
@@ -22,13 +21,15 @@ type ACLListCommand struct {
 	timeFormatter TimeFormatter
 	io            ui.IO
 	newClient     newClientFunc
+	output        *OutputConfig
 }
 
 // NewACLListCommand creates a new ACLListCommand.
-func NewACLListCommand(io ui.IO, newClient newClientFunc) *ACLListCommand {
+func NewACLListCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *ACLListCommand {
 	return &ACLListCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -103,27 +104,34 @@ func (cmd *ACLListCommand) run() error {
 
 	sort.Sort(api.SortDirPaths(paths))
 
-	tabWriter := tabwriter.NewWriter(cmd.io.Stdout(), 0, 4, 4, ' ', 0)
-	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n", "PATH", "PERMISSIONS", "LAST EDITED", "ACCOUNT")
-
+	table := newOutputTable(4, "PATH", "PERMISSIONS", "LAST EDITED", "ACCOUNT")
 	for _, p := range paths {
 		rulesForPath := ruleMap[p]
 		sort.Sort(api.SortAccessRules(rules))
 
 		for _, rule := range rulesForPath {
-			fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n",
-				p,
-				rule.Permission,
+			table.add(
+				accessRuleOutput{
+					Path:          p.String(),
+					Permission:    rule.Permission.String(),
+					LastChangedAt: rule.LastChangedAt,
+					Account:       rule.Account.Name.String(),
+				},
+				p.String(),
+				rule.Permission.String(),
 				cmd.timeFormatter.Format(rule.LastChangedAt.Local()),
-				rule.Account.Name,
+				rule.Account.Name.String(),
 			)
 		}
 	}
 
-	err = tabWriter.Flush()
-	if err != nil {
-		return err
-	}
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
 
-	return nil
+// accessRuleOutput is the structured output format of a listed access rule.
+type accessRuleOutput struct {
+	Path          string    `json:"path" yaml:"path"`
+	Permission    string    `json:"permission" yaml:"permission"`
+	LastChangedAt time.Time `json:"last_changed_at" yaml:"last_changed_at"`
+	Account       string    `json:"account" yaml:"account"`
 }
@@ -0,0 +1,26 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// AgentCommand handles operations on the agent that keeps the credential unlocked.
+type AgentCommand struct {
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewAgentCommand creates a new AgentCommand.
+func NewAgentCommand(io ui.IO, credentialStore CredentialConfig) *AgentCommand {
+	return &AgentCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *AgentCommand) Register(r command.Registerer) {
+	clause := r.Command("agent", "Manage the agent that keeps your credential unlocked for other commands.")
+	NewAgentStartCommand(cmd.io, cmd.credentialStore).Register(clause)
+}
@@ -0,0 +1,129 @@
+package secrethub
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/agent"
+	"github.com/secrethub/secrethub-cli/internals/cli/mlock"
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// Errors
+var (
+	ErrAgentCredentialNotSupported = errMain.Code("agent_credential_not_supported").Error("the agent only supports key credentials")
+)
+
+// The default socket is created in its own directory, which only the current user can access.
+const (
+	defaultAgentSocketDir  = "agent"
+	defaultAgentSocketName = "agent.sock"
+)
+
+// defaultAgentSocket returns the path of the default socket of the agent in the configuration directory.
+func defaultAgentSocket(configDir string) string {
+	return filepath.Join(configDir, defaultAgentSocketDir, defaultAgentSocketName)
+}
+
+// AgentStartCommand runs an agent that keeps the credential unlocked in memory
+// and uses it for other commands of the same user.
+type AgentStartCommand struct {
+	socket          string
+	idleTimeout     time.Duration
+	noMlock         bool
+	io              ui.IO
+	credentialStore CredentialConfig
+}
+
+// NewAgentStartCommand creates a new AgentStartCommand.
+func NewAgentStartCommand(io ui.IO, credentialStore CredentialConfig) *AgentStartCommand {
+	return &AgentStartCommand{
+		io:              io,
+		credentialStore: credentialStore,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *AgentStartCommand) Register(r command.Registerer) {
+	clause := r.Command("start", "Unlock your credential and keep it in memory for other commands until the agent is stopped or idle. The agent runs in the foreground and prints the environment variable that makes other commands use it.")
+	clause.Flag("socket", "The path of the Unix socket to listen on. The directory of the socket must only be accessible by the current user. Defaults to agent/agent.sock in the configuration directory.").PlaceHolder("SOCKET").StringVar(&cmd.socket)
+	clause.Flag("idle-timeout", "Stop the agent when it has not been used for this duration. Set it to 0 to keep the agent running until it is stopped.").Default("1h").DurationVar(&cmd.idleTimeout)
+	clause.Flag("no-mlock", "Do not lock the memory of the agent. Warning: the unlocked credential could then be written to swap.").BoolVar(&cmd.noMlock)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run unlocks the credential and serves it on the socket.
+func (cmd *AgentStartCommand) Run() error {
+	if !cmd.noMlock {
+		if mlock.Supported() {
+			err := mlock.LockMemory()
+			if err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, "Warning: memory locking is not supported on this platform, the unlocked credential could be written to swap.")
+		}
+	}
+
+	key, err := cmd.credentialStore.Import()
+	if err != nil {
+		return err
+	}
+
+	authenticator, _, err := key.Provide(nil)
+	if err != nil {
+		return err
+	}
+
+	credential, ok := authenticator.(agent.Credential)
+	if !ok {
+		return ErrAgentCredentialNotSupported
+	}
+
+	socket := cmd.socket
+	if socket == "" {
+		socket = defaultAgentSocket(cmd.credentialStore.ConfigDir().Path())
+	}
+
+	socket, err = filepath.Abs(socket)
+	if err != nil {
+		return err
+	}
+
+	l, err := agent.Listen(socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socket)
+
+	fmt.Fprintf(cmd.io.Stdout(), "%s=%s; export %s;\n", agent.EnvSocket, socket, agent.EnvSocket)
+
+	var stopped bool
+	var mutex sync.Mutex
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		mutex.Lock()
+		stopped = true
+		mutex.Unlock()
+		l.Close()
+	}()
+
+	err = agent.NewServer(credential, cmd.idleTimeout).Serve(l)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if stopped {
+		return nil
+	}
+	return err
+}
@@ -66,6 +66,8 @@ var (
 type App struct {
 	credentialStore CredentialConfig
 	clientFactory   ClientFactory
+	cacheConfig     *SecretCacheConfig
+	output          *OutputConfig
 	cli             *cli.App
 	io              ui.IO
 	logger          cli.Logger
@@ -86,6 +88,7 @@ func NewApp() *App {
 		"The CLI is configurable through command-line flags and environment variables. " +
 		"Options set on the command-line take precedence over those set in the environment. " +
 		"The format for environment variables is `SECRETHUB_[COMMAND_]FLAG_NAME`."
+	clientFactory := NewClientFactory(store)
 	return &App{
 		cli: cli.NewApp(ApplicationName, help).ExtraEnvVarFunc(
 			func(key string) bool {
@@ -93,7 +96,9 @@ func NewApp() *App {
 			},
 		),
 		credentialStore: store,
-		clientFactory:   NewClientFactory(store),
+		clientFactory:   clientFactory,
+		cacheConfig:     NewSecretCacheConfig(clientFactory),
+		output:          NewOutputConfig(),
 		io:              io,
 		logger:          cli.NewLogger(),
 	}
@@ -112,8 +117,10 @@ func (app *App) Run(args []string) error {
 	RegisterDebugFlag(app.cli, app.logger)
 	RegisterMlockFlag(app.cli)
 	RegisterColorFlag(app.cli)
+	app.output.Register(app.cli)
 	app.credentialStore.Register(app.cli)
 	app.clientFactory.Register(app.cli)
+	app.cacheConfig.Register(app.cli)
 	app.registerCommands()
 
 	app.cli.UsageTemplate(DefaultUsageTemplate)
@@ -156,14 +163,18 @@ func (app *App) Run(args []string) error {
 func (app *App) registerCommands() {
 
 	// Management commands
-	NewOrgCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewRepoCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewACLCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewServiceCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewAccountCommand(app.io, app.clientFactory.NewClient, app.credentialStore).Register(app.cli)
-	NewCredentialCommand(app.io, app.clientFactory, app.credentialStore).Register(app.cli)
+	NewOrgCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewRepoCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewACLCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewServiceCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewAccountCommand(app.io, app.clientFactory.NewClient, app.credentialStore, app.output).Register(app.cli)
+	NewCredentialCommand(app.io, app.clientFactory, app.credentialStore, app.output).Register(app.cli)
 	NewConfigCommand(app.io, app.credentialStore).Register(app.cli)
-	NewEnvCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewEnvCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewRefsCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewSystemdCommand(app.io).Register(app.cli)
+	NewCacheCommand(app.io, app.cacheConfig, app.output).Register(app.cli)
+	NewAgentCommand(app.io, app.credentialStore).Register(app.cli)
 
 	// Commands
 	NewInitCommand(app.io, app.clientFactory.NewUnauthenticatedClient, app.clientFactory.NewClientWithCredentials, app.credentialStore).Register(app.cli)
@@ -171,21 +182,21 @@ func (app *App) registerCommands() {
 	NewWriteCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewReadCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewGenerateSecretCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewLsCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewLsCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
 	NewMkDirCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewRmCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewTreeCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewInspectCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewAuditCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewInjectCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
-	NewRunCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
+	NewTreeCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewInspectCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewAuditCommand(app.io, app.clientFactory.NewClient, app.output).Register(app.cli)
+	NewInjectCommand(app.io, app.clientFactory.NewClient, app.cacheConfig).Register(app.cli)
+	NewRunCommand(app.io, app.clientFactory.NewClient, app.cacheConfig, app.output).Register(app.cli)
 	NewPrintEnvCommand(app.cli, app.io).Register(app.cli)
 
 	// Hidden commands
 	NewClearCommand(app.io).Register(app.cli)
 	NewSetCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 	NewClearClipboardCommand().Register(app.cli)
-	NewKeyringClearCommand().Register(app.cli)
+	NewKeyringClearCommand(app.credentialStore).Register(app.cli)
 
 	demo.NewCommand(app.io, app.clientFactory.NewClient).Register(app.cli)
 }
@@ -2,8 +2,7 @@ package secrethub
 
 import (
 	"fmt"
-	"strings"
-	"text/tabwriter"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
@@ -21,13 +20,15 @@ type AuditCommand struct {
 	timeFormatter TimeFormatter
 	newClient     newClientFunc
 	perPage       int
+	output        *OutputConfig
 }
 
 // NewAuditCommand creates a new audit command.
-func NewAuditCommand(io ui.IO, newClient newClientFunc) *AuditCommand {
+func NewAuditCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *AuditCommand {
 	return &AuditCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -63,13 +64,9 @@ func (cmd *AuditCommand) run() error {
 		return err
 	}
 
-	tabWriter := tabwriter.NewWriter(cmd.io.Stdout(), 0, 4, 4, ' ', 0)
-	header := strings.Join(auditTable.header(), "\t") + "\n"
-	fmt.Fprint(tabWriter, header)
-
+	table := newOutputTable(4, auditTable.header()...)
 	i := 0
 	for {
-		i++
 		event, err := iter.Next()
 		if err == iterator.Done {
 			break
@@ -77,15 +74,22 @@ func (cmd *AuditCommand) run() error {
 			return err
 		}
 
+		record, err := auditTable.output(event)
+		if err != nil {
+			return err
+		}
+
 		row, err := auditTable.row(event)
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprint(tabWriter, strings.Join(row, "\t")+"\n")
+		table.add(record, row...)
 
-		if i == cmd.perPage {
-			err = tabWriter.Flush()
+		// In the table format, the events are printed in pages.
+		i++
+		if cmd.output.Format() == outputFormatTable && i == cmd.perPage {
+			err = cmd.output.printTable(cmd.io.Stdout(), table)
 			if err != nil {
 				return err
 			}
@@ -96,16 +100,11 @@ func (cmd *AuditCommand) run() error {
 			if err != nil {
 				return err
 			}
-			fmt.Fprint(tabWriter, header)
+			table = newOutputTable(4, auditTable.header()...)
 		}
 	}
 
-	err = tabWriter.Flush()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return cmd.output.printTable(cmd.io.Stdout(), table)
 }
 
 func (cmd *AuditCommand) iterAndAuditTable() (secrethub.AuditEventIterator, auditTable, error) {
@@ -153,6 +152,16 @@ func (cmd *AuditCommand) iterAndAuditTable() (secrethub.AuditEventIterator, audi
 type auditTable interface {
 	header() []string
 	row(event api.Audit) ([]string, error)
+	output(event api.Audit) (auditEventOutput, error)
+}
+
+// auditEventOutput is the structured output format of an audit event.
+type auditEventOutput struct {
+	Author    string    `json:"author" yaml:"author"`
+	Event     string    `json:"event" yaml:"event"`
+	Subject   string    `json:"subject,omitempty" yaml:"subject,omitempty"`
+	IPAddress string    `json:"ip_address" yaml:"ip_address"`
+	Date      time.Time `json:"date" yaml:"date"`
 }
 
 func newBaseAuditTable(timeFormatter TimeFormatter) baseAuditTable {
@@ -180,6 +189,20 @@ func (table baseAuditTable) row(event api.Audit, content ...string) ([]string, e
 	return append(res, event.IPAddress, table.timeFormatter.Format(event.LoggedAt)), nil
 }
 
+func (table baseAuditTable) output(event api.Audit) (auditEventOutput, error) {
+	actor, err := getAuditActor(event)
+	if err != nil {
+		return auditEventOutput{}, err
+	}
+
+	return auditEventOutput{
+		Author:    actor,
+		Event:     getEventAction(event),
+		IPAddress: event.IPAddress,
+		Date:      event.LoggedAt,
+	}, nil
+}
+
 func newSecretAuditTable(timeFormatter TimeFormatter) secretAuditTable {
 	return secretAuditTable{
 		baseAuditTable: newBaseAuditTable(timeFormatter),
@@ -222,3 +245,17 @@ func (table repoAuditTable) row(event api.Audit) ([]string, error) {
 
 	return table.baseAuditTable.row(event, subject)
 }
+
+func (table repoAuditTable) output(event api.Audit) (auditEventOutput, error) {
+	subject, err := getAuditSubject(event, table.tree)
+	if err != nil {
+		return auditEventOutput{}, err
+	}
+
+	out, err := table.baseAuditTable.output(event)
+	if err != nil {
+		return auditEventOutput{}, err
+	}
+	out.Subject = subject
+	return out, nil
+}
@@ -0,0 +1,29 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// CacheCommand handles operations on the local cache of secrets.
+type CacheCommand struct {
+	io          ui.IO
+	cacheConfig *SecretCacheConfig
+	output      *OutputConfig
+}
+
+// NewCacheCommand creates a new CacheCommand.
+func NewCacheCommand(io ui.IO, cacheConfig *SecretCacheConfig, output *OutputConfig) *CacheCommand {
+	return &CacheCommand{
+		io:          io,
+		cacheConfig: cacheConfig,
+		output:      output,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *CacheCommand) Register(r command.Registerer) {
+	clause := r.Command("cache", "Manage the local cache of secrets configured with --cache-dir.")
+	NewCacheLsCommand(cmd.io, cmd.cacheConfig, cmd.output).Register(clause)
+	NewCacheClearCommand(cmd.io, cmd.cacheConfig).Register(clause)
+}
@@ -0,0 +1,45 @@
+package secrethub
+
+import (
+	"fmt"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// CacheClearCommand removes all secrets from the local cache.
+type CacheClearCommand struct {
+	io          ui.IO
+	cacheConfig *SecretCacheConfig
+}
+
+// NewCacheClearCommand creates a new CacheClearCommand.
+func NewCacheClearCommand(io ui.IO, cacheConfig *SecretCacheConfig) *CacheClearCommand {
+	return &CacheClearCommand{
+		io:          io,
+		cacheConfig: cacheConfig,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *CacheClearCommand) Register(r command.Registerer) {
+	clause := r.Command("clear", "Remove all secrets from the local cache.")
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run removes all secrets from the local cache.
+func (cmd *CacheClearCommand) Run() error {
+	cache := cmd.cacheConfig.cache(cmd.io.Stdout())
+	if cache == nil {
+		return ErrCacheNotConfigured
+	}
+
+	removed, err := cache.Clear()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Stdout(), "Removed %d secret(s) from the cache.\n", removed)
+	return nil
+}
@@ -0,0 +1,69 @@
+package secrethub
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// CacheLsCommand lists the secrets in the local cache.
+type CacheLsCommand struct {
+	useTimestamps bool
+	io            ui.IO
+	cacheConfig   *SecretCacheConfig
+	output        *OutputConfig
+}
+
+// NewCacheLsCommand creates a new CacheLsCommand.
+func NewCacheLsCommand(io ui.IO, cacheConfig *SecretCacheConfig, output *OutputConfig) *CacheLsCommand {
+	return &CacheLsCommand{
+		io:          io,
+		cacheConfig: cacheConfig,
+		output:      output,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *CacheLsCommand) Register(r command.Registerer) {
+	clause := r.Command("ls", "List the secrets in the local cache.")
+	clause.Alias("list")
+	registerTimestampFlag(clause).BoolVar(&cmd.useTimestamps)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run lists the secrets in the local cache.
+func (cmd *CacheLsCommand) Run() error {
+	cache := cmd.cacheConfig.cache(cmd.io.Stdout())
+	if cache == nil {
+		return ErrCacheNotConfigured
+	}
+
+	entries, err := cache.List()
+	if err != nil {
+		return err
+	}
+
+	timeFormatter := NewTimeFormatter(cmd.useTimestamps)
+	table := newOutputTable(2, "PATH", "VERSION", "CACHED")
+	for _, entry := range entries {
+		table.add(
+			cacheEntryOutput{
+				Path:     entry.Path,
+				Version:  entry.Version,
+				CachedAt: entry.CachedAt,
+			},
+			entry.Path, strconv.Itoa(entry.Version), timeFormatter.Format(entry.CachedAt.Local()),
+		)
+	}
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
+
+// cacheEntryOutput is the structured output format of a cached secret.
+type cacheEntryOutput struct {
+	Path     string    `json:"path" yaml:"path"`
+	Version  int       `json:"version" yaml:"version"`
+	CachedAt time.Time `json:"cached_at" yaml:"cached_at"`
+}
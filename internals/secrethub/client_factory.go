@@ -1,12 +1,18 @@
 package secrethub
 
 import (
+	"fmt"
 	"net/url"
+	"os"
 	"strings"
 
+	"github.com/secrethub/secrethub-cli/internals/agent"
+
+	"github.com/secrethub/secrethub-go/internals/auth"
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/internals/http"
 )
 
 // Errors
@@ -20,6 +26,9 @@ type ClientFactory interface {
 	NewClient() (secrethub.ClientInterface, error)
 	NewClientWithCredentials(credentials.Provider) (secrethub.ClientInterface, error)
 	NewUnauthenticatedClient() (secrethub.ClientInterface, error)
+	// CacheKey returns the key of the credential used by NewClient to encrypt
+	// and decrypt the secret cache with.
+	CacheKey() (credentials.Encrypter, credentials.Decrypter, error)
 	Register(FlagRegisterer)
 }
 
@@ -35,6 +44,7 @@ type clientFactory struct {
 	ServerURL        *url.URL
 	identityProvider string
 	store            CredentialConfig
+	decrypter        credentials.Decrypter
 }
 
 // Register the flags for configuration on a cli application.
@@ -52,13 +62,16 @@ func (f *clientFactory) NewClient() (secrethub.ClientInterface, error) {
 		case "aws":
 			credentialProvider = credentials.UseAWS()
 		case "key":
-			credentialProvider = f.store.Provider()
+			credentialProvider = f.keyProvider()
 		default:
 			return nil, ErrUnknownIdentityProvider(f.identityProvider)
 		}
 
 		options := f.baseClientOptions()
-		options = append(options, secrethub.WithCredentials(credentialProvider))
+		options = append(options, secrethub.WithCredentials(decrypterRecorder{
+			Provider:  credentialProvider,
+			decrypter: &f.decrypter,
+		}))
 
 		client, err := secrethub.NewClient(options...)
 		if err == configdir.ErrCredentialNotFound {
@@ -71,6 +84,51 @@ func (f *clientFactory) NewClient() (secrethub.ClientInterface, error) {
 	return f.client, nil
 }
 
+// keyProvider returns a provider for the key credential. The running agent
+// is preferred over reading the credential from the configured source.
+func (f *clientFactory) keyProvider() credentials.Provider {
+	if socket := f.store.AgentSocket(); socket != "" {
+		provider, err := agent.NewClient(socket).Provider()
+		if err == nil {
+			return provider
+		}
+		fmt.Fprintf(os.Stderr, "Warning: could not use the agent, using the credential instead: %s\n", err)
+	}
+	return f.store.Provider()
+}
+
+// CacheKey returns the key of the credential used by NewClient, so the credential
+// is only loaded once. Only credentials that can also encrypt, such as a key
+// credential or the agent, can be used for the cache.
+func (f *clientFactory) CacheKey() (credentials.Encrypter, credentials.Decrypter, error) {
+	_, err := f.NewClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encrypter, ok := f.decrypter.(credentials.Encrypter)
+	if !ok {
+		return nil, nil, ErrCacheCredentialNotSupported
+	}
+	return encrypter, f.decrypter, nil
+}
+
+// decrypterRecorder is a credentials.Provider that records the decrypter provided by the wrapped provider.
+type decrypterRecorder struct {
+	credentials.Provider
+	decrypter *credentials.Decrypter
+}
+
+// Provide implements the credentials.Provider interface.
+func (r decrypterRecorder) Provide(httpClient *http.Client) (auth.Authenticator, credentials.Decrypter, error) {
+	authenticator, decrypter, err := r.Provider.Provide(httpClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	*r.decrypter = decrypter
+	return authenticator, decrypter, nil
+}
+
 func (f *clientFactory) NewClientWithCredentials(provider credentials.Provider) (secrethub.ClientInterface, error) {
 	options := f.baseClientOptions()
 	options = append(options, secrethub.WithCredentials(provider))
@@ -10,21 +10,23 @@ type CredentialCommand struct {
 	io              ui.IO
 	clientFactory   ClientFactory
 	credentialStore CredentialConfig
+	output          *OutputConfig
 }
 
 // NewCredentialCommand creates a new CredentialCommand.
-func NewCredentialCommand(io ui.IO, clientFactory ClientFactory, credentialStore CredentialConfig) *CredentialCommand {
+func NewCredentialCommand(io ui.IO, clientFactory ClientFactory, credentialStore CredentialConfig, output *OutputConfig) *CredentialCommand {
 	return &CredentialCommand{
 		io:              io,
 		clientFactory:   clientFactory,
 		credentialStore: credentialStore,
+		output:          output,
 	}
 }
 
 // Register registers the command and its sub-commands on the provided Registerer.
 func (cmd *CredentialCommand) Register(r command.Registerer) {
 	clause := r.Command("credential", "Manage your credentials.")
-	NewCredentialListCommand(cmd.io, cmd.clientFactory.NewClient).Register(clause)
+	NewCredentialListCommand(cmd.io, cmd.clientFactory.NewClient, cmd.output).Register(clause)
 	NewCredentialBackupCommand(cmd.io, cmd.clientFactory.NewClient).Register(clause)
 	NewCredentialDisableCommand(cmd.io, cmd.clientFactory.NewClient).Register(clause)
 }
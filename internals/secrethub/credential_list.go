@@ -1,9 +1,7 @@
 package secrethub
 
 import (
-	"fmt"
-	"strings"
-	"text/tabwriter"
+	"time"
 
 	"github.com/secrethub/secrethub-go/pkg/secrethub"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/iterator"
@@ -17,13 +15,15 @@ type CredentialListCommand struct {
 	io            ui.IO
 	newClient     newClientFunc
 	useTimestamps bool
+	output        *OutputConfig
 }
 
 // NewAccountInitCommand creates a new CredentialListCommand.
-func NewCredentialListCommand(io ui.IO, newClient newClientFunc) *CredentialListCommand {
+func NewCredentialListCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *CredentialListCommand {
 	return &CredentialListCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -46,13 +46,7 @@ func (cmd *CredentialListCommand) Run() error {
 
 	timeFormatter := NewTimeFormatter(cmd.useTimestamps)
 
-	w := tabwriter.NewWriter(cmd.io.Stdout(), 0, 2, 2, ' ', 0)
-	fmt.Fprintln(w,
-		"FINGERPRINT\t"+
-			"TYPE\t"+
-			"ENABLED\t"+
-			"CREATED\t"+
-			"DESCRIPTION")
+	table := newOutputTable(2, "FINGERPRINT", "TYPE", "ENABLED", "CREATED", "DESCRIPTION")
 
 	it := client.Credentials().List(&secrethub.CredentialListParams{})
 	for {
@@ -68,20 +62,30 @@ func (cmd *CredentialListCommand) Run() error {
 			enabled = "yes"
 		}
 
-		row := []string{
+		table.add(
+			credentialOutput{
+				Fingerprint: cred.Fingerprint,
+				Type:        string(cred.Type),
+				Enabled:     cred.Enabled,
+				CreatedAt:   cred.CreatedAt,
+				Description: cred.Description,
+			},
 			cred.Fingerprint[:16],
 			string(cred.Type),
 			enabled,
 			timeFormatter.Format(cred.CreatedAt),
 			cred.Description,
-		}
-		fmt.Fprintln(w, strings.Join(row, "\t"))
+		)
 	}
 
-	err = w.Flush()
-	if err != nil {
-		return err
-	}
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
 
-	return nil
+// credentialOutput is the structured output format of a listed credential.
+type credentialOutput struct {
+	Fingerprint string    `json:"fingerprint" yaml:"fingerprint"`
+	Type        string    `json:"type" yaml:"type"`
+	Enabled     bool      `json:"enabled" yaml:"enabled"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+	Description string    `json:"description" yaml:"description"`
 }
@@ -20,7 +20,9 @@ type CredentialConfig interface {
 	Provider() credentials.Provider
 	Import() (credentials.Key, error)
 	ConfigDir() configdir.Dir
+	AgentSocket() string
 	PassphraseReader() credentials.Reader
+	Keyring() Keyring
 
 	Register(FlagRegisterer)
 }
@@ -28,7 +30,8 @@ type CredentialConfig interface {
 // NewCredentialConfig creates a new CredentialConfig.
 func NewCredentialConfig(io ui.IO) CredentialConfig {
 	return &credentialConfig{
-		io: io,
+		io:                     io,
+		passphraseCacheBackend: passphraseCacheBackendKeyring,
 	}
 }
 
@@ -37,6 +40,8 @@ type credentialConfig struct {
 	AccountCredential            string
 	credentialPassphrase         string
 	CredentialPassphraseCacheTTL time.Duration
+	passphraseCacheBackend       passphraseCacheBackendFlag
+	agentSocket                  string
 	io                           ui.IO
 }
 
@@ -44,6 +49,11 @@ func (store *credentialConfig) ConfigDir() configdir.Dir {
 	return store.configDir.Dir
 }
 
+// AgentSocket returns the socket of the running agent that is configured with --agent-sock.
+func (store *credentialConfig) AgentSocket() string {
+	return store.agentSocket
+}
+
 func (store *credentialConfig) IsPassphraseSet() bool {
 	return store.credentialPassphrase != ""
 }
@@ -54,7 +64,9 @@ func (store *credentialConfig) Register(r FlagRegisterer) {
 	r.Flag("credential", "Use a specific account credential to authenticate to the API. This overrides the credential stored in the configuration directory.").StringVar(&store.AccountCredential)
 	r.Flag("p", "").Short('p').Hidden().NoEnvar().StringVar(&store.credentialPassphrase) // Shorthand -p is deprecated. Use --credential-passphrase instead.
 	r.Flag("credential-passphrase", "The passphrase to unlock your credential file. When set, it will not prompt for the passphrase, nor cache it in the OS keyring. Please only use this if you know what you're doing and ensure your passphrase doesn't end up in bash history.").StringVar(&store.credentialPassphrase)
-	r.Flag("credential-passphrase-cache-ttl", "Cache the credential passphrase in the OS keyring for this duration. The cache is automatically cleared after the timer runs out. Each time the passphrase is read from the cache the timer is reset. Passphrase caching is turned on by default for 5 minutes. Turn it off by setting the duration to 0.").Default("5m").DurationVar(&store.CredentialPassphraseCacheTTL)
+	r.Flag("credential-passphrase-cache-ttl", "Cache the credential passphrase in the OS keyring, or the configured --passphrase-cache-backend, for this duration. The cache is automatically cleared after the timer runs out. Each time the passphrase is read from the cache the timer is reset. Passphrase caching is turned on by default for 5 minutes. Turn it off by setting the duration to 0.").Default("5m").DurationVar(&store.CredentialPassphraseCacheTTL)
+	r.Flag("passphrase-cache-backend", "Where to cache the credential passphrase. The options are keyring (the OS keyring), file (an encrypted file in the configuration directory, with its key in $XDG_RUNTIME_DIR, which must be set), kernel (the Linux kernel keyring of the user) and agent (the memory of the `secrethub agent` at --agent-sock).").Default(passphraseCacheBackendKeyring).HintOptions(passphraseCacheBackendKeyring, passphraseCacheBackendFile, passphraseCacheBackendKernel, passphraseCacheBackendAgent).SetValue(&store.passphraseCacheBackend)
+	r.Flag("agent-sock", "The socket of a running `secrethub agent`. When set, the agent is used instead of reading the credential, if the agent can be reached.").PlaceHolder("SOCKET").StringVar(&store.agentSocket)
 }
 
 // Provider retrieves a credential from the store.
@@ -77,5 +89,11 @@ func (store *credentialConfig) getCredentialReader() credentials.Reader {
 
 // PassphraseReader returns a PassphraseReader configured by the flags.
 func (store *credentialConfig) PassphraseReader() credentials.Reader {
-	return NewPassphraseReader(store.io, store.credentialPassphrase, store.CredentialPassphraseCacheTTL)
+	cleaner := NewKeyringCleaner(store.passphraseCacheBackend.String(), store.ConfigDir().Path(), store.agentSocket)
+	return NewPassphraseReader(store.io, store.credentialPassphrase, store.CredentialPassphraseCacheTTL, store.Keyring(), cleaner)
+}
+
+// Keyring returns the Keyring of the configured passphrase cache backend.
+func (store *credentialConfig) Keyring() Keyring {
+	return newKeyringBackend(store.passphraseCacheBackend.String(), store.ConfigDir(), store.agentSocket)
 }
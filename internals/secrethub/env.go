@@ -9,13 +9,15 @@ import (
 type EnvCommand struct {
 	io        ui.IO
 	newClient newClientFunc
+	output    *OutputConfig
 }
 
 // NewEnvCommand creates a new EnvCommand.
-func NewEnvCommand(io ui.IO, newClient newClientFunc) *EnvCommand {
+func NewEnvCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *EnvCommand {
 	return &EnvCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -24,5 +26,6 @@ func (cmd *EnvCommand) Register(r command.Registerer) {
 	clause := r.Command("env", "[BETA] Manage environment variables.").Hidden()
 	clause.HelpLong("This command is hidden because it is still in beta. Future versions may break.")
 	NewEnvReadCommand(cmd.io, cmd.newClient).Register(clause)
-	NewEnvListCommand(cmd.io).Register(clause)
+	NewEnvListCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
+	NewEnvExportCommand(cmd.io, cmd.newClient).Register(clause)
 }
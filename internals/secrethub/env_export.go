@@ -0,0 +1,194 @@
+package secrethub
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/cli/validation"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// Errors
+var (
+	ErrInvalidExportName = errMain.Code("invalid_export_name").ErrorPref("cannot export variable '%s': the name must only contain letters, digits and underscores and cannot start with a digit")
+)
+
+// The shells that the environment can be exported to.
+const (
+	shellBash       = "bash"
+	shellZsh        = "zsh"
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+	shellDirenv     = "direnv"
+)
+
+// EnvExportCommand is a command to print the environment as statements that set it in a shell.
+type EnvExportCommand struct {
+	io          ui.IO
+	newClient   newClientFunc
+	environment *environment
+	shell       string
+	unset       bool
+}
+
+// NewEnvExportCommand creates a new EnvExportCommand.
+func NewEnvExportCommand(io ui.IO, newClient newClientFunc) *EnvExportCommand {
+	return &EnvExportCommand{
+		io:          io,
+		newClient:   newClient,
+		environment: newEnvironment(io, newClient),
+	}
+}
+
+// Register adds a CommandClause and it's args and flags to a Registerer.
+func (cmd *EnvExportCommand) Register(r command.Registerer) {
+	clause := r.Command("export", "[BETA] Print the statements that set the environment in a shell.")
+	clause.HelpLong("Prints the variables of the environment, with the secrets in them, as statements that set them in the current shell, e.g.:\n\n" +
+		"  eval \"$(secrethub env export --shell bash)\"\n" +
+		"  secrethub env export --shell fish | source\n" +
+		"  secrethub env export --shell powershell | Out-String | Invoke-Expression\n\n" +
+		"In an .envrc file of direnv, use `eval \"$(secrethub env export --shell direnv)\"`, which also reloads the environment when the env file changes. " +
+		"Use --unset to print the statements that remove the variables again. " +
+		"Variables that are only defined in the OS environment are left out." +
+		"\n\nThis command is hidden because it is still in beta. Future versions may break.")
+	clause.Flag("shell", "The shell to print the statements for. The options are bash, zsh, fish, powershell and direnv.").Default(shellBash).EnumVar(&cmd.shell, shellBash, shellZsh, shellFish, shellPowerShell, shellDirenv)
+	clause.Flag("unset", "Print the statements that remove the variables from the shell, without reading the secrets.").BoolVar(&cmd.unset)
+
+	cmd.environment.register(clause)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run executes the command.
+func (cmd *EnvExportCommand) Run() error {
+	sourced, err := cmd.environment.sourcedEnv()
+	if err != nil {
+		return err
+	}
+
+	env := make(map[string]value, len(sourced))
+	names := make([]string, 0, len(sourced))
+	for name, values := range sourced {
+		used := values[len(values)-1]
+		if used.source == envSourceOS {
+			continue
+		}
+		env[name] = used.value
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// The names are printed unquoted, so a name that is not a POSIX name could
+	// inject commands into the shell that evaluates the statements.
+	for _, name := range names {
+		if !validation.IsEnvarNamePosix(name) {
+			return ErrInvalidExportName(name)
+		}
+	}
+
+	shell := shells[cmd.shell]
+	if cmd.unset {
+		for _, name := range names {
+			fmt.Fprintln(cmd.io.Stdout(), shell.unset(name))
+		}
+		return nil
+	}
+
+	secretReader := newCachingSecretReader(cmd.newClient, nil)
+	values := make([]value, 0, len(env))
+	for _, name := range names {
+		values = append(values, env[name])
+	}
+	err = prefetchSecrets(secretReader, values...)
+	if err != nil {
+		return err
+	}
+
+	// All secrets are read before anything is printed, so that a
+	// shell never evaluates the statements of a partial environment.
+	statements := make([]string, 0, len(names)+1)
+	if cmd.shell == shellDirenv && cmd.environment.envFile != "" {
+		statements = append(statements, "watch_file "+quotePOSIXShell(cmd.environment.envFile))
+	}
+	for _, name := range names {
+		value, err := env[name].resolve(secretReader)
+		if err != nil {
+			return err
+		}
+		statements = append(statements, shell.export(name, value))
+	}
+
+	for _, statement := range statements {
+		fmt.Fprintln(cmd.io.Stdout(), statement)
+	}
+	return nil
+}
+
+// shellSyntax defines the statements that set and remove variables in a shell.
+// The names of the variables are checked to be valid POSIX names before any
+// statement is printed, so they are never quoted.
+type shellSyntax struct {
+	export func(name, value string) string
+	unset  func(name string) string
+}
+
+var (
+	posixShell = shellSyntax{
+		export: func(name, value string) string {
+			return "export " + name + "=" + quotePOSIXShell(value)
+		},
+		unset: func(name string) string {
+			return "unset " + name
+		},
+	}
+
+	shells = map[string]shellSyntax{
+		shellBash:   posixShell,
+		shellZsh:    posixShell,
+		shellDirenv: posixShell,
+		shellFish: {
+			export: func(name, value string) string {
+				return "set -gx " + name + " " + quoteFish(value)
+			},
+			unset: func(name string) string {
+				return "set -e " + name
+			},
+		},
+		shellPowerShell: {
+			export: func(name, value string) string {
+				return "$env:" + name + " = " + quotePowerShell(value)
+			},
+			unset: func(name string) string {
+				return "Remove-Item -Path Env:" + name + " -ErrorAction SilentlyContinue"
+			},
+		},
+	}
+)
+
+// quotePOSIXShell returns the value in single quotes, in which a POSIX shell does
+// not interpret any character. A single quote in the value ends the quoted string,
+// adds an escaped single quote and starts a new quoted string.
+func quotePOSIXShell(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// quoteFish returns the value in single quotes, in which fish only interprets
+// an escaped single quote and an escaped backslash.
+func quoteFish(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// quotePowerShell returns the value in single quotes, in which PowerShell does not
+// interpret any character except for a single quote, which is escaped by doubling it.
+// PowerShell also accepts the typographic single quotes as quotes, so they are doubled too.
+func quotePowerShell(value string) string {
+	return "'" + strings.NewReplacer(
+		"'", "''",
+		"\u2018", "\u2018\u2018",
+		"\u2019", "\u2019\u2019",
+		"\u201a", "\u201a\u201a",
+		"\u201b", "\u201b\u201b",
+	).Replace(value) + "'"
+}
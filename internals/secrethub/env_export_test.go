@@ -0,0 +1,275 @@
+package secrethub
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+// shellQuotingCases are values that are hard to quote, with the statements that set them per shell.
+var shellQuotingCases = map[string]struct {
+	value      string
+	bash       string
+	fish       string
+	powershell string
+}{
+	"plain": {
+		value:      "s3cr3t",
+		bash:       `export SECRET='s3cr3t'`,
+		fish:       `set -gx SECRET 's3cr3t'`,
+		powershell: `$env:SECRET = 's3cr3t'`,
+	},
+	"empty": {
+		value:      "",
+		bash:       `export SECRET=''`,
+		fish:       `set -gx SECRET ''`,
+		powershell: `$env:SECRET = ''`,
+	},
+	"single quotes": {
+		value:      `it's 'quoted'`,
+		bash:       `export SECRET='it'\''s '\''quoted'\'''`,
+		fish:       `set -gx SECRET 'it\'s \'quoted\''`,
+		powershell: `$env:SECRET = 'it''s ''quoted'''`,
+	},
+	"double quotes": {
+		value:      `say "hi"`,
+		bash:       `export SECRET='say "hi"'`,
+		fish:       `set -gx SECRET 'say "hi"'`,
+		powershell: `$env:SECRET = 'say "hi"'`,
+	},
+	"expansions": {
+		value:      "$HOME ${HOME} $(id) `id` %PATH% $env:PATH * ~ !!",
+		bash:       "export SECRET='$HOME ${HOME} $(id) `id` %PATH% $env:PATH * ~ !!'",
+		fish:       "set -gx SECRET '$HOME ${HOME} $(id) `id` %PATH% $env:PATH * ~ !!'",
+		powershell: "$env:SECRET = '$HOME ${HOME} $(id) `id` %PATH% $env:PATH * ~ !!'",
+	},
+	"backslashes": {
+		value:      `C:\Users\app\ \'`,
+		bash:       `export SECRET='C:\Users\app\ \'\'''`,
+		fish:       `set -gx SECRET 'C:\\Users\\app\\ \\\''`,
+		powershell: `$env:SECRET = 'C:\Users\app\ \'''`,
+	},
+	"multi-line": {
+		value:      "-----BEGIN KEY-----\nMIIEvQ;&|<>\n-----END KEY-----\n",
+		bash:       "export SECRET='-----BEGIN KEY-----\nMIIEvQ;&|<>\n-----END KEY-----\n'",
+		fish:       "set -gx SECRET '-----BEGIN KEY-----\nMIIEvQ;&|<>\n-----END KEY-----\n'",
+		powershell: "$env:SECRET = '-----BEGIN KEY-----\nMIIEvQ;&|<>\n-----END KEY-----\n'",
+	},
+	"typographic quotes": {
+		value:      "\u2018a\u2019 \u201ab\u201b",
+		bash:       "export SECRET='\u2018a\u2019 \u201ab\u201b'",
+		fish:       "set -gx SECRET '\u2018a\u2019 \u201ab\u201b'",
+		powershell: "$env:SECRET = '\u2018\u2018a\u2019\u2019 \u201a\u201ab\u201b\u201b'",
+	},
+}
+
+func TestShellSyntax_Export(t *testing.T) {
+	for name, tc := range shellQuotingCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, shells[shellBash].export("SECRET", tc.value), tc.bash)
+			assert.Equal(t, shells[shellZsh].export("SECRET", tc.value), tc.bash)
+			assert.Equal(t, shells[shellDirenv].export("SECRET", tc.value), tc.bash)
+			assert.Equal(t, shells[shellFish].export("SECRET", tc.value), tc.fish)
+			assert.Equal(t, shells[shellPowerShell].export("SECRET", tc.value), tc.powershell)
+		})
+	}
+}
+
+// TestShellSyntax_Evaluate evaluates the statements in the shells that are installed
+// and checks that they set the variables to the original values.
+func TestShellSyntax_Evaluate(t *testing.T) {
+	shellCommands := map[string]func(statement string) []string{
+		shellBash: func(statement string) []string {
+			return []string{"bash", "-c", statement + "\nprintf %s \"$SECRET\""}
+		},
+		shellZsh: func(statement string) []string {
+			return []string{"zsh", "-c", statement + "\nprintf %s \"$SECRET\""}
+		},
+		shellFish: func(statement string) []string {
+			return []string{"fish", "-c", statement + "\nprintf %s $SECRET"}
+		},
+		shellPowerShell: func(statement string) []string {
+			return []string{"pwsh", "-NoProfile", "-NonInteractive", "-Command", statement + "\n[Console]::Out.Write($env:SECRET)"}
+		},
+	}
+
+	for shell, shellCommand := range shellCommands {
+		t.Run(shell, func(t *testing.T) {
+			_, err := exec.LookPath(shellCommand("")[0])
+			if err != nil {
+				t.Skipf("%s is not installed", shell)
+			}
+
+			for name, tc := range shellQuotingCases {
+				t.Run(name, func(t *testing.T) {
+					args := shellCommand(shells[shell].export("SECRET", tc.value))
+					out, err := exec.Command(args[0], args[1:]...).Output()
+					assert.OK(t, err)
+					assert.Equal(t, string(out), tc.value)
+				})
+			}
+		})
+	}
+}
+
+func TestEnvExportCommand_Run(t *testing.T) {
+	newClient := func() (secrethub.ClientInterface, error) {
+		return fakeclient.Client{
+			SecretService: &fakeclient.SecretService{
+				VersionService: &fakeclient.SecretVersionService{
+					WithDataGetter: fakeclient.WithDataGetter{
+						ReturnsVersion: &api.SecretVersion{Data: []byte("it's s3cr3t")},
+					},
+				},
+			},
+		}, nil
+	}
+
+	cases := map[string]struct {
+		shell string
+		unset bool
+		out   string
+	}{
+		"bash": {
+			shell: shellBash,
+			out: "export DB_HOST='db.example.com'\n" +
+				"export DB_PASSWORD='it'\\''s s3cr3t'\n",
+		},
+		"bash unset": {
+			shell: shellBash,
+			unset: true,
+			out: "unset DB_HOST\n" +
+				"unset DB_PASSWORD\n",
+		},
+		"fish": {
+			shell: shellFish,
+			out: "set -gx DB_HOST 'db.example.com'\n" +
+				"set -gx DB_PASSWORD 'it\\'s s3cr3t'\n",
+		},
+		"fish unset": {
+			shell: shellFish,
+			unset: true,
+			out: "set -e DB_HOST\n" +
+				"set -e DB_PASSWORD\n",
+		},
+		"powershell": {
+			shell: shellPowerShell,
+			out: "$env:DB_HOST = 'db.example.com'\n" +
+				"$env:DB_PASSWORD = 'it''s s3cr3t'\n",
+		},
+		"powershell unset": {
+			shell: shellPowerShell,
+			unset: true,
+			out: "Remove-Item -Path Env:DB_HOST -ErrorAction SilentlyContinue\n" +
+				"Remove-Item -Path Env:DB_PASSWORD -ErrorAction SilentlyContinue\n",
+		},
+		"direnv": {
+			shell: shellDirenv,
+			out: "watch_file 'secrethub.env'\n" +
+				"export DB_HOST='db.example.com'\n" +
+				"export DB_PASSWORD='it'\\''s s3cr3t'\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := ui.NewFakeIO()
+			cmd := EnvExportCommand{
+				io:        io,
+				newClient: newClient,
+				environment: &environment{
+					osStat:          osStatFunc("secrethub.env", nil),
+					readFile:        readFileFunc("secrethub.env", "DB_PASSWORD={{ company/app/db/password }}\nDB_HOST=db.example.com"),
+					osEnv:           []string{"HOME=/root", "DB_HOST=localhost"},
+					envar:           map[string]string{},
+					templateVersion: "2",
+					secretsEnvDir:   "default",
+				},
+				shell: tc.shell,
+				unset: tc.unset,
+			}
+
+			err := cmd.Run()
+			assert.OK(t, err)
+			assert.Equal(t, io.StdOut.String(), tc.out)
+		})
+	}
+}
+
+func TestEnvExportCommand_Run_SecretNotFound(t *testing.T) {
+	io := ui.NewFakeIO()
+	cmd := EnvExportCommand{
+		io: io,
+		newClient: func() (secrethub.ClientInterface, error) {
+			return fakeclient.Client{
+				SecretService: &fakeclient.SecretService{
+					VersionService: &fakeclient.SecretVersionService{
+						WithDataGetter: fakeclient.WithDataGetter{
+							Err: api.ErrSecretNotFound,
+						},
+					},
+				},
+			}, nil
+		},
+		environment: &environment{
+			osStat:          osStatFunc("secrethub.env", os.ErrNotExist),
+			readFile:        readFileFunc("secrethub.env", ""),
+			envar:           map[string]string{"DB_PASSWORD": "company/app/db/password"},
+			templateVersion: "2",
+		},
+		shell: shellBash,
+	}
+
+	err := cmd.Run()
+	assert.Equal(t, err != nil, true)
+	// Nothing is printed when a secret cannot be read.
+	assert.Equal(t, io.StdOut.String(), "")
+}
+
+func TestEnvExportCommand_Run_InvalidName(t *testing.T) {
+	cases := map[string]struct {
+		envFile string
+		envar   map[string]string
+		name    string
+	}{
+		"env file key with command substitution": {
+			envFile: "FOO`id`=bar\nDB_HOST=db.example.com",
+			name:    "FOO`id`",
+		},
+		"envar name with semicolon": {
+			envar: map[string]string{"FOO;id": "company/app/db/password"},
+			name:  "FOO;id",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, unset := range []bool{false, true} {
+				io := ui.NewFakeIO()
+				cmd := EnvExportCommand{
+					io: io,
+					environment: &environment{
+						osStat:          osStatFunc("secrethub.env", nil),
+						readFile:        readFileFunc("secrethub.env", tc.envFile),
+						envar:           tc.envar,
+						templateVersion: "2",
+					},
+					shell: shellBash,
+					unset: unset,
+				}
+
+				err := cmd.Run()
+				assert.Equal(t, err, ErrInvalidExportName(tc.name))
+				// Nothing is printed when a name is invalid.
+				assert.Equal(t, io.StdOut.String(), "")
+			}
+		})
+	}
+}
@@ -2,22 +2,32 @@ package secrethub
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
 )
 
 // EnvListCommand is a command to list all environment variable keys set in the process of `secrethub run`.
 type EnvListCommand struct {
 	io          ui.IO
+	newClient   newClientFunc
 	environment *environment
+	sources     bool
+	output      *OutputConfig
 }
 
 // NewEnvListCommand creates a new EnvListCommand.
-func NewEnvListCommand(io ui.IO) *EnvListCommand {
+func NewEnvListCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *EnvListCommand {
 	return &EnvListCommand{
 		io:          io,
-		environment: newEnvironment(io),
+		newClient:   newClient,
+		environment: newEnvironment(io, newClient),
+		output:      output,
 	}
 }
 
@@ -26,6 +36,8 @@ func (cmd *EnvListCommand) Register(r command.Registerer) {
 	clause := r.Command("ls", "[BETA] List environment variable names that will be populated with secrets.")
 	clause.HelpLong("This command is hidden because it is still in beta. Future versions may break.")
 	clause.Alias("list")
+	clause.Flag("sources", "List every variable with the source of its value, the sources it overrides and the versions of the secrets it contains. Values are never printed. "+
+		"Variables that are only defined in the OS environment are left out.").BoolVar(&cmd.sources)
 
 	cmd.environment.register(clause)
 
@@ -34,6 +46,24 @@ func (cmd *EnvListCommand) Register(r command.Registerer) {
 
 // Run executes the command.
 func (cmd *EnvListCommand) Run() error {
+	if cmd.sources {
+		sourced, err := cmd.environment.sourcedEnv()
+		if err != nil {
+			return err
+		}
+
+		client, err := cmd.newClient()
+		if err != nil {
+			return err
+		}
+
+		table, err := envSourcesTable(sourced, client.Secrets().Versions().GetWithoutData)
+		if err != nil {
+			return err
+		}
+		return cmd.output.printTable(cmd.io.Stdout(), table)
+	}
+
 	env, err := cmd.environment.env()
 	if err != nil {
 		return err
@@ -49,3 +79,101 @@ func (cmd *EnvListCommand) Run() error {
 
 	return nil
 }
+
+// envSourcesTable returns a table with for every variable the source of its value, the sources
+// it overrides and the versions of the secrets it contains, without reading the secrets. Secrets
+// that do not exist have version 0. Variables that are only defined in the OS environment are left out.
+func envSourcesTable(sourced map[string][]sourcedValue, getSecretVersion func(path string) (*api.SecretVersion, error)) (*outputTable, error) {
+	names := make([]string, 0, len(sourced))
+	for name, values := range sourced {
+		if len(values) == 1 && values[0].source == envSourceOS {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	versions := make(map[string]int)
+	table := newOutputTable(2, "NAME", "SOURCE", "SECRET", "SECRET VERSIONS", "OVERRIDES")
+	for _, name := range names {
+		values := sourced[name]
+		used := values[len(values)-1]
+
+		paths, err := used.value.secretPaths()
+		if err != nil {
+			return nil, err
+		}
+
+		secrets := make([]envSecretVersionOutput, len(paths))
+		secretsColumn := make([]string, len(paths))
+		for i, path := range paths {
+			version, ok := versions[path]
+			if !ok {
+				secret, err := getSecretVersion(path)
+				if err == nil {
+					version = secret.Version
+				} else if !api.IsErrNotFound(err) {
+					return nil, err
+				}
+				versions[path] = version
+			}
+
+			secrets[i] = envSecretVersionOutput{Path: path, Version: version}
+			secretsColumn[i] = path + ":" + strconv.Itoa(version)
+			if version == 0 {
+				secretsColumn[i] = path + " (not found)"
+			}
+		}
+
+		// The overridden sources are listed from the highest to the lowest precedence.
+		overrides := make([]string, 0, len(values)-1)
+		for i := len(values) - 2; i >= 0; i-- {
+			overrides = append(overrides, values[i].source)
+		}
+
+		containsSecret := "no"
+		if used.value.containsSecret() {
+			containsSecret = "yes"
+		}
+
+		table.add(
+			envVarSourceOutput{
+				Name:           name,
+				Source:         used.source,
+				Overrides:      overrides,
+				ContainsSecret: used.value.containsSecret(),
+				Secrets:        secrets,
+			},
+			name,
+			used.source,
+			containsSecret,
+			joinOrDash(secretsColumn),
+			joinOrDash(overrides),
+		)
+	}
+	return table, nil
+}
+
+// joinOrDash joins the elements with a comma, or returns a dash when there are none.
+func joinOrDash(elems []string) string {
+	if len(elems) == 0 {
+		return "-"
+	}
+	return strings.Join(elems, ", ")
+}
+
+// envVarSourceOutput is the structured output format of the sources of an environment variable.
+type envVarSourceOutput struct {
+	Name           string                   `json:"name" yaml:"name"`
+	Source         string                   `json:"source" yaml:"source"`
+	Overrides      []string                 `json:"overrides" yaml:"overrides"`
+	ContainsSecret bool                     `json:"contains_secret" yaml:"contains_secret"`
+	Secrets        []envSecretVersionOutput `json:"secrets" yaml:"secrets"`
+}
+
+// envSecretVersionOutput is the structured output format of a secret in an environment variable.
+// Secrets that do not exist have version 0.
+type envSecretVersionOutput struct {
+	Path    string `json:"path" yaml:"path"`
+	Version int    `json:"version" yaml:"version"`
+}
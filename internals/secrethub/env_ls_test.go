@@ -0,0 +1,116 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+func TestEnvSourcesTable(t *testing.T) {
+	versions := map[string]int{
+		"company/app/db/password":   3,
+		"company/app/db/password:1": 1,
+		"company/app/db/user":       2,
+	}
+	getSecretVersion := func(path string) (*api.SecretVersion, error) {
+		version, ok := versions[path]
+		if !ok {
+			return nil, api.ErrSecretNotFound
+		}
+		return &api.SecretVersion{Version: version}, nil
+	}
+
+	testErr := errio.Namespace("test").Code("test").Error("test error")
+
+	cases := map[string]struct {
+		sourced          map[string][]sourcedValue
+		getSecretVersion func(path string) (*api.SecretVersion, error)
+		table            string
+		json             string
+		err              error
+	}{
+		"overrides": {
+			sourced: map[string][]sourcedValue{
+				"HOME": {
+					{source: envSourceOS, value: newPlaintextValue("/root")},
+				},
+				"DB_PASSWORD": {
+					{source: envSourceOS, value: newPlaintextValue("secrethub://company/app/db/password:1")},
+					{source: envSourceReference, value: newSecretValue("company/app/db/password:1")},
+					{source: "secrethub.env", value: newSecretValue("company/app/db/password")},
+				},
+				"DB_USER": {
+					{source: envSourceEnvar, value: newSecretValue("company/app/db/user")},
+				},
+				"DB_HOST": {
+					{source: "secrethub.env", value: newPlaintextValue("db.example.com")},
+				},
+			},
+			getSecretVersion: getSecretVersion,
+			table: "NAME         SOURCE         SECRET  SECRET VERSIONS            OVERRIDES\n" +
+				"DB_HOST      secrethub.env  no      -                          -\n" +
+				"DB_PASSWORD  secrethub.env  yes     company/app/db/password:3  secrethub:// reference, os environment\n" +
+				"DB_USER      --envar        yes     company/app/db/user:2      -\n",
+		},
+		"secret not found": {
+			sourced: map[string][]sourcedValue{
+				"DB_PASSWORD": {
+					{source: envSourceEnvar, value: newSecretValue("company/app/db/unknown")},
+				},
+			},
+			getSecretVersion: getSecretVersion,
+			table: "NAME         SOURCE   SECRET  SECRET VERSIONS                     OVERRIDES\n" +
+				"DB_PASSWORD  --envar  yes     company/app/db/unknown (not found)  -\n",
+			json: "[\n" +
+				"    {\n" +
+				"        \"name\": \"DB_PASSWORD\",\n" +
+				"        \"source\": \"--envar\",\n" +
+				"        \"overrides\": [],\n" +
+				"        \"contains_secret\": true,\n" +
+				"        \"secrets\": [\n" +
+				"            {\n" +
+				"                \"path\": \"company/app/db/unknown\",\n" +
+				"                \"version\": 0\n" +
+				"            }\n" +
+				"        ]\n" +
+				"    }\n" +
+				"]\n",
+		},
+		"get secret version error": {
+			sourced: map[string][]sourcedValue{
+				"DB_PASSWORD": {
+					{source: envSourceEnvar, value: newSecretValue("company/app/db/password")},
+				},
+			},
+			getSecretVersion: func(path string) (*api.SecretVersion, error) {
+				return nil, testErr
+			},
+			err: testErr,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			table, err := envSourcesTable(tc.sourced, tc.getSecretVersion)
+			assert.Equal(t, err, tc.err)
+			if err != nil {
+				return
+			}
+
+			var out bytes.Buffer
+			err = table.printAs(&out, outputFormatTable)
+			assert.OK(t, err)
+			assert.Equal(t, out.String(), tc.table)
+
+			if tc.json != "" {
+				out.Reset()
+				err = table.printAs(&out, outputFormatJSON)
+				assert.OK(t, err)
+				assert.Equal(t, out.String(), tc.json)
+			}
+		})
+	}
+}
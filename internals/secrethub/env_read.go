@@ -20,7 +20,7 @@ func NewEnvReadCommand(io ui.IO, newClient newClientFunc) *EnvReadCommand {
 	return &EnvReadCommand{
 		io:          io,
 		newClient:   newClient,
-		environment: newEnvironment(io),
+		environment: newEnvironment(io, newClient),
 	}
 }
 
@@ -47,7 +47,11 @@ func (cmd *EnvReadCommand) Run() error {
 		return fmt.Errorf("no environment variable with that key is set")
 	}
 
-	secretReader := newSecretReader(cmd.newClient)
+	secretReader := newCachingSecretReader(cmd.newClient, nil)
+	err = prefetchSecrets(secretReader, value)
+	if err != nil {
+		return err
+	}
 
 	res, err := value.resolve(secretReader)
 	if err != nil {
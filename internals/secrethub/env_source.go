@@ -1,13 +1,14 @@
 package secrethub
 
 import (
-	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -24,44 +25,78 @@ import (
 
 type environment struct {
 	io                           ui.IO
+	newClient                    newClientFunc
 	osEnv                        []string
 	readFile                     func(filename string) ([]byte, error)
 	osStat                       func(filename string) (os.FileInfo, error)
 	envar                        map[string]string
+	envDirs                      map[string]string
+	envDirRecursive              bool
+	envDirSeparator              string
 	envFile                      string
 	templateVars                 map[string]string
 	templateVersion              string
 	dontPromptMissingTemplateVar bool
 	secretsEnvDir                string
+	templateVarReader            tpl.VariableReader
+	// osEnvFilter filters the variables of the OS environment that are sourced.
+	// The template variables are read from the unfiltered OS environment.
+	osEnvFilter *envFilter
 }
 
-func newEnvironment(io ui.IO) *environment {
+func newEnvironment(io ui.IO, newClient newClientFunc) *environment {
 	return &environment{
 		io:           io,
+		newClient:    newClient,
 		osEnv:        os.Environ(),
 		readFile:     ioutil.ReadFile,
 		osStat:       os.Stat,
 		templateVars: make(map[string]string),
 		envar:        make(map[string]string),
+		envDirs:      make(map[string]string),
 	}
 }
 
 func (env *environment) register(clause *cli.CommandClause) {
 	clause.Flag("envar", "Source an environment variable from a secret at a given path with `NAME=<path>`").Short('e').StringMapVar(&env.envar)
-	clause.Flag("env-file", "The path to a file with environment variable mappings of the form `NAME=value`. Template syntax can be used to inject secrets.").StringVar(&env.envFile)
+	clause.Flag("env-dir", "Source an environment variable from every secret in the directory at a given path with `PREFIX=<dir-path>`. The name of a variable is the prefix followed by the name of the secret in uppercase, with every character that is not allowed in a POSIX environment variable name replaced by an underscore. In an env file, use a `# secrethub:env-dir PREFIX=<dir-path>` comment.").StringMapVar(&env.envDirs)
+	clause.Flag("env-dir-recursive", "Also source the secrets in the subdirectories of the --env-dir directories. The names of their variables contain the names of the subdirectories, followed by the --env-dir-separator.").BoolVar(&env.envDirRecursive)
+	clause.Flag("env-dir-separator", "The separator between the names of subdirectories in the variables sourced with --env-dir-recursive.").Default("_").StringVar(&env.envDirSeparator)
+	clause.Flag("env-file", "The path to a file with environment variable mappings of the form `NAME=value`, in the .env format used by Docker Compose and dotenv. Template syntax can be used to inject secrets.").StringVar(&env.envFile)
 	clause.Flag("template", "").Hidden().StringVar(&env.envFile)
 	clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod").Short('v').StringMapVar(&env.templateVars)
-	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, latest or auto to automatically detect the version.").Default("auto").StringVar(&env.templateVersion)
+	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, v3, latest or auto to automatically detect the version.").Default("auto").StringVar(&env.templateVersion)
 	clause.Flag("no-prompt", "Do not prompt when a template variable is missing and return an error instead.").BoolVar(&env.dontPromptMissingTemplateVar)
 	clause.Flag("env", "The name of the environment prepared by the set command (default is `default`)").Default("default").Hidden().StringVar(&env.secretsEnvDir)
 }
 
+// env returns the variables of the environment. When multiple sources define
+// the same variable, the source with the highest precedence is used.
 func (env *environment) env() (map[string]value, error) {
-	osEnvMap, _ := parseKeyValueStringsToMap(env.osEnv)
-	var sources []EnvSource
+	sourced, err := env.sourcedEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]value, len(sourced))
+	for name, values := range sourced {
+		result[name] = values[len(values)-1].value
+	}
+	return result, nil
+}
 
-	sources = append(sources, &osEnv{
-		osEnv: osEnvMap,
+// sourcedEnv returns for every variable of the environment the values of all
+// sources that define it, ordered from the lowest to the highest precedence.
+// The last value is the one that is used.
+func (env *environment) sourcedEnv() (map[string][]sourcedValue, error) {
+	osEnvMap, _ := parseKeyValueStringsToMap(env.osEnvFilter.filter(env.osEnv))
+	var sources []namedEnvSource
+
+	sources = append(sources, namedEnvSource{
+		name: envSourceOS,
+		source: &osEnv{
+			osEnv: osEnvMap,
+		},
 	})
 
 	// .secretsenv dir (for backwards compatibility)
@@ -72,7 +107,7 @@ func (env *environment) env() (map[string]value, error) {
 		if err != nil {
 			return nil, err
 		}
-		sources = append(sources, dirSource)
+		sources = append(sources, namedEnvSource{name: envDir, source: dirSource})
 	}
 
 	//secrethub.env file
@@ -85,16 +120,18 @@ func (env *environment) env() (map[string]value, error) {
 		}
 	}
 
+	envDirs, err := newEnvDirMappings(env.envDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	var envFile *EnvFile
 	if env.envFile != "" {
-		templateVariableReader, err := newVariableReader(osEnvMap, env.templateVars)
+		templateVariableReader, err := env.variableReader()
 		if err != nil {
 			return nil, err
 		}
 
-		if !env.dontPromptMissingTemplateVar {
-			templateVariableReader = newPromptMissingVariableReader(templateVariableReader, env.io)
-		}
-
 		raw, err := env.readFile(env.envFile)
 		if err != nil {
 			return nil, ErrCannotReadFile(env.envFile, err)
@@ -105,16 +142,36 @@ func (env *environment) env() (map[string]value, error) {
 			return nil, err
 		}
 
-		envFile, err := ReadEnvFile(env.envFile, bytes.NewReader(raw), templateVariableReader, parser)
+		file, err := ReadEnvFile(env.envFile, bytes.NewReader(raw), osEnvMap, templateVariableReader, parser)
 		if err != nil {
 			return nil, err
 		}
-		sources = append(sources, envFile)
+		envFile = &file
+
+		directives, err := parseEnvDirDirectives(raw, templateVariableReader, parser)
+		if err != nil {
+			return nil, ErrParsingTemplate(env.envFile, err)
+		}
+		envDirs = append(envDirs, directives...)
+	}
+
+	// --env-dir flags and directives, which are overridden by the variables
+	// that are explicitly set in the env file.
+	if len(envDirs) > 0 {
+		secretDirEnv, err := newSecretDirEnv(env.newClient, envDirs, env.envDirRecursive, env.envDirSeparator)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, namedEnvSource{name: envSourceEnvDir, source: secretDirEnv})
+	}
+
+	if envFile != nil {
+		sources = append(sources, namedEnvSource{name: env.envFile, source: envFile})
 	}
 
 	// secret references (secrethub://)
 	referenceEnv := newReferenceEnv(osEnvMap)
-	sources = append(sources, referenceEnv)
+	sources = append(sources, namedEnvSource{name: envSourceReference, source: referenceEnv})
 
 	// --envar flag
 	// TODO: Validate the flags when parsing by implementing the Flag interface for EnvFlags.
@@ -122,28 +179,62 @@ func (env *environment) env() (map[string]value, error) {
 	if err != nil {
 		return nil, err
 	}
-	sources = append(sources, flagEnv)
+	sources = append(sources, namedEnvSource{name: envSourceEnvar, source: flagEnv})
 
-	envs := make([]map[string]value, len(sources))
+	result := make(map[string][]sourcedValue)
 	for _, source := range sources {
-		env, err := source.env()
+		env, err := source.source.env()
 		if err != nil {
 			return nil, err
 		}
-		envs = append(envs, env)
+		for name, value := range env {
+			result[name] = append(result[name], sourcedValue{source: source.name, value: value})
+		}
 	}
-
-	return mergeEnvs(envs...), nil
+	return result, nil
 }
 
-func mergeEnvs(envs ...map[string]value) map[string]value {
-	result := map[string]value{}
-	for _, env := range envs {
-		for name, value := range env {
-			result[name] = value
-		}
+// variableReader returns the reader for the template variables of the environment,
+// which are defined in the OS environment and with flags. Unless prompting is
+// disabled, the user is asked for missing variables. The reader is shared by all
+// templates of the environment, so the user is only asked once for every variable.
+func (env *environment) variableReader() (tpl.VariableReader, error) {
+	if env.templateVarReader != nil {
+		return env.templateVarReader, nil
 	}
-	return result
+
+	osEnvMap, _ := parseKeyValueStringsToMap(env.osEnv)
+	templateVariableReader, err := newVariableReader(osEnvMap, env.templateVars)
+	if err != nil {
+		return nil, err
+	}
+
+	if !env.dontPromptMissingTemplateVar {
+		templateVariableReader = newPromptMissingVariableReader(templateVariableReader, env.io)
+	}
+
+	env.templateVarReader = templateVariableReader
+	return templateVariableReader, nil
+}
+
+// The names of the sources of an environment, other than the paths of env files and env dirs.
+const (
+	envSourceOS        = "os environment"
+	envSourceEnvDir    = "--env-dir"
+	envSourceReference = "secrethub:// reference"
+	envSourceEnvar     = "--envar"
+)
+
+// namedEnvSource is a source of an environment with a name that describes it to the user.
+type namedEnvSource struct {
+	name   string
+	source EnvSource
+}
+
+// sourcedValue is the value of a variable with the name of the source that defines it.
+type sourcedValue struct {
+	source string
+	value  value
 }
 
 // EnvSource defines a method of reading environment variables from a source.
@@ -155,24 +246,62 @@ type EnvSource interface {
 type value interface {
 	resolve(tpl.SecretReader) (string, error)
 	containsSecret() bool
+	// secretPaths returns the paths of the secrets needed to resolve the value.
+	secretPaths() ([]string, error)
 }
 
 type secretValue struct {
-	path string
+	path  string
+	field string
 }
 
+// resolve returns the value of the secret or of its field. The fields of an
+// empty secret, such as a missing secret that is ignored, are empty as well.
+// The value of the field is recorded when the secret reader records values.
 func (s *secretValue) resolve(sr tpl.SecretReader) (string, error) {
-	return sr.ReadSecret(s.path)
+	secret, err := sr.ReadSecret(s.path)
+	if err != nil || s.field == "" || secret == "" {
+		return secret, err
+	}
+
+	value, err := tpl.ExtractField(s.path, secret, s.field)
+	if err != nil {
+		return "", err
+	}
+
+	recorder, ok := sr.(tpl.ValueRecorder)
+	if ok {
+		recorder.RecordValue(value)
+	}
+	return value, nil
 }
 
 func (s *secretValue) containsSecret() bool {
 	return true
 }
 
+func (s *secretValue) secretPaths() ([]string, error) {
+	return []string{s.path}, nil
+}
+
 func newSecretValue(path string) value {
 	return &secretValue{path: path}
 }
 
+// prefetchSecrets fetches the secrets needed to resolve the given values
+// in advance with the given secret reader.
+func prefetchSecrets(sr *cachingSecretReader, values ...value) error {
+	var paths []string
+	for _, value := range values {
+		valuePaths, err := value.secretPaths()
+		if err != nil {
+			return err
+		}
+		paths = append(paths, valuePaths...)
+	}
+	return sr.Prefetch(paths)
+}
+
 // EnvFlags defines environment variables sourced from command-line flags.
 type EnvFlags map[string]string
 
@@ -204,7 +333,8 @@ func (ef EnvFlags) env() (map[string]value, error) {
 }
 
 // referenceEnv is an environment with secrets configured with the
-// secrethub:// syntax in the os environment variables.
+// secrethub:// syntax in the os environment variables. A reference can
+// point to a field of a structured secret: secrethub://path/to/secret#field.
 type referenceEnv struct {
 	envVars map[string]string
 }
@@ -227,8 +357,9 @@ func newReferenceEnv(osEnv map[string]string) *referenceEnv {
 // secrethub:// syntax.
 func (env *referenceEnv) env() (map[string]value, error) {
 	envVarsWithSecrets := make(map[string]value)
-	for key, path := range env.envVars {
-		envVarsWithSecrets[key] = newSecretValue(path)
+	for key, ref := range env.envVars {
+		path, field := tpl.SplitField(ref)
+		envVarsWithSecrets[key] = &secretValue{path: path, field: field}
 	}
 	return envVarsWithSecrets, nil
 }
@@ -245,6 +376,10 @@ func (s *envDirSecretValue) containsSecret() bool {
 	return true
 }
 
+func (s *envDirSecretValue) secretPaths() ([]string, error) {
+	return nil, nil
+}
+
 func newEnvDirSecretValue(value string) value {
 	return &envDirSecretValue{value: value}
 }
@@ -281,6 +416,190 @@ func (dir EnvDir) env() (map[string]value, error) {
 	return dir, nil
 }
 
+const (
+	// envDirDirective is the comment in an env file that sources the secrets in a
+	// directory, e.g. `# secrethub:env-dir DB_=company/app/db`.
+	envDirDirective = "secrethub:env-dir"
+)
+
+// envDirMapping maps the secrets in a directory to environment variables
+// with a name that starts with the prefix.
+type envDirMapping struct {
+	prefix string
+	path   string
+}
+
+// newEnvDirMappings validates the directory paths of the --env-dir flags
+// and returns the mappings ordered by prefix.
+func newEnvDirMappings(flags map[string]string) ([]envDirMapping, error) {
+	mappings := make([]envDirMapping, 0, len(flags))
+	for prefix, path := range flags {
+		path = strings.TrimSuffix(path, "/")
+		err := api.ValidateDirPath(path)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, envDirMapping{prefix: prefix, path: path})
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].prefix < mappings[j].prefix
+	})
+	return mappings, nil
+}
+
+// parseEnvDirDirectives returns the directories that are mapped in an env file with
+// `# secrethub:env-dir PREFIX=<dir-path>` comments. Template variables can be used
+// in the path.
+func parseEnvDirDirectives(raw []byte, varReader tpl.VariableReader, parser tpl.Parser) ([]envDirMapping, error) {
+	var mappings []envDirMapping
+	for i, line := range strings.Split(string(raw), "\n") {
+		lineNumber := i + 1
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		directive := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
+		arg := strings.TrimPrefix(directive, envDirDirective)
+		if arg == directive || (arg != "" && !unicode.IsSpace(rune(arg[0]))) {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSpace(arg), "=", 2)
+		if len(parts) != 2 {
+			return nil, ErrTemplate(lineNumber, fmt.Errorf("the %s directive is not formatted as PREFIX=<dir-path>", envDirDirective))
+		}
+
+		columnNumber := strings.LastIndex(line, parts[1]) + 1
+		pathTpl, err := parser.Parse(parts[1], lineNumber, columnNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		path, err := pathTpl.Evaluate(varReader, secretReaderNotAllowed{})
+		if err != nil {
+			return nil, templateError(lineNumber, err)
+		}
+
+		path = strings.TrimSuffix(path, "/")
+		err = api.ValidateDirPath(path)
+		if err != nil {
+			return nil, templateError(lineNumber, err)
+		}
+
+		mappings = append(mappings, envDirMapping{prefix: parts[0], path: path})
+	}
+	return mappings, nil
+}
+
+// secretDirEnv is an environment with a variable for every secret in a set of directories.
+type secretDirEnv struct {
+	paths map[string]string
+}
+
+// newSecretDirEnv lists the secrets in the directories and maps them to environment
+// variables. Secrets in subdirectories are included when recursive is true, with the
+// names of the subdirectories followed by the separator in the names of their variables.
+// All secrets that do not map to a valid name, or to the same name as another secret,
+// are reported at once.
+func newSecretDirEnv(newClient newClientFunc, mappings []envDirMapping, recursive bool, separator string) (*secretDirEnv, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	depth := 1
+	if recursive {
+		depth = -1
+	}
+
+	env := &secretDirEnv{
+		paths: make(map[string]string),
+	}
+	var problems []string
+	for _, mapping := range mappings {
+		tree, err := client.Dirs().GetTree(mapping.path, depth, false)
+		if err != nil {
+			return nil, err
+		}
+
+		problems = append(problems, env.add(tree.RootDir, mapping.path, mapping.prefix, recursive, separator)...)
+	}
+
+	if len(problems) > 0 {
+		return nil, ErrInvalidEnvDir("\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return env, nil
+}
+
+// add maps the secrets in the directory to variables with the given prefix and
+// returns the problems with the resulting names.
+func (env *secretDirEnv) add(dir *api.Dir, dirPath, prefix string, recursive bool, separator string) []string {
+	var problems []string
+
+	secrets := make([]*api.Secret, len(dir.Secrets))
+	copy(secrets, dir.Secrets)
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+
+	for _, secret := range secrets {
+		path := dirPath + "/" + secret.Name
+		name := prefix + envDirVarName(secret.Name)
+		if !validation.IsEnvarNamePosix(name) {
+			problems = append(problems, fmt.Sprintf("the name %s of secret %s is not a valid environment variable name", name, path))
+			continue
+		}
+
+		other, exists := env.paths[name]
+		if exists && other != path {
+			problems = append(problems, fmt.Sprintf("the secrets %s and %s are both mapped to %s", other, path, name))
+			continue
+		}
+		env.paths[name] = path
+	}
+
+	if recursive {
+		subDirs := make([]*api.Dir, len(dir.SubDirs))
+		copy(subDirs, dir.SubDirs)
+		sort.Slice(subDirs, func(i, j int) bool {
+			return subDirs[i].Name < subDirs[j].Name
+		})
+
+		for _, subDir := range subDirs {
+			subDirPrefix := prefix + envDirVarName(subDir.Name) + separator
+			problems = append(problems, env.add(subDir, dirPath+"/"+subDir.Name, subDirPrefix, recursive, separator)...)
+		}
+	}
+
+	return problems
+}
+
+// Env returns a map of environment variables sourced from the secrets in the directories.
+func (env *secretDirEnv) env() (map[string]value, error) {
+	result := make(map[string]value, len(env.paths))
+	for name, path := range env.paths {
+		result[name] = newSecretValue(path)
+	}
+	return result, nil
+}
+
+// envDirVarName converts the name of a secret or directory to the uppercase name
+// of an environment variable, replacing every character that is not allowed in a
+// POSIX environment variable name with an underscore.
+func envDirVarName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
 type templateValue struct {
 	filepath  string
 	template  tpl.Template
@@ -299,6 +618,14 @@ func (v *templateValue) containsSecret() bool {
 	return v.template.ContainsSecrets()
 }
 
+func (v *templateValue) secretPaths() ([]string, error) {
+	paths, err := v.template.SecretPaths(v.varReader)
+	if err != nil {
+		return nil, ErrParsingTemplate(v.filepath, err)
+	}
+	return paths, nil
+}
+
 func newTemplateValue(filepath string, template tpl.Template, varReader tpl.VariableReader) value {
 	return &templateValue{
 		filepath:  filepath,
@@ -348,9 +675,10 @@ func templateError(lineNo int, err error) error {
 	return err
 }
 
-// ReadEnvFile reads and parses a .env file.
-func ReadEnvFile(filepath string, reader io.Reader, varReader tpl.VariableReader, parser tpl.Parser) (EnvFile, error) {
-	env, err := NewEnv(filepath, reader, varReader, parser)
+// ReadEnvFile reads and parses a .env file. The ${KEY} tags in its values that
+// are not template variables are expanded with the given OS environment.
+func ReadEnvFile(filepath string, reader io.Reader, osEnv map[string]string, varReader tpl.VariableReader, parser tpl.Parser) (EnvFile, error) {
+	env, err := NewEnv(filepath, reader, osEnv, varReader, parser)
 	if err != nil {
 		return EnvFile{}, ErrParsingTemplate(filepath, err)
 	}
@@ -377,8 +705,8 @@ func (e EnvFile) env() (map[string]value, error) {
 
 // NewEnv loads an environment of key-value pairs from a string.
 // The format of the string can be `key: value` or `key=value` pairs.
-func NewEnv(filepath string, r io.Reader, varReader tpl.VariableReader, parser tpl.Parser) (EnvSource, error) {
-	env, err := parseEnvironment(r)
+func NewEnv(filepath string, r io.Reader, osEnv map[string]string, varReader tpl.VariableReader, parser tpl.Parser) (EnvSource, error) {
+	env, err := parseEnvironment(r, dotEnvExpansion{osEnv: osEnv, varReader: varReader, parser: parser})
 	if err != nil {
 		return nil, err
 	}
@@ -426,9 +754,9 @@ type envvar struct {
 // It first tries the key=value format. When that returns an error,
 // the yml format is tried.
 // The default parser to be used with the format is also returned.
-func parseEnvironment(r io.Reader) ([]envvar, error) {
+func parseEnvironment(r io.Reader, expansion dotEnvExpansion) ([]envvar, error) {
 	var ymlReader bytes.Buffer
-	env, err := parseDotEnv(io.TeeReader(r, &ymlReader))
+	env, err := parseDotEnv(io.TeeReader(r, &ymlReader), expansion)
 	if err != nil {
 		var ymlErr error
 		env, ymlErr = parseYML(&ymlReader)
@@ -439,15 +767,34 @@ func parseEnvironment(r io.Reader) ([]envvar, error) {
 	return env, nil
 }
 
-// parseDotEnv parses key-value pairs in the .env syntax (key=value).
-func parseDotEnv(r io.Reader) ([]envvar, error) {
-	vars := map[string]envvar{}
-	scanner := bufio.NewScanner(r)
+// parseDotEnv parses key-value pairs in the .env syntax (key=value). The syntax is
+// compatible with the .env files used by tools like Docker Compose and Node's dotenv:
+//   - Lines can be prefixed with `export`.
+//   - Lines starting with # are comments. In unquoted values, a # preceded by
+//     whitespace starts a comment that runs until the end of the line.
+//   - Values can be wrapped in single or double quotes and can then span multiple lines.
+//   - Double-quoted values support the escape sequences \n, \r, \t, \", \\ and \$.
+//   - In unquoted and double-quoted values, ${KEY} is replaced by the value of a key
+//     that is defined earlier in the file. Otherwise, when KEY is not set as a template
+//     variable, it is replaced by the variable KEY of the OS environment. In all other
+//     cases, the tag is left as is, so it can be used as a template variable.
+//     The replaced text is never parsed as template syntax.
+func parseDotEnv(r io.Reader, expansion dotEnvExpansion) ([]envvar, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
 
-	i := 0
-	for scanner.Scan() {
-		i++
-		line := scanner.Text()
+	lines := strings.Split(string(raw), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	vars := map[string]envvar{}
+	defined := map[string]string{}
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := lines[i]
 
 		trimmed := strings.TrimSpace(line)
 		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
@@ -456,7 +803,7 @@ func parseDotEnv(r io.Reader) ([]envvar, error) {
 
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
-			return nil, ErrTemplate(i, errors.New("template is not formatted as key=value pairs"))
+			return nil, ErrTemplate(lineNumber, errors.New("template is not formatted as key=value pairs"))
 		}
 
 		columnNumberValue := len(parts[0]) + 2 // the length of the key (including spaces and quotes) + one for the = sign and one for the current column.
@@ -476,22 +823,61 @@ func parseDotEnv(r io.Reader) ([]envvar, error) {
 		}
 
 		key := strings.TrimSpace(parts[0])
+		if unexported, ok := trimExportPrefix(key); ok {
+			columnNumberKey += len(key) - len(unexported)
+			key = unexported
+		}
 
-		value, isTrimmed := trimQuotes(strings.TrimSpace(parts[1]))
-		if isTrimmed {
-			columnNumberValue++
+		var value, plain string
+		rest := strings.TrimLeftFunc(parts[1], unicode.IsSpace)
+		if rest != "" && (rest[0] == doubleQuoteChar || rest[0] == singleQuoteChar) {
+			quote := rest[0]
+			content, trailing, endLine, closed := scanQuoted(lines, i, rest[1:], quote)
+			if !closed {
+				return nil, ErrTemplate(lineNumber, fmt.Errorf("value is not closed with a %c quote", quote))
+			}
+
+			isTrimmed := true
+			trailing = strings.TrimSpace(trailing)
+			if trailing != "" && !strings.HasPrefix(trailing, "#") {
+				if endLine != i {
+					return nil, ErrTemplate(endLine+1, errors.New("unexpected characters after the closing quote of the value"))
+				}
+				// The quotes are part of the value, e.g. in "{"foo":"bar"}".
+				value, isTrimmed = trimQuotes(strings.TrimSpace(rest))
+				plain = value
+			} else if quote == doubleQuoteChar {
+				value, plain, err = expansion.expand(content, true, defined)
+				if err != nil {
+					return nil, ErrTemplate(lineNumber, err)
+				}
+			} else {
+				value = content
+				plain = content
+			}
+
+			if isTrimmed {
+				columnNumberValue++
+			}
+			i = endLine
+		} else {
+			value, plain, err = expansion.expand(strings.TrimSpace(trimInlineComment(rest)), false, defined)
+			if err != nil {
+				return nil, ErrTemplate(lineNumber, err)
+			}
 		}
 
+		defined[key] = plain
 		vars[key] = envvar{
 			key:               key,
 			value:             value,
-			lineNumber:        i,
+			lineNumber:        lineNumber,
 			columnNumberValue: columnNumberValue,
 			columnNumberKey:   columnNumberKey,
 		}
 	}
 
-	i = 0
+	i := 0
 	res := make([]envvar, len(vars))
 	for _, envvar := range vars {
 		res[i] = envvar
@@ -501,6 +887,167 @@ func parseDotEnv(r io.Reader) ([]envvar, error) {
 	return res, nil
 }
 
+// trimExportPrefix removes the `export` keyword from the given key.
+// It returns false when the key is not prefixed with it.
+func trimExportPrefix(key string) (string, bool) {
+	const prefix = "export"
+	if !strings.HasPrefix(key, prefix) {
+		return key, false
+	}
+
+	unexported := strings.TrimLeftFunc(key[len(prefix):], unicode.IsSpace)
+	if len(unexported) == len(key)-len(prefix) || unexported == "" {
+		return key, false
+	}
+	return unexported, true
+}
+
+// trimInlineComment removes a comment that starts with a # preceded by whitespace.
+func trimInlineComment(value string) string {
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return value[:i]
+		}
+	}
+	return value
+}
+
+// scanQuoted scans the value that starts after an opening quote at the given line,
+// continuing on the next lines until the closing quote. In double-quoted values, a quote
+// can be escaped with a backslash. It returns the content between the quotes, the
+// rest of the line after the closing quote, the index of the line with the closing
+// quote and whether a closing quote was found.
+func scanQuoted(lines []string, lineIndex int, rest string, quote byte) (string, string, int, bool) {
+	var content strings.Builder
+	for {
+		for i := 0; i < len(rest); i++ {
+			switch {
+			case rest[i] == '\\' && quote == doubleQuoteChar && i+1 < len(rest):
+				content.WriteString(rest[i : i+2])
+				i++
+			case rest[i] == quote:
+				return content.String(), rest[i+1:], lineIndex, true
+			default:
+				content.WriteByte(rest[i])
+			}
+		}
+
+		lineIndex++
+		if lineIndex >= len(lines) {
+			return "", "", lineIndex, false
+		}
+		content.WriteByte('\n')
+		rest = lines[lineIndex]
+	}
+}
+
+// dotEnvExpansion expands the ${KEY} tags in the values of a .env file.
+type dotEnvExpansion struct {
+	osEnv     map[string]string
+	varReader tpl.VariableReader
+	parser    tpl.Parser
+}
+
+// expand replaces the ${KEY} tags in the value with the value of the key defined
+// earlier or else, when KEY is not set as a template variable, with the variable
+// of the OS environment. When escapes is true, escape sequences are replaced as well.
+// It returns the value to parse as a template, in which the replaced text is
+// escaped, and the value with the replaced text as is.
+func (e dotEnvExpansion) expand(value string, escapes bool, defined map[string]string) (string, string, error) {
+	var res, plain strings.Builder
+	for i := 0; i < len(value); i++ {
+		if escapes && value[i] == '\\' && i+1 < len(value) {
+			var unescaped string
+			switch value[i+1] {
+			case 'n':
+				unescaped = "\n"
+			case 'r':
+				unescaped = "\r"
+			case 't':
+				unescaped = "\t"
+			case '"', '\\', '$':
+				unescaped = value[i+1 : i+2]
+			default:
+				unescaped = value[i : i+2]
+			}
+			res.WriteString(unescaped)
+			plain.WriteString(unescaped)
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(value[i:], "${") {
+			end := strings.IndexByte(value[i:], '}')
+			if end > 0 {
+				name := value[i+2 : i+end]
+				expanded, ok, err := e.lookup(name, defined)
+				if err != nil {
+					return "", "", err
+				}
+				if ok {
+					escaped, err := e.escape(name, expanded)
+					if err != nil {
+						return "", "", err
+					}
+					res.WriteString(escaped)
+					plain.WriteString(expanded)
+					i += end
+					continue
+				}
+			}
+		}
+
+		res.WriteByte(value[i])
+		plain.WriteByte(value[i])
+	}
+	return res.String(), plain.String(), nil
+}
+
+// lookup returns the value to replace the ${name} tag with and whether it should be replaced.
+func (e dotEnvExpansion) lookup(name string, defined map[string]string) (string, bool, error) {
+	value, ok := defined[name]
+	if ok {
+		return value, true, nil
+	}
+
+	if !validation.IsEnvarNamePosix(name) {
+		return "", false, nil
+	}
+
+	if e.varReader != nil {
+		_, isVariable, err := tpl.LookupVariable(e.varReader, strings.ToLower(name))
+		if err != nil {
+			return "", false, err
+		}
+		if isVariable {
+			return "", false, nil
+		}
+	}
+
+	value, ok = e.osEnv[name]
+	return value, ok, nil
+}
+
+// escape escapes the expanded value of the ${name} tag, so that it is not parsed as
+// template syntax. When the parser does not support escaping, an error is returned
+// for values that would be parsed as template syntax.
+func (e dotEnvExpansion) escape(name string, expanded string) (string, error) {
+	if e.parser == nil {
+		return expanded, nil
+	}
+
+	escaper, ok := e.parser.(tpl.Escaper)
+	if ok {
+		return escaper.Escape(expanded), nil
+	}
+
+	template, err := e.parser.Parse(expanded, 1, 1)
+	if err != nil || template.ContainsSecrets() {
+		return "", fmt.Errorf("the value of ${%s} cannot be used because it contains template syntax", name)
+	}
+	return expanded, nil
+}
+
 const (
 	doubleQuoteChar = '\u0022' // "
 	singleQuoteChar = '\u0027' // '
@@ -568,6 +1115,10 @@ func (v *plaintextValue) containsSecret() bool {
 	return false
 }
 
+func (v *plaintextValue) secretPaths() ([]string, error) {
+	return nil, nil
+}
+
 type osEnv struct {
 	osEnv map[string]string
 }
@@ -0,0 +1,343 @@
+package secrethub
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+// TestParseDotEnv_Conformance tests the compatibility of parseDotEnv with
+// the .env files used by Docker Compose and Node's dotenv.
+func TestParseDotEnv_Conformance(t *testing.T) {
+	osEnv := map[string]string{
+		"HOST": "example.com",
+	}
+
+	cases := map[string]struct {
+		raw      string
+		expected map[string]string
+		err      error
+	}{
+		"unquoted": {
+			raw:      "FOO=bar",
+			expected: map[string]string{"FOO": "bar"},
+		},
+		"empty value": {
+			raw:      "FOO=",
+			expected: map[string]string{"FOO": ""},
+		},
+		"export prefix": {
+			raw:      "export FOO=bar\nexport\tBAR=baz",
+			expected: map[string]string{"FOO": "bar", "BAR": "baz"},
+		},
+		"key named export": {
+			raw:      "export=bar",
+			expected: map[string]string{"export": "bar"},
+		},
+		"inline comment": {
+			raw:      "FOO=bar # comment\nBAR=baz\t# comment",
+			expected: map[string]string{"FOO": "bar", "BAR": "baz"},
+		},
+		"hash without whitespace": {
+			raw:      "FOO=bar#baz",
+			expected: map[string]string{"FOO": "bar#baz"},
+		},
+		"comment after quoted value": {
+			raw:      `FOO="bar # baz" # comment`,
+			expected: map[string]string{"FOO": "bar # baz"},
+		},
+		"double-quoted multi-line": {
+			raw:      "KEY=\"-----BEGIN KEY-----\nabc\n-----END KEY-----\"\nFOO=bar",
+			expected: map[string]string{"KEY": "-----BEGIN KEY-----\nabc\n-----END KEY-----", "FOO": "bar"},
+		},
+		"single-quoted multi-line": {
+			raw:      "KEY='foo\nbar'",
+			expected: map[string]string{"KEY": "foo\nbar"},
+		},
+		"CRLF line endings": {
+			raw:      "FOO=bar\r\nKEY=\"foo\r\nbar\"\r\n",
+			expected: map[string]string{"FOO": "bar", "KEY": "foo\nbar"},
+		},
+		"escape sequences": {
+			raw:      `FOO="a\nb\tc\"d\\e\$f"`,
+			expected: map[string]string{"FOO": "a\nb\tc\"d\\e$f"},
+		},
+		"unknown escape sequence": {
+			raw:      `FOO="a\qb"`,
+			expected: map[string]string{"FOO": `a\qb`},
+		},
+		"escaped quote in multi-line value": {
+			raw:      "FOO=\"a\\\"\nb\"",
+			expected: map[string]string{"FOO": "a\"\nb"},
+		},
+		"no escape sequences in single quotes": {
+			raw:      `FOO='a\nb'`,
+			expected: map[string]string{"FOO": `a\nb`},
+		},
+		"no escape sequences unquoted": {
+			raw:      `FOO=a\nb`,
+			expected: map[string]string{"FOO": `a\nb`},
+		},
+		"expand previous key": {
+			raw:      "USER=admin\nURL=https://${USER}@host",
+			expected: map[string]string{"USER": "admin", "URL": "https://admin@host"},
+		},
+		"expand previous key in double quotes": {
+			raw:      "USER=admin\nURL=\"${USER} ${USER}\"",
+			expected: map[string]string{"USER": "admin", "URL": "admin admin"},
+		},
+		"expand OS environment": {
+			raw:      "URL=https://${HOST}/",
+			expected: map[string]string{"URL": "https://example.com/"},
+		},
+		"previous key takes precedence over OS environment": {
+			raw:      "HOST=localhost\nURL=${HOST}",
+			expected: map[string]string{"HOST": "localhost", "URL": "localhost"},
+		},
+		"no expansion of later key": {
+			raw:      "URL=${LATER}\nLATER=admin",
+			expected: map[string]string{"URL": "${LATER}", "LATER": "admin"},
+		},
+		"no expansion in single quotes": {
+			raw:      "USER=admin\nURL='${USER}'",
+			expected: map[string]string{"USER": "admin", "URL": "${USER}"},
+		},
+		"no expansion when escaped": {
+			raw:      "USER=admin\nURL=\"\\${USER}\"",
+			expected: map[string]string{"USER": "admin", "URL": "${USER}"},
+		},
+		"undefined variable is left for the template": {
+			raw:      "FOO={{ ${app}/db/password }}",
+			expected: map[string]string{"FOO": "{{ ${app}/db/password }}"},
+		},
+		"quotes as part of the value": {
+			raw:      `FOO="{"foo":"bar"}"`,
+			expected: map[string]string{"FOO": `{"foo":"bar"}`},
+		},
+		"unterminated double quote": {
+			raw: "FOO=\"bar\nBAR=baz",
+			err: ErrTemplate(1, errors.New("value is not closed with a \" quote")),
+		},
+		"characters after multi-line value": {
+			raw: "FOO=\"bar\nbaz\"qux",
+			err: ErrTemplate(2, errors.New("unexpected characters after the closing quote of the value")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			env, err := parseDotEnv(strings.NewReader(tc.raw), dotEnvExpansion{osEnv: osEnv})
+			assert.Equal(t, err, tc.err)
+
+			if tc.err == nil {
+				actual := make(map[string]string, len(env))
+				for _, envvar := range env {
+					actual[envvar.key] = envvar.value
+				}
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseDotEnv_Position(t *testing.T) {
+	env, err := parseDotEnv(strings.NewReader("FOO=\"a\nb\"\n  export BAR = 'baz'"), dotEnvExpansion{})
+	assert.OK(t, err)
+
+	elemEqual(t, env, []envvar{
+		{
+			key:               "FOO",
+			value:             "a\nb",
+			lineNumber:        1,
+			columnNumberKey:   1,
+			columnNumberValue: 6,
+		},
+		{
+			key:               "BAR",
+			value:             "baz",
+			lineNumber:        3,
+			columnNumberKey:   10,
+			columnNumberValue: 17,
+		},
+	})
+}
+
+func TestReferenceEnv(t *testing.T) {
+	sr := fakes.FakeSecretReader{
+		Secrets: map[string]string{
+			"company/app/password": "secret",
+			"company/app/config":   `{"db": {"user": "admin"}}`,
+		},
+	}
+
+	cases := map[string]struct {
+		osEnv    map[string]string
+		expected map[string]string
+		err      error
+	}{
+		"secret": {
+			osEnv:    map[string]string{"DB_PASSWORD": "secrethub://company/app/password", "OTHER": "value"},
+			expected: map[string]string{"DB_PASSWORD": "secret"},
+		},
+		"field": {
+			osEnv:    map[string]string{"DB_USER": "secrethub://company/app/config#db.user"},
+			expected: map[string]string{"DB_USER": "admin"},
+		},
+		"missing field": {
+			osEnv: map[string]string{"DB_HOST": "secrethub://company/app/config#db.host"},
+			err:   tpl.ErrFieldNotFound("db.host", "company/app/config"),
+		},
+		"field of unstructured secret": {
+			osEnv: map[string]string{"DB_USER": "secrethub://company/app/password#user"},
+			err:   tpl.ErrSecretNotStructured("user", "company/app/password"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			env, err := newReferenceEnv(tc.osEnv).env()
+			assert.OK(t, err)
+
+			actual := make(map[string]string, len(env))
+			for key, value := range env {
+				actual[key], err = value.resolve(sr)
+				if err != nil {
+					break
+				}
+			}
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSecretDirEnv(t *testing.T) {
+	tree := &api.Tree{
+		RootDir: &api.Dir{
+			Name: "prod",
+			Secrets: []*api.Secret{
+				{Name: "db-password"},
+				{Name: "api.key"},
+			},
+			SubDirs: []*api.Dir{
+				{
+					Name: "mail",
+					Secrets: []*api.Secret{
+						{Name: "smtp_user"},
+					},
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		mappings  []envDirMapping
+		recursive bool
+		tree      *api.Tree
+		expected  map[string]string
+		err       error
+	}{
+		"prefix": {
+			mappings: []envDirMapping{{prefix: "APP_", path: "company/app/prod"}},
+			tree:     tree,
+			expected: map[string]string{
+				"APP_DB_PASSWORD": "company/app/prod/db-password",
+				"APP_API_KEY":     "company/app/prod/api.key",
+			},
+		},
+		"recursive": {
+			mappings:  []envDirMapping{{path: "company/app/prod"}},
+			recursive: true,
+			tree:      tree,
+			expected: map[string]string{
+				"DB_PASSWORD":    "company/app/prod/db-password",
+				"API_KEY":        "company/app/prod/api.key",
+				"MAIL_SMTP_USER": "company/app/prod/mail/smtp_user",
+			},
+		},
+		"invalid name": {
+			mappings: []envDirMapping{{path: "company/app/prod"}},
+			tree: &api.Tree{
+				RootDir: &api.Dir{
+					Secrets: []*api.Secret{{Name: "1password"}, {Name: "token"}},
+				},
+			},
+			err: ErrInvalidEnvDir("\n  - the name 1PASSWORD of secret company/app/prod/1password is not a valid environment variable name"),
+		},
+		"collision": {
+			mappings: []envDirMapping{{path: "company/app/prod"}},
+			tree: &api.Tree{
+				RootDir: &api.Dir{
+					Secrets: []*api.Secret{{Name: "db_password"}, {Name: "db-password"}, {Name: "DB.PASSWORD"}},
+				},
+			},
+			err: ErrInvalidEnvDir("\n  - the secrets company/app/prod/DB.PASSWORD and company/app/prod/db-password are both mapped to DB_PASSWORD" +
+				"\n  - the secrets company/app/prod/DB.PASSWORD and company/app/prod/db_password are both mapped to DB_PASSWORD"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			newClient := func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					DirService: &fakeclient.DirService{
+						TreeGetter: fakeclient.TreeGetter{
+							ReturnsTree: tc.tree,
+						},
+					},
+				}, nil
+			}
+
+			env, err := newSecretDirEnv(newClient, tc.mappings, tc.recursive, "_")
+			assert.Equal(t, err, tc.err)
+			if err == nil {
+				assert.Equal(t, env.paths, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseEnvDirDirectives(t *testing.T) {
+	cases := map[string]struct {
+		raw      string
+		expected []envDirMapping
+		err      error
+	}{
+		"directive": {
+			raw:      "# secrethub:env-dir APP_=company/app/prod/\nFOO=bar",
+			expected: []envDirMapping{{prefix: "APP_", path: "company/app/prod"}},
+		},
+		"template variable": {
+			raw:      "#secrethub:env-dir APP_=company/app/${env}",
+			expected: []envDirMapping{{prefix: "APP_", path: "company/app/prd"}},
+		},
+		"other comments": {
+			raw: "# comment\n# secrethub:env-directory APP_=company/app/prod",
+		},
+		"invalid directive": {
+			raw: "FOO=bar\n# secrethub:env-dir company/app/prod",
+			err: ErrTemplate(2, errors.New("the secrethub:env-dir directive is not formatted as PREFIX=<dir-path>")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			vars := fakes.FakeVariableReader{Variables: map[string]string{"env": "prd"}}
+
+			actual, err := parseEnvDirDirectives([]byte(tc.raw), vars, tpl.NewV2Parser())
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package secrethub
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"golang.org/x/crypto/openpgp"
+	pgperrors "golang.org/x/crypto/openpgp/errors"
+)
+
+// Errors
+var (
+	ErrAgeRecipientNotSupported = errMain.Code("age_recipient_not_supported").Error("age recipients are not supported, use an OpenPGP public key file or --passphrase instead")
+	ErrReadPublicKey            = errMain.Code("public_key_read_error").ErrorPref("could not read the OpenPGP public key %s: %s")
+	ErrReadPrivateKey           = errMain.Code("private_key_read_error").ErrorPref("could not read the OpenPGP private key %s: %s")
+	ErrExportDecryptionFailed   = errMain.Code("export_decryption_failed").ErrorPref("could not decrypt the export file: %s")
+	ErrExportManifestMissing    = errMain.Code("export_manifest_missing").Error("the export file does not contain a manifest")
+	ErrExportManifestInvalid    = errMain.Code("export_manifest_invalid").ErrorPref("the manifest of the export file is invalid: %s")
+	ErrExportEntryMissing       = errMain.Code("export_entry_missing").ErrorPref("%s is listed in the manifest but missing from the export file")
+	ErrExportEntryNotInManifest = errMain.Code("export_entry_not_in_manifest").ErrorPref("%s is not listed in the manifest of the export file")
+	ErrExportDigestMismatch     = errMain.Code("export_digest_mismatch").ErrorPref("the SHA-256 digest of %s does not match the manifest")
+)
+
+const (
+	// exportManifestName is the name of the file in an export archive that lists
+	// all secret versions in the archive.
+	exportManifestName = "secrethub_manifest.json"
+	// ageRecipientPrefix is the prefix of age X25519 public keys.
+	ageRecipientPrefix = "age1"
+)
+
+// exportManifest describes the contents of an export archive.
+type exportManifest struct {
+	Repo      string                `json:"repo"`
+	CreatedAt time.Time             `json:"created_at"`
+	Secrets   []exportManifestEntry `json:"secrets"`
+}
+
+// exportManifestEntry describes a single secret version in an export archive.
+type exportManifestEntry struct {
+	// Path is the path of the entry in the archive.
+	Path      string    `json:"path"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	SHA256    string    `json:"sha256"`
+	// NewlineAdded is true when a newline was appended to the secret on export,
+	// which has to be removed again on import.
+	NewlineAdded bool `json:"newline_added,omitempty"`
+}
+
+// add adds an entry with the given contents to the manifest.
+func (m *exportManifest) add(path string, version int, createdAt time.Time, data []byte, newlineAdded bool) {
+	digest := sha256.Sum256(data)
+	m.Secrets = append(m.Secrets, exportManifestEntry{
+		Path:         path,
+		Version:      version,
+		CreatedAt:    createdAt.UTC(),
+		SHA256:       hex.EncodeToString(digest[:]),
+		NewlineAdded: newlineAdded,
+	})
+}
+
+// write writes the manifest to the archive.
+func (m *exportManifest) write(w *zip.Writer) error {
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	node, err := w.Create(exportManifestName)
+	if err != nil {
+		return err
+	}
+
+	_, err = node.Write(raw)
+	return err
+}
+
+// verifyExportArchive checks that the archive contains exactly the entries listed
+// in its manifest and that their contents match the digests in the manifest.
+func verifyExportArchive(archive *zip.Reader) (*exportManifest, error) {
+	manifest, err := readExportManifest(archive)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, ErrExportManifestMissing
+	}
+
+	err = verifyExportManifest(archive, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// verifyExportManifest checks that the archive contains exactly the entries listed
+// in the given manifest and that their contents match the digests in the manifest.
+func verifyExportManifest(archive *zip.Reader, manifest *exportManifest) error {
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		if file.Name != exportManifestName && !file.FileInfo().IsDir() {
+			files[file.Name] = file
+		}
+	}
+
+	for _, entry := range manifest.Secrets {
+		file, ok := files[entry.Path]
+		if !ok {
+			return ErrExportEntryMissing(entry.Path)
+		}
+		delete(files, entry.Path)
+
+		data, err := readZipFile(file)
+		if err != nil {
+			return err
+		}
+
+		digest := sha256.Sum256(data)
+		if hex.EncodeToString(digest[:]) != strings.ToLower(entry.SHA256) {
+			return ErrExportDigestMismatch(entry.Path)
+		}
+	}
+
+	if len(files) > 0 {
+		var unlisted []string
+		for name := range files {
+			unlisted = append(unlisted, name)
+		}
+		sort.Strings(unlisted)
+		return ErrExportEntryNotInManifest(unlisted[0])
+	}
+
+	return nil
+}
+
+// readExportManifest returns the manifest of an export archive,
+// or nil when the archive was created without a manifest.
+func readExportManifest(archive *zip.Reader) (*exportManifest, error) {
+	for _, file := range archive.File {
+		if file.Name != exportManifestName {
+			continue
+		}
+
+		raw, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		manifest := &exportManifest{}
+		err = json.Unmarshal(raw, manifest)
+		if err != nil {
+			return nil, ErrExportManifestInvalid(err)
+		}
+		return manifest, nil
+	}
+	return nil, nil
+}
+
+// readZipFile returns the contents of a file in a zip archive.
+func readZipFile(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+// isZipArchive returns whether the given data starts with the signature of a zip archive.
+func isZipArchive(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// readExportFile reads a file created by the export command and decrypts it when it is encrypted.
+// The identity is the file with the OpenPGP private key the export is encrypted to.
+// When no identity is given, the passphrase of the export is asked for.
+func readExportFile(filename string, identity string, io ui.IO, readFile func(string) ([]byte, error)) ([]byte, error) {
+	raw, err := readFile(filename)
+	if err != nil {
+		return nil, ErrReadFile(filename, err)
+	}
+
+	if isZipArchive(raw) {
+		return raw, nil
+	}
+
+	var keys openpgp.EntityList
+	if identity != "" {
+		keys, err = readPrivateKeys(identity, readFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return decryptExport(bytes.NewReader(raw), keys, func() (string, error) {
+		return ui.AskSecret(io, "Please enter the passphrase to decrypt the export: ")
+	})
+}
+
+// readPublicKeys reads an armored or binary OpenPGP public key from a file.
+// Age recipients, given directly or in a file, are not supported.
+func readPublicKeys(filename string, readFile func(string) ([]byte, error)) (openpgp.EntityList, error) {
+	if strings.HasPrefix(filename, ageRecipientPrefix) {
+		return nil, ErrAgeRecipientNotSupported
+	}
+
+	raw, err := readFile(filename)
+	if err != nil {
+		return nil, ErrReadPublicKey(filename, err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte(ageRecipientPrefix)) {
+		return nil, ErrAgeRecipientNotSupported
+	}
+
+	keys, err := parseKeyRing(raw)
+	if err != nil {
+		return nil, ErrReadPublicKey(filename, err)
+	}
+	return keys, nil
+}
+
+// readPrivateKeys reads an armored or binary OpenPGP private key from a file.
+func readPrivateKeys(filename string, readFile func(string) ([]byte, error)) (openpgp.EntityList, error) {
+	keys, err := readKeyRing(filename, readFile)
+	if err != nil {
+		return nil, ErrReadPrivateKey(filename, err)
+	}
+	return keys, nil
+}
+
+func readKeyRing(filename string, readFile func(string) ([]byte, error)) (openpgp.EntityList, error) {
+	raw, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeyRing(raw)
+}
+
+// parseKeyRing parses an armored or binary OpenPGP key ring.
+func parseKeyRing(raw []byte) (openpgp.EntityList, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("-----BEGIN")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(raw))
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(raw))
+}
+
+// encryptExport returns a writer that encrypts everything written to it to the given
+// recipients or, when no recipients are given, with the given passphrase.
+// The returned writer must be closed to complete the encrypted message.
+func encryptExport(w io.Writer, recipients openpgp.EntityList, passphrase []byte) (io.WriteCloser, error) {
+	hints := &openpgp.FileHints{IsBinary: true}
+	if len(recipients) > 0 {
+		return openpgp.Encrypt(w, recipients, nil, hints, nil)
+	}
+	return openpgp.SymmetricallyEncrypt(w, passphrase, hints, nil)
+}
+
+// decryptExport decrypts an encrypted export with a key from the given key ring.
+// The passphrase func is called once to get the passphrase of the export or of
+// an encrypted private key.
+func decryptExport(r io.Reader, keys openpgp.EntityList, passphrase func() (string, error)) ([]byte, error) {
+	prompted := false
+	prompt := func(candidates []openpgp.Key, symmetric bool) ([]byte, error) {
+		if prompted {
+			return nil, pgperrors.ErrKeyIncorrect
+		}
+		prompted = true
+
+		answer, err := passphrase()
+		if err != nil {
+			return nil, err
+		}
+
+		if symmetric {
+			return []byte(answer), nil
+		}
+
+		for _, key := range candidates {
+			_ = key.PrivateKey.Decrypt([]byte(answer))
+		}
+		return nil, nil
+	}
+
+	md, err := openpgp.ReadMessage(r, keys, prompt, nil)
+	if err != nil {
+		return nil, ErrExportDecryptionFailed(err)
+	}
+
+	data, err := ioutil.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, ErrExportDecryptionFailed(err)
+	}
+	return data, nil
+}
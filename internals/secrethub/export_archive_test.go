@@ -0,0 +1,157 @@
+package secrethub
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func newTestExportArchive(t *testing.T, entries map[string]string, manifestEntries map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	manifest := exportManifest{
+		Repo:      "namespace/repo",
+		CreatedAt: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for name, content := range entries {
+		node, err := w.Create(name)
+		assert.OK(t, err)
+		_, err = node.Write([]byte(content))
+		assert.OK(t, err)
+	}
+	for name, content := range manifestEntries {
+		manifest.add(name, 1, time.Now(), []byte(content), false)
+	}
+	assert.OK(t, manifest.write(w))
+	assert.OK(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestVerifyExportArchive(t *testing.T) {
+	cases := map[string]struct {
+		entries  map[string]string
+		manifest map[string]string
+		err      error
+	}{
+		"success": {
+			entries:  map[string]string{"dir/secret/1": "foo\n"},
+			manifest: map[string]string{"dir/secret/1": "foo\n"},
+		},
+		"digest mismatch": {
+			entries:  map[string]string{"dir/secret/1": "bar\n"},
+			manifest: map[string]string{"dir/secret/1": "foo\n"},
+			err:      ErrExportDigestMismatch("dir/secret/1"),
+		},
+		"missing entry": {
+			entries:  map[string]string{},
+			manifest: map[string]string{"dir/secret/1": "foo\n"},
+			err:      ErrExportEntryMissing("dir/secret/1"),
+		},
+		"entry not in manifest": {
+			entries:  map[string]string{"dir/secret/1": "foo\n", "dir/secret/2": "bar\n"},
+			manifest: map[string]string{"dir/secret/1": "foo\n"},
+			err:      ErrExportEntryNotInManifest("dir/secret/2"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			raw := newTestExportArchive(t, tc.entries, tc.manifest)
+			archive, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+			assert.OK(t, err)
+
+			_, err = verifyExportArchive(archive)
+
+			assert.Equal(t, err, tc.err)
+		})
+	}
+}
+
+func TestEncryptExport_Passphrase(t *testing.T) {
+	plaintext := newTestExportArchive(t, map[string]string{"secret/1": "foo\n"}, map[string]string{"secret/1": "foo\n"})
+
+	var buf bytes.Buffer
+	w, err := encryptExport(&buf, nil, []byte("correct horse"))
+	assert.OK(t, err)
+	_, err = w.Write(plaintext)
+	assert.OK(t, err)
+	assert.OK(t, w.Close())
+
+	assert.Equal(t, isZipArchive(buf.Bytes()), false)
+
+	decrypted, err := decryptExport(bytes.NewReader(buf.Bytes()), nil, func() (string, error) {
+		return "correct horse", nil
+	})
+	assert.OK(t, err)
+	assert.Equal(t, decrypted, plaintext)
+
+	_, err = decryptExport(bytes.NewReader(buf.Bytes()), nil, func() (string, error) {
+		return "wrong", nil
+	})
+	if err == nil {
+		t.Errorf("expected an error when decrypting with the wrong passphrase")
+	}
+}
+
+func TestReadExportFile(t *testing.T) {
+	plaintext := newTestExportArchive(t, map[string]string{"secret/1": "foo\n"}, map[string]string{"secret/1": "foo\n"})
+
+	var buf bytes.Buffer
+	w, err := encryptExport(&buf, nil, []byte("correct horse"))
+	assert.OK(t, err)
+	_, err = w.Write(plaintext)
+	assert.OK(t, err)
+	assert.OK(t, w.Close())
+
+	files := map[string][]byte{
+		"export.zip":     plaintext,
+		"export.zip.gpg": buf.Bytes(),
+	}
+	readFile := func(filename string) ([]byte, error) {
+		return files[filename], nil
+	}
+
+	cases := map[string]struct {
+		filename   string
+		passphrase string
+		expected   []byte
+	}{
+		"zip": {
+			filename: "export.zip",
+			expected: plaintext,
+		},
+		"encrypted with passphrase": {
+			filename:   "export.zip.gpg",
+			passphrase: "correct horse",
+			expected:   plaintext,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := ui.NewFakeIO()
+			io.PromptIn.Buffer = bytes.NewBufferString(tc.passphrase)
+
+			actual, err := readExportFile(tc.filename, "", io, readFile)
+			assert.OK(t, err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestReadPublicKeys_AgeRecipient(t *testing.T) {
+	_, err := readPublicKeys("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p", nil)
+	assert.Equal(t, err, ErrAgeRecipientNotSupported)
+
+	_, err = readPublicKeys("recipient.txt", func(string) ([]byte, error) {
+		return []byte("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p\n"), nil
+	})
+	assert.Equal(t, err, ErrAgeRecipientNotSupported)
+}
@@ -1,10 +1,16 @@
 package secrethub
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
@@ -20,8 +26,11 @@ import (
 
 // Errors
 var (
-	ErrUnknownTemplateVersion = errMain.Code("unknown_template_version").ErrorPref("unknown template version: '%s' supported versions are 1, 2 and latest")
+	ErrUnknownTemplateVersion = errMain.Code("unknown_template_version").ErrorPref("unknown template version: '%s' supported versions are 1, 2, 3 and latest")
 	ErrReadFile               = errMain.Code("in_file_read_error").ErrorPref("could not read the input file %s: %s")
+	ErrWatchWithoutOutFile    = errMain.Code("watch_without_out_file").Error("--watch can only be used in combination with --out-file")
+	ErrInvalidWatchInterval   = errMain.Code("invalid_watch_interval").Error("the --interval must be positive")
+	ErrOnChangeFailed         = errMain.Code("on_change_failed").ErrorPref("the --on-change command failed: %s")
 )
 
 // InjectCommand is a command to read a secret.
@@ -31,6 +40,7 @@ type InjectCommand struct {
 	fileMode                      filemode.FileMode
 	force                         bool
 	io                            ui.IO
+	stderr                        io.Writer
 	useClipboard                  bool
 	clearClipboardAfter           time.Duration
 	clipper                       clip.Clipper
@@ -39,17 +49,25 @@ type InjectCommand struct {
 	templateVars                  map[string]string
 	templateVersion               string
 	dontPromptMissingTemplateVars bool
+	watch                         bool
+	watchInterval                 time.Duration
+	onChange                      string
+	runOnChange                   func(command string, stdout, stderr io.Writer) error
+	cacheConfig                   *SecretCacheConfig
 }
 
 // NewInjectCommand creates a new InjectCommand.
-func NewInjectCommand(io ui.IO, newClient newClientFunc) *InjectCommand {
+func NewInjectCommand(io ui.IO, newClient newClientFunc, cacheConfig *SecretCacheConfig) *InjectCommand {
 	return &InjectCommand{
+		cacheConfig:         cacheConfig,
 		clipper:             clip.NewClipboard(),
 		osEnv:               os.Environ(),
 		clearClipboardAfter: defaultClearClipboardAfter,
 		io:                  io,
+		stderr:              os.Stderr,
 		newClient:           newClient,
 		templateVars:        make(map[string]string),
+		runOnChange:         runShellCommand,
 	}
 }
 
@@ -69,8 +87,11 @@ func (cmd *InjectCommand) Register(r command.Registerer) {
 	clause.Flag("file", "").Hidden().StringVar(&cmd.outFile) // Alias of --out-file (for backwards compatibility)
 	clause.Flag("file-mode", "Set filemode for the output file if it does not yet exist. Defaults to 0600 (read and write for current user) and is ignored without the --out-file flag.").Default("0600").SetValue(&cmd.fileMode)
 	clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod").Short('v').StringMapVar(&cmd.templateVars)
-	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, latest or auto to automatically detect the version.").Default("auto").StringVar(&cmd.templateVersion)
+	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, v3, latest or auto to automatically detect the version.").Default("auto").StringVar(&cmd.templateVersion)
 	clause.Flag("no-prompt", "Do not prompt when a template variable is missing and return an error instead.").BoolVar(&cmd.dontPromptMissingTemplateVars)
+	clause.Flag("watch", "Keep running and re-render the template to the --out-file whenever one of the secrets it contains gets a new version. Stops on SIGINT or SIGTERM.").BoolVar(&cmd.watch)
+	clause.Flag("interval", "The interval at which to check for new secret versions when using --watch.").Default("1m").DurationVar(&cmd.watchInterval)
+	clause.Flag("on-change", "A shell command to run after the --out-file has been updated when using --watch, e.g. 'systemctl reload nginx'.").StringVar(&cmd.onChange)
 	registerForceFlag(clause).BoolVar(&cmd.force)
 
 	command.BindAction(clause, cmd.Run)
@@ -82,6 +103,14 @@ func (cmd *InjectCommand) Run() error {
 		return ErrFlagsConflict("--clip and --file")
 	}
 
+	if cmd.watch && cmd.outFile == "" {
+		return ErrWatchWithoutOutFile
+	}
+
+	if cmd.watch && cmd.watchInterval <= 0 {
+		return ErrInvalidWatchInterval
+	}
+
 	var err error
 	var raw []byte
 
@@ -123,7 +152,30 @@ func (cmd *InjectCommand) Run() error {
 		return err
 	}
 
-	injected, err := template.Evaluate(templateVariableReader, newSecretReader(cmd.newClient))
+	if cmd.watch {
+		confirmed, err := cmd.confirmOverwrite()
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Stdout(), "Aborting.")
+			return nil
+		}
+
+		return cmd.watchTemplate(template, templateVariableReader)
+	}
+
+	secretReader := newCachingSecretReader(cmd.newClient, cmd.cacheConfig.cache(cmd.stderr))
+	paths, err := template.SecretPaths(templateVariableReader)
+	if err != nil {
+		return err
+	}
+	err = secretReader.Prefetch(paths)
+	if err != nil {
+		return err
+	}
+
+	injected, err := template.Evaluate(templateVariableReader, secretReader)
 	if err != nil {
 		return err
 	}
@@ -137,28 +189,13 @@ func (cmd *InjectCommand) Run() error {
 
 		fmt.Fprintln(cmd.io.Stdout(), fmt.Sprintf("Copied injected template to clipboard. It will be cleared after %s.", units.HumanDuration(cmd.clearClipboardAfter)))
 	} else if cmd.outFile != "" {
-		_, err := os.Stat(cmd.outFile)
-		if err == nil && !cmd.force {
-			if cmd.io.Stdout().IsPiped() {
-				return ErrFileAlreadyExists
-			}
-
-			confirmed, err := ui.AskYesNo(
-				cmd.io,
-				fmt.Sprintf(
-					"File %s already exists, overwrite it?",
-					cmd.outFile,
-				),
-				ui.DefaultNo,
-			)
-			if err != nil {
-				return err
-			}
-
-			if !confirmed {
-				fmt.Fprintln(cmd.io.Stdout(), "Aborting.")
-				return nil
-			}
+		confirmed, err := cmd.confirmOverwrite()
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(cmd.io.Stdout(), "Aborting.")
+			return nil
 		}
 
 		err = ioutil.WriteFile(cmd.outFile, posix.AddNewLine(out), cmd.fileMode.FileMode())
@@ -168,7 +205,7 @@ func (cmd *InjectCommand) Run() error {
 
 		absPath, err := filepath.Abs(cmd.outFile)
 		if err != nil {
-			return ErrCannotWrite(err)
+			return ErrCannotWrite(cmd.outFile, err)
 		}
 
 		fmt.Fprintf(cmd.io.Stdout(), "%s\n", absPath)
@@ -178,3 +215,175 @@ func (cmd *InjectCommand) Run() error {
 
 	return nil
 }
+
+// confirmOverwrite asks the user to confirm overwriting the out file when it
+// already exists and --force is not set.
+func (cmd *InjectCommand) confirmOverwrite() (bool, error) {
+	_, err := os.Stat(cmd.outFile)
+	if err != nil || cmd.force {
+		return true, nil
+	}
+
+	if cmd.io.Stdout().IsPiped() {
+		return false, ErrFileAlreadyExists
+	}
+
+	return ui.AskYesNo(
+		cmd.io,
+		fmt.Sprintf(
+			"File %s already exists, overwrite it?",
+			cmd.outFile,
+		),
+		ui.DefaultNo,
+	)
+}
+
+// watchTemplate renders the template to the out file and renders it again whenever
+// one of the secrets in the template gets a new version, until SIGINT or SIGTERM
+// is received. Errors after the first render are printed, but do not stop watching.
+func (cmd *InjectCommand) watchTemplate(template tpl.Template, varReader tpl.VariableReader) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	versions, _, err := cmd.renderToFile(template, varReader)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(cmd.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+			newVersions, err := cmd.updateOutFile(template, varReader, versions)
+			if err != nil {
+				fmt.Fprintf(cmd.stderr, "Encountered an error: %s\n", err)
+				continue
+			}
+			versions = newVersions
+		}
+	}
+}
+
+// updateOutFile renders the template to the out file again when any of the secrets
+// in the template has a newer version than the given versions. When the contents of
+// the out file change, the --on-change command is run. It returns the versions of the
+// secrets used to render the template.
+func (cmd *InjectCommand) updateOutFile(template tpl.Template, varReader tpl.VariableReader, versions map[string]int) (map[string]int, error) {
+	changed, err := secretVersionsChanged(cmd.newClient, versions)
+	if err != nil {
+		return versions, err
+	}
+	if !changed {
+		return versions, nil
+	}
+
+	newVersions, written, err := cmd.renderToFile(template, varReader)
+	if err != nil {
+		return versions, err
+	}
+
+	if written && cmd.onChange != "" {
+		err = cmd.runOnChange(cmd.onChange, cmd.io.Stdout(), cmd.stderr)
+		if err != nil {
+			return newVersions, ErrOnChangeFailed(err)
+		}
+	}
+
+	return newVersions, nil
+}
+
+// renderToFile renders the template and atomically replaces the out file when the
+// rendered output differs from its current contents. It returns the versions of the
+// secrets used to render the template and whether the out file was written.
+func (cmd *InjectCommand) renderToFile(template tpl.Template, varReader tpl.VariableReader) (map[string]int, bool, error) {
+	secretReader := newCachingSecretReader(cmd.newClient, cmd.cacheConfig.cache(cmd.stderr))
+	paths, err := template.SecretPaths(varReader)
+	if err != nil {
+		return nil, false, err
+	}
+	err = secretReader.Prefetch(paths)
+	if err != nil {
+		return nil, false, err
+	}
+
+	injected, err := template.Evaluate(varReader, secretReader)
+	if err != nil {
+		return nil, false, err
+	}
+
+	out := posix.AddNewLine([]byte(injected))
+	current, err := ioutil.ReadFile(cmd.outFile)
+	if err == nil && bytes.Equal(current, out) {
+		return secretReader.Versions(), false, nil
+	}
+
+	// An existing out file keeps its file mode.
+	perm := cmd.fileMode.FileMode()
+	info, err := os.Stat(cmd.outFile)
+	if err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	err = writeFileAtomic(cmd.outFile, out, perm)
+	if err != nil {
+		return nil, false, ErrCannotWrite(cmd.outFile, err)
+	}
+
+	absPath, err := filepath.Abs(cmd.outFile)
+	if err != nil {
+		return nil, false, ErrCannotWrite(cmd.outFile, err)
+	}
+
+	fmt.Fprintf(cmd.io.Stdout(), "%s\n", absPath)
+
+	return secretReader.Versions(), true, nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory as filename
+// and then renames it to filename, so readers never see a partially written file.
+// The file gets the given file mode, also when filename already exists.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	err = os.Chmod(tmp.Name(), perm)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// runShellCommand runs the given command with the shell of the operating system,
+// writing its output to the given stdout and stderr.
+func runShellCommand(command string, stdout, stderr io.Writer) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+	c.Stdout = stdout
+	c.Stderr = stderr
+	return c.Run()
+}
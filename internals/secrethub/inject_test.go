@@ -0,0 +1,150 @@
+package secrethub
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestInjectCommand_UpdateOutFile(t *testing.T) {
+	cases := map[string]struct {
+		versions       map[string]int
+		latest         *api.SecretVersion
+		current        string
+		expectedFile   string
+		expectedChange bool
+	}{
+		"unchanged version": {
+			versions:     map[string]int{"namespace/repo/secret": 1},
+			latest:       &api.SecretVersion{Version: 1, Data: []byte("new")},
+			current:      "value: old\n",
+			expectedFile: "value: old\n",
+		},
+		"new version": {
+			versions:       map[string]int{"namespace/repo/secret": 1},
+			latest:         &api.SecretVersion{Version: 2, Data: []byte("new")},
+			current:        "value: old\n",
+			expectedFile:   "value: new\n",
+			expectedChange: true,
+		},
+		"new version with same content": {
+			versions:     map[string]int{"namespace/repo/secret": 1},
+			latest:       &api.SecretVersion{Version: 2, Data: []byte("old")},
+			current:      "value: old\n",
+			expectedFile: "value: old\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "secrethub-inject")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+
+			outFile := filepath.Join(dir, "out.yml")
+			err = ioutil.WriteFile(outFile, []byte(tc.current), 0640)
+			assert.OK(t, err)
+
+			ranOnChange := false
+			fakeIO := ui.NewFakeIO()
+			stderr := &bytes.Buffer{}
+			cmd := InjectCommand{
+				io:       fakeIO,
+				stderr:   stderr,
+				outFile:  outFile,
+				onChange: "reload",
+				runOnChange: func(command string, stdout, errOut io.Writer) error {
+					assert.Equal(t, command, "reload")
+					assert.Equal(t, stdout, fakeIO.Stdout())
+					assert.Equal(t, errOut, stderr)
+					ranOnChange = true
+					return nil
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: tc.latest,
+								},
+								WithoutDataGetter: fakeclient.WithoutDataGetter{
+									ReturnsVersion: tc.latest,
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			template, err := tpl.NewV2Parser().Parse("value: {{ namespace/repo/secret }}", 1, 1)
+			assert.OK(t, err)
+
+			versions, err := cmd.updateOutFile(template, nil, tc.versions)
+			assert.OK(t, err)
+
+			actual, err := ioutil.ReadFile(outFile)
+			assert.OK(t, err)
+
+			assert.Equal(t, string(actual), tc.expectedFile)
+			assert.Equal(t, ranOnChange, tc.expectedChange)
+
+			// The out file keeps its file mode.
+			info, err := os.Stat(outFile)
+			assert.OK(t, err)
+			assert.Equal(t, info.Mode().Perm(), os.FileMode(0640))
+			assert.Equal(t, versions["namespace/repo/secret"], tc.latest.Version)
+		})
+	}
+}
+
+func TestRunShellCommand(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	err := runShellCommand("echo foo", stdout, stderr)
+	assert.OK(t, err)
+
+	assert.Equal(t, strings.TrimSpace(stdout.String()), "foo")
+	assert.Equal(t, stderr.String(), "")
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-inject")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "out")
+
+	err = writeFileAtomic(filename, []byte("foo"), 0600)
+	assert.OK(t, err)
+
+	err = os.Chmod(filename, 0640)
+	assert.OK(t, err)
+
+	err = writeFileAtomic(filename, []byte("bar"), 0600)
+	assert.OK(t, err)
+
+	actual, err := ioutil.ReadFile(filename)
+	assert.OK(t, err)
+	assert.Equal(t, string(actual), "bar")
+
+	info, err := os.Stat(filename)
+	assert.OK(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0600))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.OK(t, err)
+	assert.Equal(t, len(files), 1)
+}
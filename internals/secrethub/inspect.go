@@ -17,14 +17,16 @@ type InspectCommand struct {
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
+	output        *OutputConfig
 }
 
 // NewInspectCommand creates a new InspectCommand.
-func NewInspectCommand(io ui.IO, newClient newClientFunc) *InspectCommand {
+func NewInspectCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *InspectCommand {
 	return &InspectCommand{
 		io:            io,
 		newClient:     newClient,
 		timeFormatter: NewTimeFormatter(true),
+		output:        output,
 	}
 }
 
@@ -43,6 +45,7 @@ func (cmd *InspectCommand) Run() error {
 		repoInspectCmd := NewRepoInspectCommand(
 			cmd.io,
 			cmd.newClient,
+			cmd.output,
 		)
 		repoInspectCmd.path = repoPath
 		return repoInspectCmd.Run()
@@ -55,6 +58,7 @@ func (cmd *InspectCommand) Run() error {
 				secretPath,
 				cmd.io,
 				cmd.newClient,
+				cmd.output,
 			).Run()
 		}
 
@@ -62,6 +66,7 @@ func (cmd *InspectCommand) Run() error {
 			secretPath,
 			cmd.io,
 			cmd.newClient,
+			cmd.output,
 		).Run()
 	}
 
@@ -1,9 +1,6 @@
 package secrethub
 
 import (
-	"fmt"
-
-	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
 	"github.com/secrethub/secrethub-go/internals/api"
@@ -15,15 +12,17 @@ type InspectSecretCommand struct {
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
+	output        *OutputConfig
 }
 
 // NewInspectSecretCommand crates a new InspectSecretCommand
-func NewInspectSecretCommand(path api.SecretPath, io ui.IO, newClient newClientFunc) *InspectSecretCommand {
+func NewInspectSecretCommand(path api.SecretPath, io ui.IO, newClient newClientFunc, output *OutputConfig) *InspectSecretCommand {
 	return &InspectSecretCommand{
 		path:          path,
 		io:            io,
 		newClient:     newClient,
 		timeFormatter: NewTimeFormatter(true),
+		output:        output,
 	}
 }
 
@@ -44,14 +43,7 @@ func (cmd *InspectSecretCommand) Run() error {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newSecretOutput(secret.Secret, versions, cmd.timeFormatter))
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintln(cmd.io.Stdout(), output)
-
-	return nil
+	return cmd.output.print(cmd.io.Stdout(), newSecretOutput(secret.Secret, versions, cmd.timeFormatter))
 }
 
 // newSecretOutput returns the JSON output of a secret.
@@ -72,8 +64,8 @@ func newSecretOutput(secret *api.Secret, versions []*api.SecretVersion, timeForm
 
 // secretOutput is the printable JSON format of a secret.
 type secretOutput struct {
-	Name         string
-	CreatedAt    string
-	VersionCount int
-	Versions     []secretVersionOutput
+	Name         string                `json:"Name" yaml:"name"`
+	CreatedAt    string                `json:"CreatedAt" yaml:"created_at"`
+	VersionCount int                   `json:"VersionCount" yaml:"version_count"`
+	Versions     []secretVersionOutput `json:"Versions" yaml:"versions"`
 }
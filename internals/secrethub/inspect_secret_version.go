@@ -1,9 +1,6 @@
 package secrethub
 
 import (
-	"fmt"
-
-	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
 	"github.com/secrethub/secrethub-go/internals/api"
@@ -15,15 +12,17 @@ type InspectSecretVersionCommand struct {
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
+	output        *OutputConfig
 }
 
 // NewInspectSecretVersionCommand creates a new InspectSecretVersionCommand.
-func NewInspectSecretVersionCommand(path api.SecretPath, io ui.IO, newClient newClientFunc) *InspectSecretVersionCommand {
+func NewInspectSecretVersionCommand(path api.SecretPath, io ui.IO, newClient newClientFunc, output *OutputConfig) *InspectSecretVersionCommand {
 	return &InspectSecretVersionCommand{
 		path:          path,
 		io:            io,
 		newClient:     newClient,
 		timeFormatter: NewTimeFormatter(true),
+		output:        output,
 	}
 }
 
@@ -39,14 +38,7 @@ func (cmd *InspectSecretVersionCommand) Run() error {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newSecretVersionOutput(version, cmd.timeFormatter))
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintln(cmd.io.Stdout(), output)
-
-	return nil
+	return cmd.output.print(cmd.io.Stdout(), newSecretVersionOutput(version, cmd.timeFormatter))
 }
 
 func newSecretVersionOutput(secret *api.SecretVersion, timeFormatter TimeFormatter) secretVersionOutput {
@@ -59,7 +51,7 @@ func newSecretVersionOutput(secret *api.SecretVersion, timeFormatter TimeFormatt
 
 // secretVersionOutput is the printable JSON format of a secret version.
 type secretVersionOutput struct {
-	Version   int
-	CreatedAt string
-	Status    string
+	Version   int    `json:"Version" yaml:"version"`
+	CreatedAt string `json:"CreatedAt" yaml:"created_at"`
+	Status    string `json:"Status" yaml:"status"`
 }
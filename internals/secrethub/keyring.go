@@ -10,6 +10,7 @@ import (
 
 	"github.com/secrethub/secrethub-cli/internals/cli/cloneproc"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
 	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
 )
 
@@ -20,6 +21,7 @@ var (
 	ErrCannotSetKeyringItem          = errMain.Code("cannot_set_keyring").ErrorPref("cannot set passphrase in keyring: %s")
 	ErrCannotDeleteKeyringItem       = errMain.Code("cannot_delete_keyring").ErrorPref("cannot delete passphrase from keyring: %s")
 	ErrCannotClearExpiredKeyringItem = errMain.Code("cannot_clear_expired_keyring_item").ErrorPref("cannot clear expired keyring item: %s")
+	ErrUnknownPassphraseCacheBackend = errMain.Code("unknown_passphrase_cache_backend").ErrorPref("unknown passphrase cache backend '%s': the options are keyring, file, kernel or agent")
 	ErrPassphraseFlagNotSet          = errMain.Code("passphrase_not_set").Error(
 		fmt.Sprintf(
 			"required --key-passphrase, -p flag has not been set.\n\n"+
@@ -35,6 +37,48 @@ const (
 	keyringKey          = "secrethub-passphrase"
 )
 
+// The backends in which the passphrase can be cached.
+const (
+	passphraseCacheBackendKeyring = "keyring"
+	passphraseCacheBackendFile    = "file"
+	passphraseCacheBackendKernel  = "kernel"
+	passphraseCacheBackendAgent   = "agent"
+)
+
+// passphraseCacheBackendFlag configures the backend the passphrase is cached in.
+type passphraseCacheBackendFlag string
+
+// String implements the flag.Value interface.
+func (f passphraseCacheBackendFlag) String() string {
+	return string(f)
+}
+
+// Set sets the backend when the given value is one of the supported backends.
+func (f *passphraseCacheBackendFlag) Set(value string) error {
+	switch value {
+	case passphraseCacheBackendKeyring, passphraseCacheBackendFile, passphraseCacheBackendKernel, passphraseCacheBackendAgent:
+		*f = passphraseCacheBackendFlag(value)
+		return nil
+	}
+	return ErrUnknownPassphraseCacheBackend(value)
+}
+
+// newKeyringBackend returns the Keyring for the given passphrase cache backend.
+// Backends that cannot be used on this system report that they are not available.
+// The agent backend uses the agent listening on agentSocket.
+func newKeyringBackend(backend string, configDir configdir.Dir, agentSocket string) Keyring {
+	switch backend {
+	case passphraseCacheBackendFile:
+		return newFileKeyring(configDir)
+	case passphraseCacheBackendKernel:
+		return newKernelKeyring()
+	case passphraseCacheBackendAgent:
+		return newAgentKeyring(configDir, agentSocket)
+	default:
+		return NewKeyring()
+	}
+}
+
 // PassphraseReader can retrieve a password and be instructed if the password is incorrect.
 // The implementation can determine to do some clean up if the password is incorrect.
 type PassphraseReader interface {
@@ -67,10 +111,9 @@ func (pr *passphraseReader) Read() ([]byte, error) {
 }
 
 // NewPassphraseReader constructs a new PassphraseReader using values in the CLI.
-func NewPassphraseReader(io ui.IO, credentialPassphrase string, credentialPassphraseTTL time.Duration) credentials.Reader {
+// The passphrase is cached in the given keyring, which is cleaned up by the given cleaner.
+func NewPassphraseReader(io ui.IO, credentialPassphrase string, credentialPassphraseTTL time.Duration, keyring Keyring, cleaner KeyringCleaner) credentials.Reader {
 	ttl := credentialPassphraseTTL
-	cleaner := NewKeyringCleaner()
-	keyring := NewKeyring()
 
 	return &passphraseReader{
 		io:        io,
@@ -312,16 +355,30 @@ type KeyringCleaner interface {
 }
 
 // keyringCleaner cleans up the credential by spawning a new CLI process that will take care of cleaning up the credential.
-type keyringCleaner struct{}
+type keyringCleaner struct {
+	backend     string
+	configDir   string
+	agentSocket string
+}
 
-// NewKeyringCleaner returns a new KeyringCleaner.
-func NewKeyringCleaner() KeyringCleaner {
-	return &keyringCleaner{}
+// NewKeyringCleaner returns a new KeyringCleaner that cleans up the passphrase
+// cached in the given backend, using the given configuration directory and agent socket.
+func NewKeyringCleaner(backend string, configDir string, agentSocket string) KeyringCleaner {
+	return &keyringCleaner{
+		backend:     backend,
+		configDir:   configDir,
+		agentSocket: agentSocket,
+	}
 }
 
 // Cleanup starts a Cleanup process to clean up the cached passphrase when it expires.
 func (kc keyringCleaner) Cleanup() error {
-	err := cloneproc.Spawn("keyring-clear")
+	args := []string{"keyring-clear", "--passphrase-cache-backend", kc.backend, "--config-dir", kc.configDir}
+	if kc.agentSocket != "" {
+		args = append(args, "--agent-sock", kc.agentSocket)
+	}
+
+	err := cloneproc.Spawn(args...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,78 @@
+package secrethub
+
+import (
+	"encoding/json"
+
+	"github.com/secrethub/secrethub-cli/internals/agent"
+
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
+)
+
+// agentKeyring implements the Keyring interface by storing the item
+// in the memory of a running agent.
+type agentKeyring struct {
+	client *agent.Client
+}
+
+// newAgentKeyring returns a Keyring that stores the item in the agent listening on
+// the given socket or, when no socket is given, on the default socket in the configuration directory.
+func newAgentKeyring(configDir configdir.Dir, socket string) Keyring {
+	if socket == "" {
+		socket = defaultAgentSocket(configDir.Path())
+	}
+
+	return &agentKeyring{
+		client: agent.NewClient(socket),
+	}
+}
+
+// IsAvailable returns true when the agent can be reached.
+func (kr agentKeyring) IsAvailable() bool {
+	_, err := kr.client.ID()
+	return err == nil
+}
+
+// Get gets the item from the agent.
+func (kr agentKeyring) Get() (*KeyringItem, error) {
+	data, err := kr.client.GetItem(keyringKey)
+	if err == agent.ErrItemNotFound {
+		return nil, ErrKeyringItemNotFound
+	} else if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	item := &KeyringItem{}
+	err = json.Unmarshal(data, item)
+	if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	return item, nil
+}
+
+// Set stores the item in the agent.
+func (kr agentKeyring) Set(item *KeyringItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	err = kr.client.SetItem(keyringKey, data)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	return nil
+}
+
+// Delete deletes the item from the agent.
+func (kr agentKeyring) Delete() error {
+	err := kr.client.DeleteItem(keyringKey)
+	if err == agent.ErrItemNotFound {
+		return ErrKeyringItemNotFound
+	} else if err != nil {
+		return ErrCannotDeleteKeyringItem(err)
+	}
+
+	return nil
+}
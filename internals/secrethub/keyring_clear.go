@@ -12,11 +12,15 @@ import (
 // KeyringClearCommand waits for the keyring item store to expire
 // and clears it. If the process receives a kill signal it will
 // delete the keyring item and stop.
-type KeyringClearCommand struct{}
+type KeyringClearCommand struct {
+	credentialStore CredentialConfig
+}
 
 // NewKeyringClearCommand creates a new KeyringClearCommand.
-func NewKeyringClearCommand() *KeyringClearCommand {
-	return &KeyringClearCommand{}
+func NewKeyringClearCommand(credentialStore CredentialConfig) *KeyringClearCommand {
+	return &KeyringClearCommand{
+		credentialStore: credentialStore,
+	}
 }
 
 // Register registers the command, arguments and flags on the provided Registerer.
@@ -33,7 +37,7 @@ func (cmd *KeyringClearCommand) Register(r command.Registerer) {
 // If the process receives a kill signal it will delete the
 // keyringItem and stop.
 func (cmd *KeyringClearCommand) Run() error {
-	keyring := NewKeyring()
+	keyring := cmd.credentialStore.Keyring()
 
 	item, err := keyring.Get()
 	if err == ErrKeyringItemNotFound {
@@ -0,0 +1,181 @@
+package secrethub
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
+)
+
+const (
+	fileKeyringFilename    = "passphrase-cache"
+	fileKeyringKeyFilename = "secrethub-passphrase-cache.key"
+	fileKeyringKeyLength   = 32
+)
+
+// fileKeyring implements the Keyring interface by storing the item in a file
+// in the configuration directory, encrypted with AES-GCM. The key is stored in
+// a separate file in $XDG_RUNTIME_DIR, which is only readable by the user and
+// cleared on logout, so the cached passphrase cannot be decrypted from a copy of
+// the configuration directory alone. Storing the key next to the encrypted item
+// would not protect the item at all, so the keyring is not available when
+// $XDG_RUNTIME_DIR is not set.
+type fileKeyring struct {
+	filename    string
+	keyFilename string
+}
+
+// newFileKeyring returns a Keyring that stores the item in the given configuration directory.
+func newFileKeyring(configDir configdir.Dir) Keyring {
+	var keyFilename string
+	keyDir := os.Getenv("XDG_RUNTIME_DIR")
+	if keyDir != "" {
+		keyFilename = filepath.Join(keyDir, fileKeyringKeyFilename)
+	}
+
+	return &fileKeyring{
+		filename:    filepath.Join(configDir.Path(), fileKeyringFilename),
+		keyFilename: keyFilename,
+	}
+}
+
+// IsAvailable returns true when there is a runtime directory to store the key in.
+func (kr fileKeyring) IsAvailable() bool {
+	return kr.keyFilename != ""
+}
+
+// Get reads and decrypts the item from the file. When the key is gone or has been
+// replaced, the item cannot be decrypted and is treated as not found.
+func (kr fileKeyring) Get() (*KeyringItem, error) {
+	ciphertext, err := ioutil.ReadFile(kr.filename)
+	if os.IsNotExist(err) {
+		return nil, ErrKeyringItemNotFound
+	} else if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	key, err := ioutil.ReadFile(kr.keyFilename)
+	if os.IsNotExist(err) {
+		return nil, ErrKeyringItemNotFound
+	} else if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	aead, err := newFileKeyringCipher(key)
+	if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrKeyringItemNotFound
+	}
+
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrKeyringItemNotFound
+	}
+
+	item := &KeyringItem{}
+	err = json.Unmarshal(plaintext, item)
+	if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	return item, nil
+}
+
+// Set encrypts the item and writes it to the file. A key is generated when none exists.
+func (kr fileKeyring) Set(item *KeyringItem) error {
+	plaintext, err := json.Marshal(item)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	key, err := kr.key()
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	aead, err := newFileKeyringCipher(key)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(kr.filename), 0700)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	err = writeFileAtomic(kr.filename, aead.Seal(nonce, nonce, plaintext, nil), 0600)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	return nil
+}
+
+// Delete removes the item and its key.
+func (kr fileKeyring) Delete() error {
+	if kr.keyFilename != "" {
+		err := os.Remove(kr.keyFilename)
+		if err != nil && !os.IsNotExist(err) {
+			return ErrCannotDeleteKeyringItem(err)
+		}
+	}
+
+	err := os.Remove(kr.filename)
+	if os.IsNotExist(err) {
+		return ErrKeyringItemNotFound
+	} else if err != nil {
+		return ErrCannotDeleteKeyringItem(err)
+	}
+
+	return nil
+}
+
+// key returns the key to encrypt the item with, generating it when it does not exist yet.
+func (kr fileKeyring) key() ([]byte, error) {
+	key, err := ioutil.ReadFile(kr.keyFilename)
+	if err == nil {
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key = make([]byte, fileKeyringKeyLength)
+	_, err = rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(filepath.Dir(kr.keyFilename), 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeFileAtomic(kr.keyFilename, key, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// newFileKeyringCipher returns the AES-GCM cipher for the given key.
+func newFileKeyringCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
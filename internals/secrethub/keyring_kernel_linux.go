@@ -0,0 +1,129 @@
+package secrethub
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// kernelKeyType is the type of the key in the kernel keyring that holds the item.
+	kernelKeyType = "user"
+	// kernelKeyPerm allows the possessor and all processes of the user to use the key.
+	kernelKeyPerm = 0x3f3f0000
+)
+
+// kernelKeyring implements the Keyring interface by storing the item in the
+// Linux kernel keyring of the user. The kernel also expires the key when the
+// item expires, in case the cleanup process is not running.
+type kernelKeyring struct {
+	ringID int
+}
+
+// newKernelKeyring returns a Keyring that stores the item in the keyring of the user.
+func newKernelKeyring() Keyring {
+	return &kernelKeyring{
+		ringID: unix.KEY_SPEC_USER_KEYRING,
+	}
+}
+
+// IsAvailable returns true when the kernel keyring of the user can be used.
+// It may be disabled by the kernel or the sandbox the process runs in.
+func (kr kernelKeyring) IsAvailable() bool {
+	_, err := unix.KeyctlGetKeyringID(kr.ringID, true)
+	return err == nil
+}
+
+// Get reads the item from the kernel keyring.
+func (kr kernelKeyring) Get() (*KeyringItem, error) {
+	id, err := kr.search()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, nil, 0)
+	if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	payload := make([]byte, size)
+	n, err := unix.KeyctlBuffer(unix.KEYCTL_READ, id, payload, 0)
+	if isKeyNotFound(err) {
+		return nil, ErrKeyringItemNotFound
+	} else if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	item := &KeyringItem{}
+	err = json.Unmarshal(payload[:n], item)
+	if err != nil {
+		return nil, ErrCannotGetKeyringItem(err)
+	}
+
+	return item, nil
+}
+
+// Set adds the item to the kernel keyring, replacing any existing item,
+// and sets its timeout to the expiry of the item.
+func (kr kernelKeyring) Set(item *KeyringItem) error {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	id, err := unix.AddKey(kernelKeyType, keyringKey, payload, kr.ringID)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	err = unix.KeyctlSetperm(id, kernelKeyPerm)
+	if err != nil {
+		return ErrCannotSetKeyringItem(err)
+	}
+
+	timeout := time.Until(item.ExpiresAt) + time.Second
+	if timeout > time.Second {
+		_, err = unix.KeyctlInt(unix.KEYCTL_SET_TIMEOUT, id, int(timeout/time.Second), 0, 0)
+		if err != nil {
+			return ErrCannotSetKeyringItem(err)
+		}
+	}
+
+	return nil
+}
+
+// Delete invalidates the item in the kernel keyring.
+func (kr kernelKeyring) Delete() error {
+	id, err := kr.search()
+	if err == ErrKeyringItemNotFound {
+		return err
+	} else if err != nil {
+		return ErrCannotDeleteKeyringItem(err)
+	}
+
+	_, err = unix.KeyctlInt(unix.KEYCTL_INVALIDATE, id, 0, 0, 0)
+	if isKeyNotFound(err) {
+		return ErrKeyringItemNotFound
+	} else if err != nil {
+		return ErrCannotDeleteKeyringItem(err)
+	}
+
+	return nil
+}
+
+// search returns the id of the key that holds the item.
+func (kr kernelKeyring) search() (int, error) {
+	id, err := unix.KeyctlSearch(kr.ringID, kernelKeyType, keyringKey, 0)
+	if isKeyNotFound(err) {
+		return 0, ErrKeyringItemNotFound
+	} else if err != nil {
+		return 0, ErrCannotGetKeyringItem(err)
+	}
+	return id, nil
+}
+
+// isKeyNotFound returns whether the error means that the key does not exist (anymore).
+func isKeyNotFound(err error) bool {
+	return err == unix.ENOKEY || err == unix.EKEYEXPIRED || err == unix.EKEYREVOKED
+}
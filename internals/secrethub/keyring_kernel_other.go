@@ -0,0 +1,32 @@
+// +build !linux
+
+package secrethub
+
+// kernelKeyring implements the Keyring interface for systems without a kernel keyring.
+// It is never available, so the passphrase is not cached.
+type kernelKeyring struct{}
+
+// newKernelKeyring returns a Keyring that is not available.
+func newKernelKeyring() Keyring {
+	return kernelKeyring{}
+}
+
+// IsAvailable returns false, as the kernel keyring only exists on Linux.
+func (kr kernelKeyring) IsAvailable() bool {
+	return false
+}
+
+// Get returns ErrKeyringItemNotFound.
+func (kr kernelKeyring) Get() (*KeyringItem, error) {
+	return nil, ErrKeyringItemNotFound
+}
+
+// Set returns an error, as the kernel keyring only exists on Linux.
+func (kr kernelKeyring) Set(item *KeyringItem) error {
+	return ErrCannotSetKeyringItem("the kernel keyring is only supported on Linux")
+}
+
+// Delete returns ErrKeyringItemNotFound.
+func (kr kernelKeyring) Delete() error {
+	return ErrKeyringItemNotFound
+}
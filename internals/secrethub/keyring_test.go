@@ -1,10 +1,18 @@
 package secrethub
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/secrethub/secrethub-cli/internals/agent"
+
 	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/configdir"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
 
 	libkeyring "github.com/zalando/go-keyring"
 )
@@ -241,3 +249,132 @@ func TestKeyring_Delete_NonExisting(t *testing.T) {
 	// Assert
 	assert.Equal(t, err, ErrKeyringItemNotFound)
 }
+
+func TestKeyringBackends(t *testing.T) {
+	cases := map[string]func(t *testing.T, dir string) Keyring{
+		"file": func(t *testing.T, dir string) Keyring {
+			return &fileKeyring{
+				filename:    filepath.Join(dir, "config", fileKeyringFilename),
+				keyFilename: filepath.Join(dir, "runtime", fileKeyringKeyFilename),
+			}
+		},
+		"kernel": func(t *testing.T, dir string) Keyring {
+			keyring := newKernelKeyring()
+			if !keyring.IsAvailable() {
+				t.Skip("the kernel keyring is not available")
+			}
+			return keyring
+		},
+		"agent": func(t *testing.T, dir string) Keyring {
+			credential, err := credentials.GenerateRSACredential(1024)
+			assert.OK(t, err)
+
+			socket := filepath.Join(dir, "agent.sock")
+			l, err := agent.Listen(socket)
+			assert.OK(t, err)
+			go func() {
+				_ = agent.NewServer(credential, time.Minute).Serve(l)
+			}()
+
+			// The configured socket is used instead of the default socket in the configuration directory.
+			return newKeyringBackend(passphraseCacheBackendAgent, configdir.New(filepath.Join(dir, "config")), socket)
+		},
+	}
+
+	for name, newKeyring := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "secrethub-keyring")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+
+			keyring := newKeyring(t, dir)
+			assert.Equal(t, keyring.IsAvailable(), true)
+
+			_, err = keyring.Get()
+			assert.Equal(t, err, ErrKeyringItemNotFound)
+
+			err = keyring.Set(&KeyringItem{Passphrase: []byte("first")})
+			assert.OK(t, err)
+
+			err = keyring.Set(testKeyringItem)
+			assert.OK(t, err)
+
+			actual, err := keyring.Get()
+			assert.OK(t, err)
+			assert.Equal(t, actual.Passphrase, testKeyringItem.Passphrase)
+			assert.Equal(t, actual.ExpiresAt.Equal(testKeyringItem.ExpiresAt), true)
+
+			err = keyring.Delete()
+			assert.OK(t, err)
+
+			_, err = keyring.Get()
+			assert.Equal(t, err, ErrKeyringItemNotFound)
+
+			err = keyring.Delete()
+			assert.Equal(t, err, ErrKeyringItemNotFound)
+		})
+	}
+}
+
+func TestFileKeyring_KeyRemoved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-keyring")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	keyring := &fileKeyring{
+		filename:    filepath.Join(dir, fileKeyringFilename),
+		keyFilename: filepath.Join(dir, fileKeyringKeyFilename),
+	}
+
+	err = keyring.Set(testKeyringItem)
+	assert.OK(t, err)
+
+	raw, err := ioutil.ReadFile(keyring.filename)
+	assert.OK(t, err)
+	assert.Equal(t, bytes.Contains(raw, []byte(password)), false)
+
+	err = os.Remove(keyring.keyFilename)
+	assert.OK(t, err)
+
+	_, err = keyring.Get()
+	assert.Equal(t, err, ErrKeyringItemNotFound)
+}
+
+func TestNewFileKeyring(t *testing.T) {
+	cases := map[string]struct {
+		runtimeDir  string
+		available   bool
+		keyFilename string
+	}{
+		"runtime dir set": {
+			runtimeDir:  "/run/user/1000",
+			available:   true,
+			keyFilename: filepath.Join("/run/user/1000", fileKeyringKeyFilename),
+		},
+		"runtime dir not set": {
+			runtimeDir: "",
+			available:  false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			runtimeDir, isSet := os.LookupEnv("XDG_RUNTIME_DIR")
+			defer func() {
+				if isSet {
+					os.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+				} else {
+					os.Unsetenv("XDG_RUNTIME_DIR")
+				}
+			}()
+			err := os.Setenv("XDG_RUNTIME_DIR", tc.runtimeDir)
+			assert.OK(t, err)
+
+			keyring := newFileKeyring(configdir.New("/home/user/.secrethub")).(*fileKeyring)
+
+			assert.Equal(t, keyring.IsAvailable(), tc.available)
+			assert.Equal(t, keyring.keyFilename, tc.keyFilename)
+			assert.Equal(t, keyring.filename, filepath.Join("/home/user/.secrethub", fileKeyringFilename))
+		})
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
-	"text/tabwriter"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
@@ -21,13 +21,15 @@ type LsCommand struct {
 	useTimestamps bool
 	io            ui.IO
 	newClient     newClientFunc
+	output        *OutputConfig
 }
 
 // NewLsCommand creates a new LsCommand.
-func NewLsCommand(io ui.IO, newClient newClientFunc) *LsCommand {
+func NewLsCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *LsCommand {
 	return &LsCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -47,7 +49,7 @@ func (cmd *LsCommand) Run() error {
 	timeFormatter := NewTimeFormatter(cmd.useTimestamps)
 
 	if cmd.path == "" {
-		repoLSCommand := NewRepoLSCommand(cmd.io, cmd.newClient)
+		repoLSCommand := NewRepoLSCommand(cmd.io, cmd.newClient, cmd.output)
 		repoLSCommand.quiet = cmd.quiet
 		repoLSCommand.useTimestamps = cmd.useTimestamps
 		return repoLSCommand.Run()
@@ -71,7 +73,7 @@ func (cmd *LsCommand) Run() error {
 			return err
 		}
 
-		err = printVersions(cmd.io.Stdout(), cmd.quiet, timeFormatter, version)
+		err = printVersions(cmd.io.Stdout(), cmd.output, cmd.quiet, timeFormatter, version)
 		if err != nil {
 			return err
 		}
@@ -88,7 +90,7 @@ func (cmd *LsCommand) Run() error {
 		} else if err != nil && !api.IsErrNotFound(err) {
 			return err
 		} else if err == nil {
-			err = printDir(cmd.io.Stdout(), cmd.quiet, dirFS.RootDir, timeFormatter)
+			err = printDir(cmd.io.Stdout(), cmd.output, cmd.quiet, dirFS.RootDir, timeFormatter)
 			if err != nil {
 				return err
 			}
@@ -106,7 +108,7 @@ func (cmd *LsCommand) Run() error {
 			return err
 		}
 
-		err = printVersions(cmd.io.Stdout(), cmd.quiet, timeFormatter, versions...)
+		err = printVersions(cmd.io.Stdout(), cmd.output, cmd.quiet, timeFormatter, versions...)
 		if err != nil {
 			return err
 		}
@@ -133,27 +135,39 @@ func (cmd *LsCommand) Run() error {
 }
 
 // printVersions prints out secret versions in long or short format.
-func printVersions(w io.Writer, quiet bool, timeFormatter TimeFormatter, versions ...*api.SecretVersion) error {
+func printVersions(w io.Writer, output *OutputConfig, quiet bool, timeFormatter TimeFormatter, versions ...*api.SecretVersion) error {
 	if quiet {
 		for _, version := range versions {
 			fmt.Fprintf(w, "%s\n", version.Name())
 		}
-	} else {
-		w := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
-		fmt.Fprintf(w, "%s\t%s\t%s\n", "NAME", "STATUS", "CREATED")
-		for _, version := range versions {
-			fmt.Fprintf(w, "%s\t%s\t%s\n", version.Name(), version.Status, timeFormatter.Format(version.CreatedAt.Local()))
-		}
-		err := w.Flush()
-		if err != nil {
-			return err
-		}
+		return nil
 	}
-	return nil
+
+	table := newOutputTable(2, "NAME", "STATUS", "CREATED")
+	for _, version := range versions {
+		table.add(
+			listVersionOutput{
+				Name:      version.Name(),
+				Version:   version.Version,
+				Status:    version.Status,
+				CreatedAt: version.CreatedAt,
+			},
+			version.Name(), version.Status, timeFormatter.Format(version.CreatedAt.Local()),
+		)
+	}
+	return output.printTable(w, table)
+}
+
+// listVersionOutput is the structured output format of a listed secret version.
+type listVersionOutput struct {
+	Name      string    `json:"name" yaml:"name"`
+	Version   int       `json:"version" yaml:"version"`
+	Status    string    `json:"status" yaml:"status"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 }
 
 // printDir prints out directory contents in long or short format.
-func printDir(w io.Writer, quiet bool, dir *api.Dir, timeFormatter TimeFormatter) error {
+func printDir(w io.Writer, output *OutputConfig, quiet bool, dir *api.Dir, timeFormatter TimeFormatter) error {
 	sort.Sort(api.SortDirByName(dir.SubDirs))
 	sort.Sort(api.SortSecretByName(dir.Secrets))
 
@@ -164,19 +178,39 @@ func printDir(w io.Writer, quiet bool, dir *api.Dir, timeFormatter TimeFormatter
 		for _, secret := range dir.Secrets {
 			fmt.Fprintf(w, "%s\n", secret.Name)
 		}
-	} else {
-		tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
-		fmt.Fprintf(tw, "%s\t%s\t%s\n", "NAME", "STATUS", "CREATED")
-		for _, dir := range dir.SubDirs {
-			fmt.Fprintf(tw, "%s/\t%s\t%s\n", dir.Name, dir.Status, timeFormatter.Format(dir.CreatedAt.Local()))
-		}
-		for _, secret := range dir.Secrets {
-			fmt.Fprintf(tw, "%s\t%s\t%s\n", secret.Name, secret.Status, timeFormatter.Format(secret.CreatedAt.Local()))
-		}
-		err := tw.Flush()
-		if err != nil {
-			return err
-		}
+		return nil
 	}
-	return nil
+
+	table := newOutputTable(2, "NAME", "STATUS", "CREATED")
+	for _, dir := range dir.SubDirs {
+		table.add(
+			listDirEntryOutput{
+				Name:      dir.Name,
+				Type:      "dir",
+				Status:    dir.Status,
+				CreatedAt: dir.CreatedAt,
+			},
+			dir.Name+"/", dir.Status, timeFormatter.Format(dir.CreatedAt.Local()),
+		)
+	}
+	for _, secret := range dir.Secrets {
+		table.add(
+			listDirEntryOutput{
+				Name:      secret.Name,
+				Type:      "secret",
+				Status:    secret.Status,
+				CreatedAt: secret.CreatedAt,
+			},
+			secret.Name, secret.Status, timeFormatter.Format(secret.CreatedAt.Local()),
+		)
+	}
+	return output.printTable(w, table)
+}
+
+// listDirEntryOutput is the structured output format of a directory or secret in a listed directory.
+type listDirEntryOutput struct {
+	Name      string    `json:"name" yaml:"name"`
+	Type      string    `json:"type" yaml:"type"`
+	Status    string    `json:"status" yaml:"status"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 }
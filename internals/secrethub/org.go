@@ -9,13 +9,15 @@ import (
 type OrgCommand struct {
 	io        ui.IO
 	newClient newClientFunc
+	output    *OutputConfig
 }
 
 // NewOrgCommand creates a new OrgCommand.
-func NewOrgCommand(io ui.IO, newClient newClientFunc) *OrgCommand {
+func NewOrgCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *OrgCommand {
 	return &OrgCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -28,12 +30,12 @@ func (cmd *OrgCommand) Register(r command.Registerer) {
 	clause.Alias("organizations")
 	clause.Alias("organisations")
 	NewOrgInitCommand(cmd.io, cmd.newClient).Register(clause)
-	NewOrgInspectCommand(cmd.io, cmd.newClient).Register(clause)
+	NewOrgInspectCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 	NewOrgInviteCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgPurchaseCommand(cmd.io).Register(clause)
-	NewOrgListUsersCommand(cmd.io, cmd.newClient).Register(clause)
-	NewOrgLsCommand(cmd.io, cmd.newClient).Register(clause)
-	NewOrgRevokeCommand(cmd.io, cmd.newClient).Register(clause)
+	NewOrgListUsersCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
+	NewOrgLsCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
+	NewOrgRevokeCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 	NewOrgRmCommand(cmd.io, cmd.newClient).Register(clause)
 	NewOrgSetRoleCommand(cmd.io, cmd.newClient).Register(clause)
 }
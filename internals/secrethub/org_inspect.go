@@ -1,9 +1,6 @@
 package secrethub
 
 import (
-	"fmt"
-
-	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
@@ -16,14 +13,16 @@ type OrgInspectCommand struct {
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
+	output        *OutputConfig
 }
 
 // NewOrgInspectCommand creates a new OrgInspectCommand.
-func NewOrgInspectCommand(io ui.IO, newClient newClientFunc) *OrgInspectCommand {
+func NewOrgInspectCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *OrgInspectCommand {
 	return &OrgInspectCommand{
 		io:            io,
 		newClient:     newClient,
 		timeFormatter: NewTimestampFormatter(),
+		output:        output,
 	}
 }
 
@@ -57,25 +56,18 @@ func (cmd *OrgInspectCommand) Run() error {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newOrgInspectOutput(org, members, repos, cmd.timeFormatter))
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintln(cmd.io.Stdout(), output)
-
-	return nil
+	return cmd.output.print(cmd.io.Stdout(), newOrgInspectOutput(org, members, repos, cmd.timeFormatter))
 }
 
 // OrgInspectOutput is the json format to print out with all the details of an organization.
 type OrgInspectOutput struct {
-	Name        string
-	Description string
-	CreatedAt   string
-	MemberCount int
-	Members     []OrgMemberOutput
-	RepoCount   int
-	Repos       []api.RepoPath
+	Name        string            `json:"Name" yaml:"name"`
+	Description string            `json:"Description" yaml:"description"`
+	CreatedAt   string            `json:"CreatedAt" yaml:"created_at"`
+	MemberCount int               `json:"MemberCount" yaml:"member_count"`
+	Members     []OrgMemberOutput `json:"Members" yaml:"members"`
+	RepoCount   int               `json:"RepoCount" yaml:"repo_count"`
+	Repos       []api.RepoPath    `json:"Repos" yaml:"repos"`
 }
 
 func newOrgInspectOutput(org *api.Org, members []*api.OrgMember, repos []*api.Repo, timeFormatter TimeFormatter) OrgInspectOutput {
@@ -102,10 +94,10 @@ func newOrgInspectOutput(org *api.Org, members []*api.OrgMember, repos []*api.Re
 
 // OrgMemberOutput is the json format to print out an org member.
 type OrgMemberOutput struct {
-	Username      string
-	Role          string
-	CreatedAt     string
-	LastChangedAt string
+	Username      string `json:"Username" yaml:"username"`
+	Role          string `json:"Role" yaml:"role"`
+	CreatedAt     string `json:"CreatedAt" yaml:"created_at"`
+	LastChangedAt string `json:"LastChangedAt" yaml:"last_changed_at"`
 }
 
 func newOrgMemberOutput(member *api.OrgMember, timeFormatter TimeFormatter) OrgMemberOutput {
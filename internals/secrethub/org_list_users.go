@@ -1,9 +1,8 @@
 package secrethub
 
 import (
-	"fmt"
 	"sort"
-	"text/tabwriter"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
@@ -18,13 +17,15 @@ type OrgListUsersCommand struct {
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
+	output        *OutputConfig
 }
 
 // NewOrgListUsersCommand creates a new OrgListUsersCommand.
-func NewOrgListUsersCommand(io ui.IO, newClient newClientFunc) *OrgListUsersCommand {
+func NewOrgListUsersCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *OrgListUsersCommand {
 	return &OrgListUsersCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -63,17 +64,24 @@ func (cmd *OrgListUsersCommand) run() error {
 
 	sort.Sort(api.SortOrgMemberByUsername(resp))
 
-	w := tabwriter.NewWriter(cmd.io.Stdout(), 0, 2, 2, ' ', 0)
-
-	fmt.Fprintf(w, "%s\t%s\t%s\n", "USER", "ROLE", "LAST CHANGED")
+	table := newOutputTable(2, "USER", "ROLE", "LAST CHANGED")
 	for _, member := range resp {
-		fmt.Fprintf(w, "%s\t%s\t%s\n", member.User.Username, member.Role, cmd.timeFormatter.Format(member.LastChangedAt.Local()))
+		table.add(
+			orgMemberListOutput{
+				User:          member.User.Username,
+				Role:          member.Role,
+				LastChangedAt: member.LastChangedAt,
+			},
+			member.User.Username, member.Role, cmd.timeFormatter.Format(member.LastChangedAt.Local()),
+		)
 	}
 
-	err = w.Flush()
-	if err != nil {
-		return err
-	}
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
 
-	return nil
+// orgMemberListOutput is the structured output format of a listed organization member.
+type orgMemberListOutput struct {
+	User          string    `json:"user" yaml:"user"`
+	Role          string    `json:"role" yaml:"role"`
+	LastChangedAt time.Time `json:"last_changed_at" yaml:"last_changed_at"`
 }
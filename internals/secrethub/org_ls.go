@@ -3,7 +3,8 @@ package secrethub
 import (
 	"fmt"
 	"sort"
-	"text/tabwriter"
+	"strconv"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
@@ -18,13 +19,15 @@ type OrgLsCommand struct {
 	io            ui.IO
 	newClient     newClientFunc
 	timeFormatter TimeFormatter
+	output        *OutputConfig
 }
 
 // NewOrgLsCommand creates a new OrgLsCommand.
-func NewOrgLsCommand(io ui.IO, newClient newClientFunc) *OrgLsCommand {
+func NewOrgLsCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *OrgLsCommand {
 	return &OrgLsCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -67,31 +70,40 @@ func (cmd *OrgLsCommand) run() error {
 		for _, org := range resp {
 			fmt.Fprintf(cmd.io.Stdout(), "%s\n", org.Name)
 		}
-	} else {
-		w := tabwriter.NewWriter(cmd.io.Stdout(), 0, 2, 2, ' ', 0)
-
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", "NAME", "REPOS", "USERS", "CREATED")
+		return nil
+	}
 
-		for _, org := range resp {
-			// TODO SHDEV-724: refactor these two calls to include the counts in the api.Org response by default.
-			members, err := client.Orgs().Members().List(org.Name)
-			if err != nil {
-				return err
-			}
-
-			repos, err := client.Repos().List(org.Name)
-			if err != nil {
-				return err
-			}
-
-			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", org.Name, len(repos), len(members), cmd.timeFormatter.Format(org.CreatedAt.Local()))
+	table := newOutputTable(2, "NAME", "REPOS", "USERS", "CREATED")
+	for _, org := range resp {
+		// TODO SHDEV-724: refactor these two calls to include the counts in the api.Org response by default.
+		members, err := client.Orgs().Members().List(org.Name)
+		if err != nil {
+			return err
 		}
 
-		err = w.Flush()
+		repos, err := client.Repos().List(org.Name)
 		if err != nil {
 			return err
 		}
+
+		table.add(
+			orgOutput{
+				Name:      org.Name,
+				Repos:     len(repos),
+				Users:     len(members),
+				CreatedAt: org.CreatedAt,
+			},
+			org.Name, strconv.Itoa(len(repos)), strconv.Itoa(len(members)), cmd.timeFormatter.Format(org.CreatedAt.Local()),
+		)
 	}
 
-	return nil
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
+
+// orgOutput is the structured output format of a listed organization.
+type orgOutput struct {
+	Name      string    `json:"name" yaml:"name"`
+	Repos     int       `json:"repos" yaml:"repos"`
+	Users     int       `json:"users" yaml:"users"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 }
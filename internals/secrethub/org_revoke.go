@@ -3,7 +3,6 @@ package secrethub
 import (
 	"fmt"
 	"io"
-	"text/tabwriter"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
@@ -17,13 +16,15 @@ type OrgRevokeCommand struct {
 	username  string
 	io        ui.IO
 	newClient newClientFunc
+	output    *OutputConfig
 }
 
 // NewOrgRevokeCommand creates a new OrgRevokeCommand.
-func NewOrgRevokeCommand(io ui.IO, newClient newClientFunc) *OrgRevokeCommand {
+func NewOrgRevokeCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *OrgRevokeCommand {
 	return &OrgRevokeCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -51,37 +52,21 @@ func (cmd *OrgRevokeCommand) Run() error {
 		return err
 	}
 
-	if len(planned.Repos) > 0 {
-		fmt.Fprintf(
-			cmd.io.Stdout(),
-			"[WARNING] Revoking %s from the %s organization will revoke the user from %d repositories, "+
-				"automatically flagging secrets for rotation.\n\n"+
-				"A revocation plan has been generated and is shown below. "+
-				"Flagged repositories will contain secrets flagged for rotation, "+
-				"failed repositories require a manual removal or access rule changes before proceeding and "+
-				"OK repos will not require rotation.\n\n",
-			cmd.username,
-			cmd.orgName,
-			len(planned.Repos),
-		)
-
-		err = writeOrgRevokeRepoList(cmd.io.Stdout(), planned.Repos...)
+	// In the other formats, only the revoked repositories are printed. The plan
+	// is then shown with the confirmation prompt, so the user always sees what
+	// is affected before confirming.
+	printMessages := cmd.output.Format() == outputFormatTable
+	var planOut io.Writer = cmd.io.Stdout()
+	if !printMessages {
+		_, planOut, err = cmd.io.Prompts()
 		if err != nil {
 			return err
 		}
+	}
 
-		flagged := planned.StatusCounts[api.StatusFlagged]
-		failed := planned.StatusCounts[api.StatusFailed]
-		unaffected := planned.StatusCounts[api.StatusOK]
-
-		fmt.Fprintf(cmd.io.Stdout(), "Revocation plan: %d to flag, %d to fail, %d OK.\n\n", flagged, failed, unaffected)
-	} else {
-		fmt.Fprintf(
-			cmd.io.Stdout(),
-			"The user %s has no memberships to any of %s's repos and can be safely removed.\n\n",
-			cmd.username,
-			cmd.orgName,
-		)
+	err = cmd.printPlan(planOut, planned)
+	if err != nil {
+		return err
 	}
 
 	confirmed, err := ui.ConfirmCaseInsensitive(
@@ -98,13 +83,19 @@ func (cmd *OrgRevokeCommand) Run() error {
 		return nil
 	}
 
-	fmt.Fprintf(cmd.io.Stdout(), "\nRevoking user...\n")
+	if printMessages {
+		fmt.Fprintf(cmd.io.Stdout(), "\nRevoking user...\n")
+	}
 
 	revoked, err := client.Orgs().Members().Revoke(cmd.orgName.Value(), cmd.username, nil)
 	if err != nil {
 		return err
 	}
 
+	if !printMessages {
+		return cmd.output.printTable(cmd.io.Stdout(), newOrgRevokeRepoTable(revoked.Repos...))
+	}
+
 	if len(revoked.Repos) > 0 {
 		fmt.Fprintln(cmd.io.Stdout(), "")
 		err = writeOrgRevokeRepoList(cmd.io.Stdout(), revoked.Repos...)
@@ -130,13 +121,64 @@ func (cmd *OrgRevokeCommand) Run() error {
 	return nil
 }
 
-// writeOrgRevokeRepoList is a helper function that writes repos with a status.
-func writeOrgRevokeRepoList(w io.Writer, repos ...*api.RevokeRepoResponse) error {
-	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+// printPlan prints the repositories that are affected by revoking the user.
+func (cmd *OrgRevokeCommand) printPlan(w io.Writer, planned *api.RevokeOrgResponse) error {
+	if len(planned.Repos) == 0 {
+		fmt.Fprintf(
+			w,
+			"The user %s has no memberships to any of %s's repos and can be safely removed.\n\n",
+			cmd.username,
+			cmd.orgName,
+		)
+		return nil
+	}
+
+	fmt.Fprintf(
+		w,
+		"[WARNING] Revoking %s from the %s organization will revoke the user from %d repositories, "+
+			"automatically flagging secrets for rotation.\n\n"+
+			"A revocation plan has been generated and is shown below. "+
+			"Flagged repositories will contain secrets flagged for rotation, "+
+			"failed repositories require a manual removal or access rule changes before proceeding and "+
+			"OK repos will not require rotation.\n\n",
+		cmd.username,
+		cmd.orgName,
+		len(planned.Repos),
+	)
+
+	err := writeOrgRevokeRepoList(w, planned.Repos...)
+	if err != nil {
+		return err
+	}
+
+	flagged := planned.StatusCounts[api.StatusFlagged]
+	failed := planned.StatusCounts[api.StatusFailed]
+	unaffected := planned.StatusCounts[api.StatusOK]
+
+	fmt.Fprintf(w, "Revocation plan: %d to flag, %d to fail, %d OK.\n\n", flagged, failed, unaffected)
+	return nil
+}
+
+// revokedRepoOutput is the structured output format of a repository the user is revoked from.
+type revokedRepoOutput struct {
+	Repo   string `json:"repo" yaml:"repo"`
+	Status string `json:"status" yaml:"status"`
+}
+
+// newOrgRevokeRepoTable returns a table of the repositories with their revoke status.
+func newOrgRevokeRepoTable(repos ...*api.RevokeRepoResponse) *outputTable {
+	table := newOutputTable(2)
 	for _, resp := range repos {
-		fmt.Fprintf(tw, "\t%s/%s\t=> %s\n", resp.Namespace, resp.Name, resp.Status)
+		repo := resp.Namespace + "/" + resp.Name
+		table.add(revokedRepoOutput{Repo: repo, Status: resp.Status}, "", repo, "=> "+resp.Status)
 	}
-	err := tw.Flush()
+	return table
+}
+
+// writeOrgRevokeRepoList is a helper function that writes repos with a status as a table,
+// which is also used for the plan that is shown with the prompt in the other output formats.
+func writeOrgRevokeRepoList(w io.Writer, repos ...*api.RevokeRepoResponse) error {
+	err := newOrgRevokeRepoTable(repos...).printAs(w, outputFormatTable)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package secrethub
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
@@ -143,3 +144,62 @@ func TestOrgRevokeCommand_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestOrgRevokeCommand_Run_JSON(t *testing.T) {
+	cmd := OrgRevokeCommand{
+		orgName:  "company",
+		username: "dev1",
+		output:   &OutputConfig{format: outputFormatJSON},
+		newClient: func() (secrethub.ClientInterface, error) {
+			return fakeclient.Client{
+				OrgService: &fakeclient.OrgService{
+					MemberService: &fakeclient.OrgMemberService{
+						Revoker: fakeclient.OrgMemberRevoker{
+							ReturnsRevokeOrgResponse: &api.RevokeOrgResponse{
+								Repos: []*api.RevokeRepoResponse{
+									{Namespace: "company", Name: "application1", Status: api.StatusFlagged},
+								},
+								StatusCounts: map[string]int{api.StatusFlagged: 1},
+							},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	io := ui.NewFakeIO()
+	io.PromptIn.Buffer = bytes.NewBufferString("dev1")
+	cmd.io = io
+
+	err := cmd.Run()
+	assert.OK(t, err)
+
+	// The plan is shown with the prompt, while the output only contains the revoked repositories.
+	assert.Equal(t, strings.Contains(io.PromptOut.String(), "Revocation plan: 1 to flag, 0 to fail, 0 OK."), true)
+	assert.Equal(t, strings.Contains(io.PromptOut.String(), "company/application1  => flagged"), true)
+	assert.Equal(t, io.StdOut.String(), "[\n    {\n        \"repo\": \"company/application1\",\n        \"status\": \"flagged\"\n    }\n]\n")
+}
+
+func TestNewOrgRevokeRepoTable(t *testing.T) {
+	table := newOrgRevokeRepoTable(
+		&api.RevokeRepoResponse{Namespace: "company", Name: "application1", Status: api.StatusOK},
+		&api.RevokeRepoResponse{Namespace: "company", Name: "application2", Status: api.StatusFlagged},
+	)
+
+	var out bytes.Buffer
+	err := table.printAs(&out, outputFormatTable)
+	assert.OK(t, err)
+	assert.Equal(t, out.String(), ""+
+		"  company/application1  => ok\n"+
+		"  company/application2  => flagged\n")
+
+	out.Reset()
+	err = table.printAs(&out, outputFormatYAML)
+	assert.OK(t, err)
+	assert.Equal(t, out.String(), ""+
+		"- repo: company/application1\n"+
+		"  status: ok\n"+
+		"- repo: company/application2\n"+
+		"  status: flagged\n")
+}
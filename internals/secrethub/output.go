@@ -0,0 +1,204 @@
+package secrethub
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/secrethub/secrethub-cli/internals/cli"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Errors
+var (
+	ErrUnknownOutputFormat = errMain.Code("unknown_output_format").ErrorPref("unknown output format '%s': the options are table, json, yaml or a Go template, e.g. '{{.Path}}'")
+	ErrInvalidOutputFormat = errMain.Code("invalid_output_format").ErrorPref("invalid output template: %s")
+)
+
+const (
+	outputFormatTable = "table"
+	outputFormatJSON  = "json"
+	outputFormatYAML  = "yaml"
+)
+
+// OutputConfig configures the format in which commands print their output.
+// It is configured with the global --format flag.
+type OutputConfig struct {
+	format formatFlag
+}
+
+// NewOutputConfig creates a new OutputConfig that prints in the table format.
+func NewOutputConfig() *OutputConfig {
+	return &OutputConfig{
+		format: outputFormatTable,
+	}
+}
+
+// Register registers the format flag on the provided Registerer.
+func (o *OutputConfig) Register(r FlagRegisterer) {
+	r.Flag("format", "The format to print output in. The options are table, json, yaml or a Go template, e.g. '{{.Path}}'.").Default(outputFormatTable).SetValue(&o.format)
+}
+
+// Format returns the configured output format. Without a configuration,
+// the table format is used.
+func (o *OutputConfig) Format() string {
+	if o == nil {
+		return outputFormatTable
+	}
+	return string(o.format)
+}
+
+// printTable writes the table to w in the configured output format.
+func (o *OutputConfig) printTable(w io.Writer, t *outputTable) error {
+	return t.printAs(w, o.Format())
+}
+
+// print writes a single record to w in the configured output format.
+// In the table format, the record is printed as indented JSON.
+func (o *OutputConfig) print(w io.Writer, record interface{}) error {
+	format := o.Format()
+	if format == outputFormatTable {
+		format = outputFormatJSON
+	}
+	return printRecord(w, format, record)
+}
+
+// formatFlag is an output format that is validated when it is set.
+type formatFlag string
+
+// String implements the flag.Value interface.
+func (f formatFlag) String() string {
+	return string(f)
+}
+
+// Set sets the output format when the given value is valid.
+func (f *formatFlag) Set(value string) error {
+	err := validateOutputFormat(value)
+	if err != nil {
+		return err
+	}
+	*f = formatFlag(value)
+	return nil
+}
+
+// validateOutputFormat returns an error when the given format is not one of the
+// supported formats or an invalid template.
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputFormatTable, outputFormatJSON, outputFormatYAML:
+		return nil
+	}
+
+	if !isOutputTemplate(format) {
+		return ErrUnknownOutputFormat(format)
+	}
+
+	_, err := template.New("format").Parse(format)
+	if err != nil {
+		return ErrInvalidOutputFormat(err)
+	}
+	return nil
+}
+
+// isOutputTemplate returns whether the format is a Go template.
+func isOutputTemplate(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// outputTable collects the records a command prints. In the table format,
+// every record is printed as a row under the given header. The other formats
+// print the records themselves, so their fields define the output schema.
+type outputTable struct {
+	padding int
+	header  []string
+	rows    [][]string
+	records []interface{}
+}
+
+// newOutputTable returns a table with the given header. The padding is the
+// minimal number of spaces between the columns in the table format.
+// A table without a header only prints its rows.
+func newOutputTable(padding int, header ...string) *outputTable {
+	return &outputTable{
+		padding: padding,
+		header:  header,
+		records: []interface{}{},
+	}
+}
+
+// add adds a record to the table, with the given row as its table representation.
+func (t *outputTable) add(record interface{}, row ...string) {
+	t.records = append(t.records, record)
+	t.rows = append(t.rows, row)
+}
+
+// printAs writes the table to w in the given output format.
+func (t *outputTable) printAs(w io.Writer, format string) error {
+	if format != outputFormatTable {
+		return printRecords(w, format, t.records)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, t.padding, t.padding, ' ', 0)
+	if len(t.header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	}
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printRecords writes a list of records to w in the given output format.
+// A template is executed once for every record.
+func printRecords(w io.Writer, format string, records []interface{}) error {
+	if !isOutputTemplate(format) {
+		return printRecord(w, format, records)
+	}
+
+	for _, record := range records {
+		err := printRecord(w, format, record)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printRecord writes a record to w in the given json, yaml or template format.
+func printRecord(w io.Writer, format string, record interface{}) error {
+	switch format {
+	case outputFormatJSON:
+		out, err := cli.PrettyJSON(record)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, out)
+		return err
+	case outputFormatYAML:
+		out, err := yaml.Marshal(record)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	}
+
+	if !isOutputTemplate(format) {
+		return ErrUnknownOutputFormat(format)
+	}
+
+	tpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return ErrInvalidOutputFormat(err)
+	}
+
+	err = tpl.Execute(w, record)
+	if err != nil {
+		return ErrInvalidOutputFormat(err)
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
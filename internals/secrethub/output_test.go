@@ -0,0 +1,173 @@
+package secrethub
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestOutputTable(t *testing.T) {
+	createdAt := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	table := newOutputTable(2, "NAME", "STATUS")
+	table.add(repoOutput{Path: "namespace/repo", Status: "ok", CreatedAt: createdAt}, "namespace/repo", "ok")
+	table.add(repoOutput{Path: "namespace/other-repo", Status: "ok", CreatedAt: createdAt}, "namespace/other-repo", "ok")
+
+	cases := map[string]struct {
+		format string
+		out    string
+		err    error
+	}{
+		"table": {
+			format: outputFormatTable,
+			out: "NAME                  STATUS\n" +
+				"namespace/repo        ok\n" +
+				"namespace/other-repo  ok\n",
+		},
+		"json": {
+			format: outputFormatJSON,
+			out: "[\n" +
+				"    {\n" +
+				"        \"path\": \"namespace/repo\",\n" +
+				"        \"status\": \"ok\",\n" +
+				"        \"created_at\": \"2019-01-01T12:00:00Z\"\n" +
+				"    },\n" +
+				"    {\n" +
+				"        \"path\": \"namespace/other-repo\",\n" +
+				"        \"status\": \"ok\",\n" +
+				"        \"created_at\": \"2019-01-01T12:00:00Z\"\n" +
+				"    }\n" +
+				"]\n",
+		},
+		"yaml": {
+			format: outputFormatYAML,
+			out: "- path: namespace/repo\n" +
+				"  status: ok\n" +
+				"  created_at: 2019-01-01T12:00:00Z\n" +
+				"- path: namespace/other-repo\n" +
+				"  status: ok\n" +
+				"  created_at: 2019-01-01T12:00:00Z\n",
+		},
+		"template": {
+			format: "{{.Path}} ({{.Status}})",
+			out:    "namespace/repo (ok)\nnamespace/other-repo (ok)\n",
+		},
+		"unknown format": {
+			format: "xml",
+			err:    ErrUnknownOutputFormat("xml"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := table.printAs(&buf, tc.format)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, buf.String(), tc.out)
+		})
+	}
+}
+
+// TestPrintRecord_InspectOutput tests that the JSON output of the inspect commands
+// keeps its original keys, while the YAML output uses snake_case keys.
+func TestPrintRecord_InspectOutput(t *testing.T) {
+	record := secretVersionOutput{
+		Version:   2,
+		CreatedAt: "2019-01-01 12:00:00",
+		Status:    "ok",
+	}
+
+	var buf bytes.Buffer
+	err := printRecord(&buf, outputFormatJSON, record)
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), "{\n"+
+		"    \"Version\": 2,\n"+
+		"    \"CreatedAt\": \"2019-01-01 12:00:00\",\n"+
+		"    \"Status\": \"ok\"\n"+
+		"}\n")
+
+	buf.Reset()
+	err = printRecord(&buf, outputFormatYAML, record)
+	assert.OK(t, err)
+	assert.Equal(t, buf.String(), "version: 2\n"+
+		"created_at: \"2019-01-01 12:00:00\"\n"+
+		"status: ok\n")
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	cases := map[string]struct {
+		format string
+		valid  bool
+	}{
+		"table":            {format: "table", valid: true},
+		"json":             {format: "json", valid: true},
+		"yaml":             {format: "yaml", valid: true},
+		"template":         {format: "{{.Path}}", valid: true},
+		"unknown":          {format: "csv", valid: false},
+		"invalid template": {format: "{{.Path", valid: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateOutputFormat(tc.format)
+			assert.Equal(t, err == nil, tc.valid)
+		})
+	}
+}
+
+func TestOutputConfig_Print(t *testing.T) {
+	record := secretVersionOutput{
+		Version:   2,
+		CreatedAt: "2019-01-01 12:00:00",
+		Status:    "ok",
+	}
+
+	cases := map[string]struct {
+		output *OutputConfig
+		out    string
+	}{
+		"nil": {
+			output: nil,
+			out:    "{\n    \"Version\": 2,\n    \"CreatedAt\": \"2019-01-01 12:00:00\",\n    \"Status\": \"ok\"\n}\n",
+		},
+		"table": {
+			output: NewOutputConfig(),
+			out:    "{\n    \"Version\": 2,\n    \"CreatedAt\": \"2019-01-01 12:00:00\",\n    \"Status\": \"ok\"\n}\n",
+		},
+		"yaml": {
+			output: &OutputConfig{format: outputFormatYAML},
+			out:    "version: 2\ncreated_at: \"2019-01-01 12:00:00\"\nstatus: ok\n",
+		},
+		"template": {
+			output: &OutputConfig{format: "{{.Version}}"},
+			out:    "2\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := tc.output.print(&buf, record)
+
+			assert.OK(t, err)
+			assert.Equal(t, buf.String(), tc.out)
+		})
+	}
+}
+
+func TestFormatFlag_Set(t *testing.T) {
+	var flag formatFlag
+
+	err := flag.Set("json")
+	assert.OK(t, err)
+	assert.Equal(t, flag.String(), outputFormatJSON)
+
+	err = flag.Set("csv")
+	assert.Equal(t, err, ErrUnknownOutputFormat("csv"))
+	assert.Equal(t, flag.String(), outputFormatJSON)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/cli/posix"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
 
 	"github.com/secrethub/secrethub-go/internals/api"
 
@@ -25,6 +26,7 @@ type ReadCommand struct {
 	clipper             clip.Clipper
 	outFile             string
 	fileMode            filemode.FileMode
+	field               string
 	newClient           newClientFunc
 }
 
@@ -50,6 +52,7 @@ func (cmd *ReadCommand) Register(r command.Registerer) {
 		),
 	).Short('c').BoolVar(&cmd.useClipboard)
 	clause.Flag("out-file", "Write the secret value to this file.").Short('o').StringVar(&cmd.outFile)
+	clause.Flag("field", "Read a field of a secret that contains a JSON or YAML object or array instead of the whole secret. Nested fields and array elements are separated by dots, e.g. `database.hosts.0`.").PlaceHolder("FIELD").StringVar(&cmd.field)
 	clause.Flag("file-mode", "Set filemode for the output file. Defaults to 0600 (read and write for current user) and is ignored without the --out-file flag.").Default("0600").SetValue(&cmd.fileMode)
 
 	command.BindAction(clause, cmd.Run)
@@ -67,8 +70,17 @@ func (cmd *ReadCommand) Run() error {
 		return err
 	}
 
+	data := secret.Data
+	if cmd.field != "" {
+		value, err := tpl.ExtractField(cmd.path.Value(), string(data), cmd.field)
+		if err != nil {
+			return err
+		}
+		data = []byte(value)
+	}
+
 	if cmd.useClipboard {
-		err = WriteClipboardAutoClear(secret.Data, cmd.clearClipboardAfter, cmd.clipper)
+		err = WriteClipboardAutoClear(data, cmd.clearClipboardAfter, cmd.clipper)
 		if err != nil {
 			return err
 		}
@@ -82,14 +94,14 @@ func (cmd *ReadCommand) Run() error {
 	}
 
 	if cmd.outFile != "" {
-		err = ioutil.WriteFile(cmd.outFile, posix.AddNewLine(secret.Data), cmd.fileMode.FileMode())
+		err = ioutil.WriteFile(cmd.outFile, posix.AddNewLine(data), cmd.fileMode.FileMode())
 		if err != nil {
 			return ErrCannotWrite(cmd.outFile, err)
 		}
 	}
 
 	if cmd.outFile == "" && !cmd.useClipboard {
-		fmt.Fprintf(cmd.io.Stdout(), "%s", string(posix.AddNewLine(secret.Data)))
+		fmt.Fprintf(cmd.io.Stdout(), "%s", string(posix.AddNewLine(data)))
 	}
 
 	return nil
@@ -2,8 +2,72 @@ package secrethub
 
 import (
 	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
 )
 
 func TestReadCommand_Run(t *testing.T) {
 	// TODO SHDEV-1029 Test ReadCommand.
 }
+
+func TestReadCommand_Field(t *testing.T) {
+	cases := map[string]struct {
+		data  string
+		field string
+		out   string
+		err   error
+	}{
+		"no field": {
+			data: `{"user": "admin"}`,
+			out:  "{\"user\": \"admin\"}\n",
+		},
+		"field": {
+			data:  `{"db": {"user": "admin"}}`,
+			field: "db.user",
+			out:   "admin\n",
+		},
+		"missing field": {
+			data:  `{"db": {"user": "admin"}}`,
+			field: "db.password",
+			err:   tpl.ErrFieldNotFound("db.password", "namespace/repo/secret"),
+		},
+		"not structured": {
+			data:  "admin",
+			field: "user",
+			err:   tpl.ErrSecretNotStructured("user", "namespace/repo/secret"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := ui.NewFakeIO()
+			cmd := ReadCommand{
+				io:    io,
+				path:  "namespace/repo/secret",
+				field: tc.field,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte(tc.data)},
+								},
+							},
+						},
+					}, nil
+				},
+			}
+
+			err := cmd.Run()
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.StdOut.String(), tc.out)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// RefsCommand handles operations on references to secrets in files.
+type RefsCommand struct {
+	io        ui.IO
+	newClient newClientFunc
+	output    *OutputConfig
+}
+
+// NewRefsCommand creates a new RefsCommand.
+func NewRefsCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *RefsCommand {
+	return &RefsCommand{
+		io:        io,
+		newClient: newClient,
+		output:    output,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *RefsCommand) Register(r command.Registerer) {
+	clause := r.Command("refs", "Manage references to secrets in files.")
+	NewRefsCheckCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
+}
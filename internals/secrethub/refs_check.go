@@ -0,0 +1,534 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+// Errors
+var (
+	ErrReferenceCheckFailed = errMain.Code("reference_check_failed").ErrorPref("found %d problem(s) with secret references")
+)
+
+// The rules that are checked by the refs check command.
+const (
+	refsRuleSecretNotFound    = "secret-not-found"
+	refsRuleSecretUnreadable  = "secret-unreadable"
+	refsRuleInvalidReference  = "invalid-reference"
+	refsRuleUndefinedVariable = "undefined-variable"
+	refsRuleTemplateError     = "template-error"
+)
+
+// refsRules describes the rules that are checked by the refs check command.
+var refsRules = []struct {
+	id          string
+	description string
+}{
+	{refsRuleSecretNotFound, "The referenced secret does not exist."},
+	{refsRuleSecretUnreadable, "The referenced secret cannot be read with the current credential."},
+	{refsRuleInvalidReference, "The reference is not a valid secret path."},
+	{refsRuleUndefinedVariable, "A template variable has no value."},
+	{refsRuleTemplateError, "The template cannot be parsed."},
+}
+
+// secretReferenceRegexp matches a secrethub:// reference in a line of text.
+var secretReferenceRegexp = regexp.MustCompile(regexp.QuoteMeta(secretReferencePrefix) + "[^\\s\"'`<>,;()\\[\\]{}]+")
+
+// errSecretNotRead is returned by the secret reader of the refs check command,
+// which only checks whether secrets exist and never reads their values.
+var errSecretNotRead = errors.New("secret is not read")
+
+// RefsCheckCommand checks that the secrets referenced in the files in a directory tree exist and can be read.
+type RefsCheckCommand struct {
+	io              ui.IO
+	newClient       newClientFunc
+	osEnv           []string
+	dir             string
+	templateGlobs   []string
+	referenceGlobs  []string
+	excludes        []string
+	templateVars    map[string]string
+	templateVersion string
+	sarif           bool
+	output          *OutputConfig
+}
+
+// NewRefsCheckCommand creates a new RefsCheckCommand.
+func NewRefsCheckCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *RefsCheckCommand {
+	return &RefsCheckCommand{
+		io:           io,
+		newClient:    newClient,
+		osEnv:        os.Environ(),
+		templateVars: make(map[string]string),
+		output:       output,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *RefsCheckCommand) Register(r command.Registerer) {
+	clause := r.Command("check", "Check that all secrets referenced in the files in a directory exist and can be read.")
+	clause.HelpLong("Scans a directory tree for templates and secrethub:// references and checks, without reading any secret values, " +
+		"that every referenced secret exists and can be read and that every template variable has a value. " +
+		"Templates with a name ending in .env are checked as env files, as used by `secrethub run --env-file`, " +
+		"other templates as used by `secrethub inject`. Glob patterns without a slash are matched against the name of a file, " +
+		"other patterns against its path relative to the directory. " +
+		"\n\nEvery problem is printed with the file and line it occurs on. The command fails when any problem is found.")
+	clause.Arg("dir", "The directory to check").Default(".").ExistingDirVar(&cmd.dir)
+	clause.Flag("template-glob", "Check the files matching the glob pattern as templates. Can be repeated.").Default("secrethub.env", "*.secrethub.env", "*.tpl").StringsVar(&cmd.templateGlobs)
+	clause.Flag("reference-glob", "Check the secrethub:// references in the files matching the glob pattern. Can be repeated.").Default("*.env", "*.yml", "*.yaml", "*.json", "Dockerfile").StringsVar(&cmd.referenceGlobs)
+	clause.Flag("exclude", "Skip the files and directories matching the glob pattern. Can be repeated.").Default(".git", "node_modules", "vendor").StringsVar(&cmd.excludes)
+	clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod").Short('v').StringMapVar(&cmd.templateVars)
+	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, v3, latest or auto to automatically detect the version.").Default("auto").StringVar(&cmd.templateVersion)
+	clause.Flag("sarif", "Print the problems as a SARIF log, e.g. for code scanning in CI.").BoolVar(&cmd.sarif)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run checks the references in the directory and prints the problems that are found.
+func (cmd *RefsCheckCommand) Run() error {
+	osEnvMap, _ := parseKeyValueStringsToMap(cmd.osEnv)
+	varReader, err := newVariableReader(osEnvMap, cmd.templateVars)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	checker := &referenceChecker{
+		templateGlobs:    cmd.templateGlobs,
+		referenceGlobs:   cmd.referenceGlobs,
+		excludes:         cmd.excludes,
+		varReader:        varReader,
+		osEnv:            osEnvMap,
+		templateVersion:  cmd.templateVersion,
+		getSecretVersion: client.Secrets().Versions().GetWithoutData,
+		results:          make(map[string]*refsDiagnostic),
+	}
+
+	diagnostics, err := checker.check(cmd.dir)
+	if err != nil {
+		return err
+	}
+
+	if cmd.sarif {
+		err = printSARIF(cmd.io.Stdout(), diagnostics)
+	} else {
+		table := newOutputTable(2, "LOCATION", "RULE", "MESSAGE")
+		for _, diagnostic := range diagnostics {
+			table.add(diagnostic, diagnostic.location(), diagnostic.Rule, diagnostic.Message)
+		}
+		err = cmd.output.printTable(cmd.io.Stdout(), table)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(diagnostics) > 0 {
+		return ErrReferenceCheckFailed(len(diagnostics))
+	}
+	return nil
+}
+
+// refsDiagnostic is a problem with a reference to a secret.
+type refsDiagnostic struct {
+	File    string `json:"file" yaml:"file"`
+	Line    int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Rule    string `json:"rule" yaml:"rule"`
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// location returns the file and, when known, the line of the problem in the file:line format.
+func (d refsDiagnostic) location() string {
+	if d.Line > 0 {
+		return d.File + ":" + strconv.Itoa(d.Line)
+	}
+	return d.File
+}
+
+// referenceChecker checks the templates and secrethub:// references in files.
+// Secrets are resolved with getSecretVersion, which should not fetch their
+// values, and the result of every path is remembered.
+type referenceChecker struct {
+	templateGlobs    []string
+	referenceGlobs   []string
+	excludes         []string
+	varReader        tpl.VariableReader
+	osEnv            map[string]string
+	templateVersion  string
+	getSecretVersion func(path string) (*api.SecretVersion, error)
+	results          map[string]*refsDiagnostic
+}
+
+// check walks the directory tree in lexical order and returns the problems found in the matching files.
+func (c *referenceChecker) check(root string) ([]refsDiagnostic, error) {
+	diagnostics := []refsDiagnostic{}
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+
+		if matchGlobs(c.excludes, rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var found []refsDiagnostic
+		if info.IsDir() {
+			return nil
+		} else if matchGlobs(c.templateGlobs, rel) {
+			found, err = c.checkTemplateFile(file)
+		} else if matchGlobs(c.referenceGlobs, rel) {
+			found, err = c.checkReferenceFile(file)
+		}
+		if err != nil {
+			return err
+		}
+
+		diagnostics = append(diagnostics, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diagnostics, nil
+}
+
+// checkTemplateFile checks a template. Files with a name ending in .env are
+// checked as env files, other files as templates for the inject command.
+func (c *referenceChecker) checkTemplateFile(file string) ([]refsDiagnostic, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, ErrCannotReadFile(file, err)
+	}
+
+	parser, err := getTemplateParser(raw, c.templateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(file, ".env") {
+		return c.checkEnvFile(file, raw, parser)
+	}
+
+	var diagnostics []refsDiagnostic
+	for i, line := range strings.Split(string(raw), "\n") {
+		lineNo := i + 1
+		template, err := parser.Parse(strings.TrimSuffix(line, "\r"), lineNo, 1)
+		if err != nil {
+			diagnostics = append(diagnostics, refsDiagnostic{File: file, Line: lineNo, Rule: refsRuleTemplateError, Message: err.Error()})
+			continue
+		}
+
+		found, err := c.checkTemplate(file, lineNo, template)
+		if err != nil {
+			return nil, err
+		}
+		diagnostics = append(diagnostics, found...)
+	}
+	return diagnostics, nil
+}
+
+// checkEnvFile checks the keys and values of an env file.
+func (c *referenceChecker) checkEnvFile(file string, raw []byte, parser tpl.Parser) ([]refsDiagnostic, error) {
+	env, err := parseEnvironment(bytes.NewReader(raw), dotEnvExpansion{osEnv: c.osEnv, varReader: c.varReader, parser: parser})
+	if err != nil {
+		return []refsDiagnostic{{File: file, Rule: refsRuleTemplateError, Message: err.Error()}}, nil
+	}
+
+	sort.Slice(env, func(i, j int) bool {
+		return env[i].lineNumber < env[j].lineNumber
+	})
+
+	var diagnostics []refsDiagnostic
+	for _, envvar := range env {
+		key, err := parser.Parse(envvar.key, envvar.lineNumber, envvar.columnNumberKey)
+		if err != nil {
+			diagnostics = append(diagnostics, refsDiagnostic{File: file, Line: envvar.lineNumber, Rule: refsRuleTemplateError, Message: err.Error()})
+			continue
+		}
+
+		_, err = key.Evaluate(c.varReader, secretReaderNotAllowed{})
+		if err == ErrSecretsNotAllowedInKey {
+			diagnostics = append(diagnostics, refsDiagnostic{File: file, Line: envvar.lineNumber, Rule: refsRuleTemplateError, Message: err.Error()})
+		} else if err != nil {
+			diagnostics = append(diagnostics, refsDiagnostic{File: file, Line: envvar.lineNumber, Rule: refsRuleUndefinedVariable, Message: err.Error()})
+		}
+
+		value, err := parser.Parse(envvar.value, envvar.lineNumber, envvar.columnNumberValue)
+		if err != nil {
+			diagnostics = append(diagnostics, refsDiagnostic{File: file, Line: envvar.lineNumber, Rule: refsRuleTemplateError, Message: err.Error()})
+			continue
+		}
+
+		found, err := c.checkTemplate(file, envvar.lineNumber, value)
+		if err != nil {
+			return nil, err
+		}
+		diagnostics = append(diagnostics, found...)
+	}
+	return diagnostics, nil
+}
+
+// checkTemplate checks the template variables and the secrets in a parsed template.
+func (c *referenceChecker) checkTemplate(file string, lineNo int, template tpl.Template) ([]refsDiagnostic, error) {
+	paths, err := template.SecretPaths(c.varReader)
+	if err != nil {
+		return []refsDiagnostic{{File: file, Line: lineNo, Rule: refsRuleUndefinedVariable, Message: err.Error()}}, nil
+	}
+
+	// Evaluating the template also checks the variables outside of secret tags.
+	// The evaluation stops at the first secret, as secrets are never read.
+	var diagnostics []refsDiagnostic
+	_, err = template.Evaluate(c.varReader, secretReaderFunc(func(string) (string, error) {
+		return "", errSecretNotRead
+	}))
+	if err != nil && err != errSecretNotRead {
+		diagnostics = append(diagnostics, refsDiagnostic{File: file, Line: lineNo, Rule: refsRuleUndefinedVariable, Message: err.Error()})
+	}
+
+	for _, path := range paths {
+		diagnostic, err := c.checkPath(file, lineNo, path)
+		if err != nil {
+			return nil, err
+		}
+		if diagnostic != nil {
+			diagnostics = append(diagnostics, *diagnostic)
+		}
+	}
+	return diagnostics, nil
+}
+
+// checkReferenceFile checks the secrethub:// references on every line of a file.
+func (c *referenceChecker) checkReferenceFile(file string) ([]refsDiagnostic, error) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, ErrCannotReadFile(file, err)
+	}
+
+	var diagnostics []refsDiagnostic
+	for i, line := range strings.Split(string(raw), "\n") {
+		for _, ref := range secretReferenceRegexp.FindAllString(line, -1) {
+			path, _ := tpl.SplitField(strings.TrimPrefix(ref, secretReferencePrefix))
+			diagnostic, err := c.checkPath(file, i+1, path)
+			if err != nil {
+				return nil, err
+			}
+			if diagnostic != nil {
+				diagnostics = append(diagnostics, *diagnostic)
+			}
+		}
+	}
+	return diagnostics, nil
+}
+
+// checkPath returns the problem with the secret at the given path, if any.
+func (c *referenceChecker) checkPath(file string, lineNo int, secretPath string) (*refsDiagnostic, error) {
+	result, ok := c.results[secretPath]
+	if !ok {
+		var err error
+		result, err = c.resolve(secretPath)
+		if err != nil {
+			return nil, err
+		}
+		c.results[secretPath] = result
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	diagnostic := *result
+	diagnostic.File = file
+	diagnostic.Line = lineNo
+	return &diagnostic, nil
+}
+
+// resolve checks whether the secret at the given path exists and can be read.
+// Errors that are not caused by the reference itself, like connection errors,
+// are returned as an error instead of as a problem with the reference.
+func (c *referenceChecker) resolve(secretPath string) (*refsDiagnostic, error) {
+	_, err := api.NewSecretPath(secretPath)
+	if err != nil {
+		return &refsDiagnostic{Rule: refsRuleInvalidReference, Path: secretPath, Message: err.Error()}, nil
+	}
+
+	_, err = c.getSecretVersion(secretPath)
+	if api.IsErrNotFound(err) {
+		return &refsDiagnostic{Rule: refsRuleSecretNotFound, Path: secretPath, Message: ErrSecretNotFound(secretPath).Error()}, nil
+	}
+	if statusErr, ok := err.(errio.PublicStatusError); ok && statusErr.StatusCode == http.StatusForbidden {
+		return &refsDiagnostic{Rule: refsRuleSecretUnreadable, Path: secretPath, Message: fmt.Sprintf("cannot read secret %s: %s", secretPath, err)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// secretReaderFunc implements tpl.SecretReader with a function.
+type secretReaderFunc func(path string) (string, error)
+
+// ReadSecret implements tpl.SecretReader.
+func (f secretReaderFunc) ReadSecret(path string) (string, error) {
+	return f(path)
+}
+
+// matchGlobs returns whether the name or the slash-separated relative path of a
+// file matches any of the glob patterns. Patterns without a slash are matched
+// against the name of the file, other patterns against the whole path.
+func matchGlobs(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+
+		match, err := path.Match(pattern, name)
+		if err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// SARIF log format, version 2.1.0. Only the properties used by the refs check command are defined.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+// printSARIF writes the problems to w as a SARIF log.
+func printSARIF(w io.Writer, diagnostics []refsDiagnostic) error {
+	rules := make([]sarifRule, len(refsRules))
+	for i, rule := range refsRules {
+		rules[i] = sarifRule{
+			ID:               rule.id,
+			ShortDescription: sarifMessage{Text: rule.description},
+		}
+	}
+
+	results := make([]sarifResult, len(diagnostics))
+	for i, diagnostic := range diagnostics {
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(diagnostic.File)},
+		}
+		if diagnostic.Line > 0 {
+			location.Region = &sarifRegion{StartLine: diagnostic.Line}
+		}
+
+		results[i] = sarifResult{
+			RuleID:    diagnostic.Rule,
+			Level:     "error",
+			Message:   sarifMessage{Text: diagnostic.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		}
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           ApplicationName,
+						Version:        Version,
+						InformationURI: "https://secrethub.io",
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
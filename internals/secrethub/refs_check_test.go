@@ -0,0 +1,199 @@
+package secrethub
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/internals/errio"
+)
+
+func TestReferenceChecker(t *testing.T) {
+	errForbidden := errio.Namespace("test").Code("forbidden").StatusError("access denied", http.StatusForbidden)
+
+	cases := map[string]struct {
+		files    map[string]string
+		vars     map[string]string
+		expected []refsDiagnostic
+		err      error
+	}{
+		"no problems": {
+			files: map[string]string{
+				"secrethub.env":  "DB_PASSWORD={{ company/app/db/password }}\nHOST=${host:-localhost}",
+				"config.tpl":     "password: {{ ${env}/db/password }}",
+				"docker.yml":     "DB_PASSWORD: secrethub://company/app/db/password",
+				"other/data.txt": "secrethub://company/app/missing",
+			},
+			vars:     map[string]string{"env": "company/app"},
+			expected: []refsDiagnostic{},
+		},
+		"missing secret in env file": {
+			files: map[string]string{
+				"secrethub.env": "# comment\nDB_USER=admin\nDB_PASSWORD={{ company/app/missing }}",
+			},
+			expected: []refsDiagnostic{
+				{File: "secrethub.env", Line: 3, Rule: refsRuleSecretNotFound, Path: "company/app/missing", Message: ErrSecretNotFound("company/app/missing").Error()},
+			},
+		},
+		"unreadable secret in template": {
+			files: map[string]string{
+				"app/config.tpl": "user: admin\npassword: {{ company/app/forbidden }}",
+			},
+			expected: []refsDiagnostic{
+				{File: "app/config.tpl", Line: 2, Rule: refsRuleSecretUnreadable, Path: "company/app/forbidden", Message: "cannot read secret company/app/forbidden: " + errForbidden.Error()},
+			},
+		},
+		"missing secret in reference": {
+			files: map[string]string{
+				"docker-compose.yml": "environment:\n  - DB_PASSWORD=secrethub://company/app/missing#field\n",
+			},
+			expected: []refsDiagnostic{
+				{File: "docker-compose.yml", Line: 2, Rule: refsRuleSecretNotFound, Path: "company/app/missing", Message: ErrSecretNotFound("company/app/missing").Error()},
+			},
+		},
+		"invalid reference": {
+			files: map[string]string{
+				"app.env": "DB_PASSWORD=secrethub://company/app",
+			},
+			expected: []refsDiagnostic{
+				{File: "app.env", Line: 1, Rule: refsRuleInvalidReference, Path: "company/app", Message: api.ErrInvalidSecretPath("company/app").Error()},
+			},
+		},
+		"undefined variable": {
+			files: map[string]string{
+				"secrethub.env": "DB_PASSWORD={{ ${env}/db/password }}\nREGION=${region:?must set region}",
+			},
+			expected: []refsDiagnostic{
+				{File: "secrethub.env", Line: 1, Rule: refsRuleUndefinedVariable, Message: "variable not found: env"},
+				{File: "secrethub.env", Line: 2, Rule: refsRuleUndefinedVariable, Message: tpl.ErrTemplateVarRequired("region", "must set region").Error()},
+			},
+		},
+		"template error": {
+			files: map[string]string{
+				"config.tpl": "ok\n{{ company/app/db/password",
+			},
+			expected: []refsDiagnostic{
+				{File: "config.tpl", Line: 2, Rule: refsRuleTemplateError, Message: tpl.ErrSecretTagNotClosed(2, 27).Error()},
+			},
+		},
+		"excluded directory": {
+			files: map[string]string{
+				"node_modules/pkg/secrethub.env": "DB_PASSWORD={{ company/app/missing }}",
+			},
+			expected: []refsDiagnostic{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "secrethub-refs-check")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+
+			for name, content := range tc.files {
+				file := filepath.Join(dir, filepath.FromSlash(name))
+				err = os.MkdirAll(filepath.Dir(file), 0755)
+				assert.OK(t, err)
+				err = ioutil.WriteFile(file, []byte(content), 0644)
+				assert.OK(t, err)
+			}
+
+			checker := &referenceChecker{
+				templateGlobs:   []string{"secrethub.env", "*.tpl"},
+				referenceGlobs:  []string{"*.env", "*.yml"},
+				excludes:        []string{"node_modules"},
+				varReader:       fakes.FakeVariableReader{Variables: tc.vars},
+				templateVersion: "auto",
+				getSecretVersion: func(path string) (*api.SecretVersion, error) {
+					switch path {
+					case "company/app/missing":
+						return nil, api.ErrSecretNotFound
+					case "company/app/forbidden":
+						return nil, errForbidden
+					}
+					return &api.SecretVersion{}, nil
+				},
+				results: make(map[string]*refsDiagnostic),
+			}
+
+			actual, err := checker.check(dir)
+			assert.Equal(t, err, tc.err)
+
+			for i := range actual {
+				rel, err := filepath.Rel(dir, actual[i].File)
+				assert.OK(t, err)
+				actual[i].File = filepath.ToSlash(rel)
+			}
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestReferenceChecker_ConnectionError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-refs-check")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "secrethub.env"), []byte("DB_PASSWORD={{ company/app/db/password }}"), 0644)
+	assert.OK(t, err)
+
+	testErr := errors.New("connection refused")
+	checker := &referenceChecker{
+		templateGlobs:   []string{"secrethub.env"},
+		templateVersion: "auto",
+		varReader:       fakes.FakeVariableReader{},
+		getSecretVersion: func(path string) (*api.SecretVersion, error) {
+			return nil, testErr
+		},
+		results: make(map[string]*refsDiagnostic),
+	}
+
+	_, err = checker.check(dir)
+	assert.Equal(t, err, testErr)
+}
+
+func TestPrintSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	err := printSARIF(&buf, []refsDiagnostic{
+		{File: "secrethub.env", Line: 3, Rule: refsRuleSecretNotFound, Path: "company/app/missing", Message: "the secret company/app/missing does not exist"},
+		{File: "app.env", Rule: refsRuleTemplateError, Message: "template is not formatted as key=value pairs"},
+	})
+	assert.OK(t, err)
+
+	var log sarifLog
+	err = json.Unmarshal(buf.Bytes(), &log)
+	assert.OK(t, err)
+
+	assert.Equal(t, log.Version, "2.1.0")
+	assert.Equal(t, len(log.Runs), 1)
+	assert.Equal(t, len(log.Runs[0].Tool.Driver.Rules), len(refsRules))
+	assert.Equal(t, log.Runs[0].Results, []sarifResult{
+		{
+			RuleID:  refsRuleSecretNotFound,
+			Level:   "error",
+			Message: sarifMessage{Text: "the secret company/app/missing does not exist"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "secrethub.env"},
+				Region:           &sarifRegion{StartLine: 3},
+			}}},
+		},
+		{
+			RuleID:  refsRuleTemplateError,
+			Level:   "error",
+			Message: sarifMessage{Text: "template is not formatted as key=value pairs"},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "app.env"},
+			}}},
+		},
+	})
+}
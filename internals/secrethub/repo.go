@@ -9,13 +9,15 @@ import (
 type RepoCommand struct {
 	io        ui.IO
 	newClient newClientFunc
+	output    *OutputConfig
 }
 
 // NewRepoCommand creates a new RepoCommand.
-func NewRepoCommand(io ui.IO, newClient newClientFunc) *RepoCommand {
+func NewRepoCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *RepoCommand {
 	return &RepoCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -26,10 +28,12 @@ func (cmd *RepoCommand) Register(r command.Registerer) {
 	clause.Alias("repos")
 	clause.Alias("repositories")
 	NewRepoInitCommand(cmd.io, cmd.newClient).Register(clause)
-	NewRepoInspectCommand(cmd.io, cmd.newClient).Register(clause)
+	NewRepoInspectCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 	NewRepoInviteCommand(cmd.io, cmd.newClient).Register(clause)
 	NewRepoExportCommand(cmd.io, cmd.newClient).Register(clause)
-	NewRepoLSCommand(cmd.io, cmd.newClient).Register(clause)
-	NewRepoRevokeCommand(cmd.io, cmd.newClient).Register(clause)
+	NewRepoImportCommand(cmd.io, cmd.newClient).Register(clause)
+	NewRepoVerifyExportCommand(cmd.io).Register(clause)
+	NewRepoLSCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
+	NewRepoRevokeCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 	NewRepoRmCommand(cmd.io, cmd.newClient).Register(clause)
 }
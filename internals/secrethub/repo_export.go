@@ -3,6 +3,8 @@ package secrethub
 import (
 	"archive/zip"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"time"
@@ -12,19 +14,27 @@ import (
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+
+	"golang.org/x/crypto/openpgp"
 )
 
 // Error
 var (
-	ErrExportAlreadyExists = errMain.Code("export_file_already_exists").Error("the export file already exists")
+	ErrExportAlreadyExists   = errMain.Code("export_file_already_exists").Error("the export file already exists")
+	ErrEmptyExportPassphrase = errMain.Code("empty_export_passphrase").Error("the passphrase to encrypt the export with cannot be empty")
 )
 
 // RepoExportCommand exports a repo to a zip file.
 type RepoExportCommand struct {
-	path      api.RepoPath
-	zipName   string
-	io        ui.IO
-	newClient newClientFunc
+	path       api.RepoPath
+	zipName    string
+	encryptTo  string
+	passphrase bool
+	io         ui.IO
+	newClient  newClientFunc
+	readFile   func(filename string) ([]byte, error)
+	timeNow    func() time.Time
 }
 
 // NewRepoExportCommand creates a new RepoExportCommand.
@@ -32,6 +42,8 @@ func NewRepoExportCommand(io ui.IO, newClient newClientFunc) *RepoExportCommand
 	return &RepoExportCommand{
 		io:        io,
 		newClient: newClient,
+		readFile:  ioutil.ReadFile,
+		timeNow:   time.Now,
 	}
 }
 
@@ -39,33 +51,58 @@ func NewRepoExportCommand(io ui.IO, newClient newClientFunc) *RepoExportCommand
 func (cmd *RepoExportCommand) Register(r command.Registerer) {
 	clause := r.Command("export", "Export the repository to a zip file.")
 	clause.Arg("repo-path", "The repository to export").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.path)
-	clause.Arg("zip-file-name", "The file name to assign to the exported .zip file. Defaults to secrethub_export_<namespace>_<repo>_<timestamp>.zip with the timestamp formatted as YYYYMMDD_HHMMSS").StringVar(&cmd.zipName)
+	clause.Arg("zip-file-name", "The file name to assign to the exported .zip file. Defaults to secrethub_export_<namespace>_<repo>_<timestamp>.zip with the timestamp formatted as YYYYMMDD_HHMMSS. When the export is encrypted, .gpg is appended to the default file name.").StringVar(&cmd.zipName)
+	clause.Flag("encrypt-to", "Encrypt the export to the OpenPGP public key in the given file, so no plaintext secrets are written to disk. Only OpenPGP public keys are supported, age and X25519 recipients are not.").PlaceHolder("KEY-FILE").StringVar(&cmd.encryptTo)
+	clause.Flag("passphrase", "Prompt for a passphrase to encrypt the export with, so no plaintext secrets are written to disk.").BoolVar(&cmd.passphrase)
 
 	command.BindAction(clause, cmd.Run)
 }
 
 // Run exports a repo to a zip file
 func (cmd *RepoExportCommand) Run() error {
+	if cmd.encryptTo != "" && cmd.passphrase {
+		return ErrFlagsConflict("--encrypt-to and --passphrase")
+	}
+	encrypted := cmd.encryptTo != "" || cmd.passphrase
+
+	var err error
+	var recipients openpgp.EntityList
+	if cmd.encryptTo != "" {
+		recipients, err = readPublicKeys(cmd.encryptTo, cmd.readFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	if cmd.zipName == "" {
 		// secrethub_export_repo_date_time.zip
-		cmd.zipName = fmt.Sprintf("%s_export_%s_%s.zip", ApplicationName, cmd.path.GetRepo(), time.Now().Format("20060102_150405"))
+		cmd.zipName = fmt.Sprintf("%s_export_%s_%s.zip", ApplicationName, cmd.path.GetRepo(), cmd.timeNow().Format("20060102_150405"))
+		if encrypted {
+			cmd.zipName += ".gpg"
+		}
 	}
 
-	_, err := os.Stat(cmd.zipName)
+	_, err = os.Stat(cmd.zipName)
 	if err == nil {
 		return ErrExportAlreadyExists
 	}
 
-	confirmed, err := ui.ConfirmCaseInsensitive(
-		cmd.io,
-		fmt.Sprintf(
-			"[DANGER ZONE] This will export all the secrets unencrypted in the %s repository. "+
-				"You are responsible for the protection of these secrets. "+
-				"Please type in the full path of the repository to confirm",
-			cmd.path.String(),
-		),
+	question := fmt.Sprintf(
+		"[DANGER ZONE] This will export all the secrets unencrypted in the %s repository. "+
+			"You are responsible for the protection of these secrets. "+
+			"Please type in the full path of the repository to confirm",
 		cmd.path.String(),
 	)
+	if encrypted {
+		question = fmt.Sprintf(
+			"This will export all the secrets in the %s repository to an encrypted file. "+
+				"You are responsible for the protection of the key to decrypt it. "+
+				"Please type in the full path of the repository to confirm",
+			cmd.path.String(),
+		)
+	}
+
+	confirmed, err := ui.ConfirmCaseInsensitive(cmd.io, question, cmd.path.String())
 	if err != nil {
 		return err
 	}
@@ -75,6 +112,17 @@ func (cmd *RepoExportCommand) Run() error {
 		return nil
 	}
 
+	var passphrase string
+	if cmd.passphrase {
+		passphrase, err = ui.AskPassphrase(cmd.io, "Please enter a passphrase to encrypt the export with: ", "Enter the same passphrase again: ", 3)
+		if err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return ErrEmptyExportPassphrase
+		}
+	}
+
 	client, err := cmd.newClient()
 	if err != nil {
 		return err
@@ -85,23 +133,46 @@ func (cmd *RepoExportCommand) Run() error {
 		return err
 	}
 
-	zipFile, err := os.Create(cmd.zipName)
+	zipFile, err := os.OpenFile(cmd.zipName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
 	if err != nil {
 		return err
 	}
 
-	writer := zip.NewWriter(zipFile)
-	defer func() {
-		err := writer.Close()
-		if err != nil {
-			panic(fmt.Errorf("could not close zip file: %s", err))
-		}
+	err = cmd.writeExport(zipFile, client, rootDir, recipients, passphrase)
+	closeErr := zipFile.Close()
+	if err == nil && closeErr != nil {
+		err = fmt.Errorf("could not close zip file: %s", closeErr)
+	}
+	if err != nil {
+		// Do not leave a partial or unencrypted export behind.
+		_ = os.Remove(cmd.zipName)
+		return err
+	}
+
+	return nil
+}
 
-		err = zipFile.Close()
+// writeExport writes all secret versions in the tree to w as a zip archive,
+// encrypted when recipients or a passphrase are given.
+func (cmd *RepoExportCommand) writeExport(w io.Writer, client secrethub.ClientInterface, rootDir *api.Tree, recipients openpgp.EntityList, passphrase string) error {
+	var err error
+	encrypted := len(recipients) > 0 || passphrase != ""
+
+	var out io.Writer = w
+	var encryptWriter io.WriteCloser
+	if encrypted {
+		encryptWriter, err = encryptExport(w, recipients, []byte(passphrase))
 		if err != nil {
-			panic(fmt.Errorf("could not close zip file: %s", err))
+			return err
 		}
-	}()
+		out = encryptWriter
+	}
+
+	writer := zip.NewWriter(out)
+	manifest := exportManifest{
+		Repo:      cmd.path.String(),
+		CreatedAt: cmd.timeNow().UTC(),
+	}
 
 	for _, secret := range rootDir.Secrets {
 		secretPath, err := rootDir.AbsSecretPath(secret.SecretID)
@@ -130,10 +201,30 @@ func (cmd *RepoExportCommand) Run() error {
 				return err
 			}
 
-			_, err = zipNode.Write(posix.AddNewLine(version.Data))
+			data := posix.AddNewLine(version.Data)
+			_, err = zipNode.Write(data)
 			if err != nil {
 				return err
 			}
+
+			manifest.add(zipSecretPath, version.Version, version.CreatedAt, data, len(data) != len(version.Data))
+		}
+	}
+
+	err = manifest.write(writer)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("could not close zip file: %s", err)
+	}
+
+	if encrypted {
+		err = encryptWriter.Close()
+		if err != nil {
+			return fmt.Errorf("could not encrypt zip file: %s", err)
 		}
 	}
 
@@ -0,0 +1,79 @@
+package secrethub
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/api/uuid"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestRepoExportCommand_Run_RemovesPartialExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-export")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	dirID := uuid.New()
+	secretID := uuid.New()
+	listErr := errors.New("connection reset")
+
+	io := ui.NewFakeIO()
+	io.PromptIn.Buffer.WriteString("namespace/repo\n")
+
+	cmd := RepoExportCommand{
+		path:    "namespace/repo",
+		zipName: filepath.Join(dir, "export.zip"),
+		io:      io,
+		timeNow: time.Now,
+		newClient: func() (secrethub.ClientInterface, error) {
+			return fakeclient.Client{
+				DirService: &fakeclient.DirService{
+					TreeGetter: fakeclient.TreeGetter{
+						ReturnsTree: &api.Tree{
+							ParentPath: "namespace",
+							RootDir: &api.Dir{
+								Name:  "repo",
+								DirID: dirID,
+							},
+							Dirs: map[uuid.UUID]*api.Dir{
+								dirID: {
+									Name:  "repo",
+									DirID: dirID,
+								},
+							},
+							Secrets: map[uuid.UUID]*api.Secret{
+								secretID: {
+									Name:     "api_key",
+									SecretID: secretID,
+									DirID:    dirID,
+								},
+							},
+						},
+					},
+				},
+				SecretService: &fakeclient.SecretService{
+					VersionService: &fakeclient.SecretVersionService{
+						WithDataLister: fakeclient.WithDataLister{
+							Err: listErr,
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	err = cmd.Run()
+	assert.Equal(t, err, listErr)
+
+	_, err = os.Stat(cmd.zipName)
+	assert.Equal(t, os.IsNotExist(err), true)
+}
@@ -0,0 +1,244 @@
+package secrethub
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+)
+
+// Errors
+var (
+	ErrInvalidExportEntry = errMain.Code("invalid_export_entry").ErrorPref("the export file contains an invalid entry %s: expected <dir>/<secret>/<version>")
+	ErrImportConflicts    = errMain.Code("import_conflicts").ErrorPref("%d secret(s) already exist in the repository. Use --skip-existing to leave them untouched")
+)
+
+// RepoImportCommand imports the secrets of a zip file created by the export command into a repo.
+type RepoImportCommand struct {
+	path         api.RepoPath
+	zipFile      string
+	identity     string
+	dryRun       bool
+	skipExisting bool
+	io           ui.IO
+	newClient    newClientFunc
+	readFile     func(filename string) ([]byte, error)
+}
+
+// NewRepoImportCommand creates a new RepoImportCommand.
+func NewRepoImportCommand(io ui.IO, newClient newClientFunc) *RepoImportCommand {
+	return &RepoImportCommand{
+		io:        io,
+		newClient: newClient,
+		readFile:  ioutil.ReadFile,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *RepoImportCommand) Register(r command.Registerer) {
+	clause := r.Command("import", "Import the secrets of a zip file created with the export command into a repository.")
+	clause.Arg("zip-file", "The .zip file to import. An encrypted export is decrypted in memory.").Required().StringVar(&cmd.zipFile)
+	clause.Arg("repo-path", "The repository to import the secrets into").Required().PlaceHolder(repoPathPlaceHolder).SetValue(&cmd.path)
+	clause.Flag("identity", "The file containing the OpenPGP private key an encrypted export was encrypted to. Without this flag, you are prompted for the passphrase the export was encrypted with.").PlaceHolder("KEY-FILE").StringVar(&cmd.identity)
+	clause.Flag("dry-run", "Only print which directories and secrets would be created, without changing anything.").BoolVar(&cmd.dryRun)
+	clause.Flag("skip-existing", "Do not import secrets that already exist in the repository instead of aborting the import.").BoolVar(&cmd.skipExisting)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run imports the secrets in the zip file into the repo.
+func (cmd *RepoImportCommand) Run() error {
+	raw, err := readExportFile(cmd.zipFile, cmd.identity, cmd.io, cmd.readFile)
+	if err != nil {
+		return err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return ErrReadFile(cmd.zipFile, err)
+	}
+
+	secrets, err := readExportArchive(archive)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	var toImport []exportedSecret
+	for _, secret := range secrets {
+		secretPath := cmd.secretPath(secret)
+		exists, err := client.Secrets().Exists(secretPath)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			conflicts = append(conflicts, secretPath)
+			continue
+		}
+		toImport = append(toImport, secret)
+	}
+
+	for _, conflict := range conflicts {
+		if cmd.skipExisting {
+			fmt.Fprintf(cmd.io.Stdout(), "Skipping %s: the secret already exists\n", conflict)
+		} else {
+			fmt.Fprintf(cmd.io.Stdout(), "Conflict: %s already exists\n", conflict)
+		}
+	}
+	if len(conflicts) > 0 && !cmd.skipExisting {
+		return ErrImportConflicts(len(conflicts))
+	}
+
+	for _, dir := range exportedDirs(toImport) {
+		dirPath := api.JoinPaths(cmd.path.Value(), dir)
+		if cmd.dryRun {
+			fmt.Fprintf(cmd.io.Stdout(), "Would create directory %s\n", dirPath)
+			continue
+		}
+
+		err = client.Dirs().CreateAll(dirPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, secret := range toImport {
+		secretPath := cmd.secretPath(secret)
+		if cmd.dryRun {
+			fmt.Fprintf(cmd.io.Stdout(), "Would write %d version(s) of %s\n", len(secret.versions), secretPath)
+			continue
+		}
+
+		for _, version := range secret.versions {
+			_, err = client.Secrets().Write(secretPath, version.data)
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(cmd.io.Stdout(), "Imported %d version(s) of %s\n", len(secret.versions), secretPath)
+	}
+
+	if !cmd.dryRun {
+		fmt.Fprintf(cmd.io.Stdout(), "Import complete! Imported %d secret(s) into %s\n", len(toImport), cmd.path)
+	}
+
+	return nil
+}
+
+// secretPath returns the full path of an exported secret in the repo to import into.
+func (cmd *RepoImportCommand) secretPath(secret exportedSecret) string {
+	return api.JoinPaths(cmd.path.Value(), secret.name)
+}
+
+// exportedSecret is a secret read from an export archive.
+type exportedSecret struct {
+	// name is the path of the secret relative to the repository.
+	name     string
+	versions []exportedVersion
+}
+
+type exportedVersion struct {
+	version int
+	data    []byte
+}
+
+// readExportArchive reads the secrets from an archive created by the export command,
+// which stores every secret version as <dir>/<secret>/<version>.
+// The secrets are sorted by path and their versions in ascending order.
+func readExportArchive(archive *zip.Reader) ([]exportedSecret, error) {
+	manifest, err := readExportManifest(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	newlineAdded := map[string]bool{}
+	if manifest != nil {
+		err = verifyExportManifest(archive, manifest)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range manifest.Secrets {
+			newlineAdded[entry.Path] = entry.NewlineAdded
+		}
+	}
+
+	secrets := map[string]*exportedSecret{}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || file.Name == exportManifestName {
+			continue
+		}
+
+		name, versionNumber := path.Split(file.Name)
+		name = strings.TrimSuffix(name, "/")
+		version, err := strconv.Atoi(versionNumber)
+		if err != nil || name == "" || version < 1 {
+			return nil, ErrInvalidExportEntry(file.Name)
+		}
+
+		data, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		// Remove the newline that was added on export. Archives without a manifest
+		// do not record whether a newline was added, so it is always removed.
+		if manifest == nil || newlineAdded[file.Name] {
+			data = bytes.TrimSuffix(data, []byte("\n"))
+		}
+
+		secret, ok := secrets[name]
+		if !ok {
+			secret = &exportedSecret{name: name}
+			secrets[name] = secret
+		}
+		secret.versions = append(secret.versions, exportedVersion{
+			version: version,
+			data:    data,
+		})
+	}
+
+	res := make([]exportedSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		sort.Slice(secret.versions, func(i, j int) bool {
+			return secret.versions[i].version < secret.versions[j].version
+		})
+		res = append(res, *secret)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].name < res[j].name
+	})
+
+	return res, nil
+}
+
+// exportedDirs returns the directories that contain the given secrets,
+// relative to the repository and sorted so parents come before their children.
+func exportedDirs(secrets []exportedSecret) []string {
+	seen := map[string]bool{}
+	var dirs []string
+	for _, secret := range secrets {
+		dir := path.Dir(secret.name)
+		if dir == "." || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
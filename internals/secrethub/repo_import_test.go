@@ -0,0 +1,237 @@
+package secrethub
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+type importSecretService struct {
+	existing map[string]bool
+	written  []string
+	secrethub.SecretService
+}
+
+func (s *importSecretService) Exists(path string) (bool, error) {
+	return s.existing[path], nil
+}
+
+func (s *importSecretService) Write(path string, data []byte) (*api.SecretVersion, error) {
+	s.written = append(s.written, path+"="+string(data))
+	return &api.SecretVersion{}, nil
+}
+
+type importDirService struct {
+	created []string
+	secrethub.DirService
+}
+
+func (s *importDirService) CreateAll(path string) error {
+	s.created = append(s.created, path)
+	return nil
+}
+
+func writeTestExport(t *testing.T, filename string, files map[string]string) {
+	f, err := os.Create(filename)
+	assert.OK(t, err)
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		node, err := w.Create(name)
+		assert.OK(t, err)
+		_, err = node.Write([]byte(content))
+		assert.OK(t, err)
+	}
+	assert.OK(t, w.Close())
+	assert.OK(t, f.Close())
+}
+
+func TestRepoImportCommand_Run(t *testing.T) {
+	export := map[string]string{
+		"db/password/2": "bar\n",
+		"db/password/1": "foo\n",
+		"api_key/1":     "key\n",
+	}
+
+	cases := map[string]struct {
+		files        map[string]string
+		existing     map[string]bool
+		dryRun       bool
+		skipExisting bool
+		created      []string
+		written      []string
+		out          string
+		err          error
+	}{
+		"success": {
+			files:   export,
+			created: []string{"namespace/repo/db"},
+			written: []string{
+				"namespace/repo/api_key=key",
+				"namespace/repo/db/password=foo",
+				"namespace/repo/db/password=bar",
+			},
+			out: "Imported 1 version(s) of namespace/repo/api_key\n" +
+				"Imported 2 version(s) of namespace/repo/db/password\n" +
+				"Import complete! Imported 2 secret(s) into namespace/repo\n",
+		},
+		"dry run": {
+			files:  export,
+			dryRun: true,
+			out: "Would create directory namespace/repo/db\n" +
+				"Would write 1 version(s) of namespace/repo/api_key\n" +
+				"Would write 2 version(s) of namespace/repo/db/password\n",
+		},
+		"conflict": {
+			files:    export,
+			existing: map[string]bool{"namespace/repo/api_key": true},
+			out:      "Conflict: namespace/repo/api_key already exists\n",
+			err:      ErrImportConflicts(1),
+		},
+		"skip existing": {
+			files:        export,
+			existing:     map[string]bool{"namespace/repo/api_key": true},
+			skipExisting: true,
+			created:      []string{"namespace/repo/db"},
+			written: []string{
+				"namespace/repo/db/password=foo",
+				"namespace/repo/db/password=bar",
+			},
+			out: "Skipping namespace/repo/api_key: the secret already exists\n" +
+				"Imported 2 version(s) of namespace/repo/db/password\n" +
+				"Import complete! Imported 1 secret(s) into namespace/repo\n",
+		},
+		"manifest records added newlines": {
+			files: map[string]string{
+				"api_key/1":     "key\n",
+				"certificate/1": "-----BEGIN CERTIFICATE-----\n",
+				exportManifestName: `{"secrets":[` +
+					`{"path":"api_key/1","sha256":"a7998f247bd965694ff227fa325c81169a07471a8b6808d3e002a486c4e65975","newline_added":true},` +
+					`{"path":"certificate/1","sha256":"b93f51c3ac1bdd90edcce2019d2452a328cbf97443f3744cc7ec63033a5b2c16"}]}`,
+			},
+			written: []string{
+				"namespace/repo/api_key=key",
+				"namespace/repo/certificate=-----BEGIN CERTIFICATE-----\n",
+			},
+			out: "Imported 1 version(s) of namespace/repo/api_key\n" +
+				"Imported 1 version(s) of namespace/repo/certificate\n" +
+				"Import complete! Imported 2 secret(s) into namespace/repo\n",
+		},
+		"digest mismatch": {
+			files: map[string]string{
+				"api_key/1":        "tampered\n",
+				exportManifestName: `{"secrets":[{"path":"api_key/1","sha256":"a7998f247bd965694ff227fa325c81169a07471a8b6808d3e002a486c4e65975","newline_added":true}]}`,
+			},
+			err: ErrExportDigestMismatch("api_key/1"),
+		},
+		"digest mismatch dry run": {
+			files: map[string]string{
+				"api_key/1":        "tampered\n",
+				exportManifestName: `{"secrets":[{"path":"api_key/1","sha256":"a7998f247bd965694ff227fa325c81169a07471a8b6808d3e002a486c4e65975","newline_added":true}]}`,
+			},
+			dryRun: true,
+			err:    ErrExportDigestMismatch("api_key/1"),
+		},
+		"invalid entry": {
+			files: map[string]string{"db/password/latest": "foo"},
+			err:   ErrInvalidExportEntry("db/password/latest"),
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "secrethub-import")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			zipFile := filepath.Join(dir, name+".zip")
+			writeTestExport(t, zipFile, tc.files)
+
+			secretService := &importSecretService{existing: tc.existing}
+			dirService := &importDirService{}
+			io := ui.NewFakeIO()
+
+			cmd := RepoImportCommand{
+				path:         "namespace/repo",
+				zipFile:      zipFile,
+				dryRun:       tc.dryRun,
+				skipExisting: tc.skipExisting,
+				io:           io,
+				newClient: func() (secrethub.ClientInterface, error) {
+					return importClient{
+						Client:        fakeclient.Client{},
+						secretService: secretService,
+						dirService:    dirService,
+					}, nil
+				},
+				readFile: ioutil.ReadFile,
+			}
+
+			err := cmd.Run()
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.StdOut.String(), tc.out)
+			assert.Equal(t, dirService.created, tc.created)
+			assert.Equal(t, secretService.written, tc.written)
+		})
+	}
+}
+
+func TestRepoImportCommand_Run_Encrypted(t *testing.T) {
+	var encrypted bytes.Buffer
+	w, err := encryptExport(&encrypted, nil, []byte("correct horse"))
+	assert.OK(t, err)
+	_, err = w.Write(newTestExportArchive(t, map[string]string{"api_key/1": "key"}, map[string]string{"api_key/1": "key"}))
+	assert.OK(t, err)
+	assert.OK(t, w.Close())
+
+	secretService := &importSecretService{}
+	io := ui.NewFakeIO()
+	io.PromptIn.Buffer = bytes.NewBufferString("correct horse")
+	cmd := RepoImportCommand{
+		path:    "namespace/repo",
+		zipFile: "export.zip.gpg",
+		io:      io,
+		newClient: func() (secrethub.ClientInterface, error) {
+			return importClient{
+				Client:        fakeclient.Client{},
+				secretService: secretService,
+				dirService:    &importDirService{},
+			}, nil
+		},
+		readFile: func(filename string) ([]byte, error) {
+			if filename == "export.zip.gpg" {
+				return encrypted.Bytes(), nil
+			}
+			return nil, os.ErrNotExist
+		},
+	}
+
+	err = cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, secretService.written, []string{"namespace/repo/api_key=key"})
+}
+
+type importClient struct {
+	fakeclient.Client
+	secretService secrethub.SecretService
+	dirService    secrethub.DirService
+}
+
+func (c importClient) Secrets() secrethub.SecretService {
+	return c.secretService
+}
+
+func (c importClient) Dirs() secrethub.DirService {
+	return c.dirService
+}
@@ -1,9 +1,6 @@
 package secrethub
 
 import (
-	"fmt"
-
-	"github.com/secrethub/secrethub-cli/internals/cli"
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
 
@@ -16,14 +13,16 @@ type RepoInspectCommand struct {
 	timeFormatter TimeFormatter
 	io            ui.IO
 	newClient     newClientFunc
+	output        *OutputConfig
 }
 
 // NewRepoInspectCommand creates a new RepoInspectCommand.
-func NewRepoInspectCommand(io ui.IO, newClient newClientFunc) *RepoInspectCommand {
+func NewRepoInspectCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *RepoInspectCommand {
 	return &RepoInspectCommand{
 		io:            io,
 		newClient:     newClient,
 		timeFormatter: NewTimeFormatter(true),
+		output:        output,
 	}
 }
 
@@ -57,14 +56,7 @@ func (cmd *RepoInspectCommand) Run() error {
 		return err
 	}
 
-	output, err := cli.PrettyJSON(newInspectRepoOutput(repo, users, services, cmd.timeFormatter))
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintln(cmd.io.Stdout(), output)
-
-	return nil
+	return cmd.output.print(cmd.io.Stdout(), newInspectRepoOutput(repo, users, services, cmd.timeFormatter))
 }
 
 func newInspectRepoOutput(repo *api.Repo, users []*api.User, services []*api.Service, timeFormatter TimeFormatter) inspectRepoOutput {
@@ -92,14 +84,14 @@ func newInspectRepoOutput(repo *api.Repo, users []*api.User, services []*api.Ser
 
 // inspectRepoOutput is the json format to print out with all the details of a repo.
 type inspectRepoOutput struct {
-	Name         string
-	Owner        string
-	CreatedAt    string
-	SecretCount  int
-	MemberCount  int
-	Users        []inspectRepoUserOutput
-	ServiceCount int
-	Services     []inspectRepoServiceOutput
+	Name         string                     `json:"Name" yaml:"name"`
+	Owner        string                     `json:"Owner" yaml:"owner"`
+	CreatedAt    string                     `json:"CreatedAt" yaml:"created_at"`
+	SecretCount  int                        `json:"SecretCount" yaml:"secret_count"`
+	MemberCount  int                        `json:"MemberCount" yaml:"member_count"`
+	Users        []inspectRepoUserOutput    `json:"Users" yaml:"users"`
+	ServiceCount int                        `json:"ServiceCount" yaml:"service_count"`
+	Services     []inspectRepoServiceOutput `json:"Services" yaml:"services"`
 }
 
 func newInspectRepoUser(user *api.User) inspectRepoUserOutput {
@@ -111,8 +103,8 @@ func newInspectRepoUser(user *api.User) inspectRepoUserOutput {
 
 // inspectRepoUserOutput is the json format to print out with all the details of repo users.
 type inspectRepoUserOutput struct {
-	User     string
-	UserName string
+	User     string `json:"User" yaml:"user"`
+	UserName string `json:"UserName" yaml:"user_name"`
 }
 
 func newInspectRepoService(service *api.Service) inspectRepoServiceOutput {
@@ -124,6 +116,6 @@ func newInspectRepoService(service *api.Service) inspectRepoServiceOutput {
 
 // inspectRepoServiceOutput is the json format to print out with all the details of repo services.
 type inspectRepoServiceOutput struct {
-	Service            string
-	ServiceDescription string
+	Service            string `json:"Service" yaml:"service"`
+	ServiceDescription string `json:"ServiceDescription" yaml:"service_description"`
 }
@@ -3,7 +3,7 @@ package secrethub
 import (
 	"fmt"
 	"sort"
-	"text/tabwriter"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
@@ -19,13 +19,15 @@ type RepoLSCommand struct {
 	io            ui.IO
 	timeFormatter TimeFormatter
 	newClient     newClientFunc
+	output        *OutputConfig
 }
 
 // NewRepoLSCommand creates a new RepoLSCommand.
-func NewRepoLSCommand(io ui.IO, newClient newClientFunc) *RepoLSCommand {
+func NewRepoLSCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *RepoLSCommand {
 	return &RepoLSCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -77,17 +79,26 @@ func (cmd *RepoLSCommand) run() error {
 		for _, repo := range list {
 			fmt.Fprintf(cmd.io.Stdout(), "%s\n", repo.Path())
 		}
-	} else {
-		w := tabwriter.NewWriter(cmd.io.Stdout(), 0, 2, 2, ' ', 0)
-		fmt.Fprintf(w, "%s\t%s\t%s\n", "NAME", "STATUS", "CREATED")
-		for _, repo := range list {
-			fmt.Fprintf(w, "%s\t%s\t%s\n", repo.Path(), repo.Status, cmd.timeFormatter.Format(repo.CreatedAt.Local()))
-		}
-		err = w.Flush()
-		if err != nil {
-			return err
-		}
+		return nil
+	}
+
+	table := newOutputTable(2, "NAME", "STATUS", "CREATED")
+	for _, repo := range list {
+		table.add(
+			repoOutput{
+				Path:      repo.Path().String(),
+				Status:    repo.Status,
+				CreatedAt: repo.CreatedAt,
+			},
+			repo.Path().String(), repo.Status, cmd.timeFormatter.Format(repo.CreatedAt.Local()),
+		)
 	}
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
 
-	return nil
+// repoOutput is the structured output format of a listed repository.
+type repoOutput struct {
+	Path      string    `json:"path" yaml:"path"`
+	Status    string    `json:"status" yaml:"status"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 }
@@ -2,8 +2,6 @@ package secrethub
 
 import (
 	"fmt"
-	"io"
-	"text/tabwriter"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
@@ -11,6 +9,11 @@ import (
 	"github.com/secrethub/secrethub-go/internals/api"
 )
 
+// Errors
+var (
+	ErrRevokeFailed = errMain.Code("revoke_failed").ErrorPref("revoke failed: the account %s is the only admin on the repo %s. You need to make sure another account has admin rights on the repository or you can remove the repo")
+)
+
 // RepoRevokeCommand handles revoking an account access to a repository.
 type RepoRevokeCommand struct {
 	accountName api.AccountName
@@ -18,13 +21,15 @@ type RepoRevokeCommand struct {
 	force       bool
 	io          ui.IO
 	newClient   newClientFunc
+	output      *OutputConfig
 }
 
 // NewRepoRevokeCommand creates a new RepoRevokeCommand.
-func NewRepoRevokeCommand(io ui.IO, newClient newClientFunc) *RepoRevokeCommand {
+func NewRepoRevokeCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *RepoRevokeCommand {
 	return &RepoRevokeCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -75,7 +80,11 @@ func (cmd *RepoRevokeCommand) Run() error {
 		}
 	}
 
-	fmt.Fprint(cmd.io.Stdout(), "Revoking account...\n\n")
+	// In the other formats, only the flagged secrets are printed.
+	printMessages := cmd.output.Format() == outputFormatTable
+	if printMessages {
+		fmt.Fprint(cmd.io.Stdout(), "Revoking account...\n\n")
+	}
 
 	var revoked *api.RevokeRepoResponse
 	if cmd.accountName.IsService() {
@@ -88,12 +97,7 @@ func (cmd *RepoRevokeCommand) Run() error {
 	}
 
 	if revoked.Status == api.StatusFailed {
-		fmt.Fprintf(cmd.io.Stdout(),
-			"\nRevoke failed! The account %s is the only admin on the repo %s."+
-				"You need to make sure another account has admin rights on the repository or you can remove the repo.",
-			prettyName,
-			cmd.path,
-		)
+		return ErrRevokeFailed(prettyName, cmd.path)
 	}
 
 	rootDir, err := client.Dirs().GetTree(cmd.path.GetDirPath().Value(), -1, false)
@@ -101,15 +105,18 @@ func (cmd *RepoRevokeCommand) Run() error {
 		return err
 	}
 
-	w := tabwriter.NewWriter(cmd.io.Stdout(), 0, 2, 2, ' ', 0)
+	table := newOutputTable(2)
+	countUnaffected, countFlagged := addFlaggedSecrets(table, rootDir.RootDir, cmd.path.GetNamespace())
 
-	countUnaffected, countFlagged := printFlaggedSecrets(w, rootDir.RootDir, cmd.path.GetNamespace())
-
-	err = w.Flush()
+	err = cmd.output.printTable(cmd.io.Stdout(), table)
 	if err != nil {
 		return err
 	}
 
+	if !printMessages {
+		return nil
+	}
+
 	if countFlagged > 0 {
 		fmt.Fprintln(cmd.io.Stdout())
 	}
@@ -126,7 +133,15 @@ func (cmd *RepoRevokeCommand) Run() error {
 	return nil
 }
 
-func printFlaggedSecrets(w io.Writer, dir *api.Dir, prePath string) (int, int) {
+// flaggedSecretOutput is the structured output format of a secret that is flagged for rotation.
+type flaggedSecretOutput struct {
+	Path   string `json:"path" yaml:"path"`
+	Status string `json:"status" yaml:"status"`
+}
+
+// addFlaggedSecrets adds the flagged secrets in the directory and its subdirectories to the table
+// and returns the number of unaffected and flagged secrets.
+func addFlaggedSecrets(table *outputTable, dir *api.Dir, prePath string) (int, int) {
 	var countUnaffected, countFlagged int
 	if prePath != "" {
 		prePath = fmt.Sprintf("%s/%s", prePath, dir.Name)
@@ -136,7 +151,7 @@ func printFlaggedSecrets(w io.Writer, dir *api.Dir, prePath string) (int, int) {
 
 	// Print the directories below
 	for _, subDir := range dir.SubDirs {
-		subUnaffected, subFlagged := printFlaggedSecrets(w, subDir, prePath)
+		subUnaffected, subFlagged := addFlaggedSecrets(table, subDir, prePath)
 		countUnaffected += subUnaffected
 		countFlagged += subFlagged
 	}
@@ -145,7 +160,8 @@ func printFlaggedSecrets(w io.Writer, dir *api.Dir, prePath string) (int, int) {
 	for _, secret := range dir.Secrets {
 		if secret.Status != api.StatusOK {
 			countFlagged++
-			fmt.Fprintf(w, "%s/%s\t=> %s\n", prePath, secret.Name, secret.Status)
+			secretPath := prePath + "/" + secret.Name
+			table.add(flaggedSecretOutput{Path: secretPath, Status: secret.Status}, secretPath, "=> "+secret.Status)
 		} else {
 			countUnaffected++
 		}
@@ -309,14 +309,45 @@ func TestRepoRevokeCommand_Run(t *testing.T) {
 					},
 				},
 			},
-			// TODO SHDEV-1079: Fix this bug. When the revoke fails, the command should not print to stdout that
-			// the dev "can no longer access the ... repository".
-			out: "Revoking account...\n\n\n" +
-				"Revoke failed! The account dev1 (Developer Uno) is the only admin on the repo namespace/repo." +
-				"You need to make sure another account has admin rights on the repository or you can remove the repo." +
-				"Revoke complete! The account dev1 (Developer Uno) can no longer access the namespace/repo repository. " +
-				"Make sure you overwrite or delete all flagged secrets. " +
-				"Secrets: 0 unaffected, 0 flagged\n",
+			out: "Revoking account...\n\n",
+			err: ErrRevokeFailed("dev1 (Developer Uno)", "namespace/repo"),
+		},
+		"revoke user failed json": {
+			cmd: RepoRevokeCommand{
+				accountName: api.AccountName("dev1"),
+				path:        "namespace/repo",
+				force:       true,
+				output:      &OutputConfig{format: outputFormatJSON},
+			},
+			userService: fakeclient.UserService{
+				Getter: fakeclient.UserGetter{
+					ReturnsUser: &api.User{
+						AccountID: testUUID,
+						Username:  "dev1",
+						FullName:  "Developer Uno",
+					},
+				},
+			},
+			dirService: fakeclient.DirService{
+				TreeGetter: fakeclient.TreeGetter{
+					ReturnsTree: &api.Tree{
+						RootDir: &api.Dir{
+							Name: "repo",
+						},
+					},
+				},
+			},
+			repoService: fakeclient.RepoService{
+				UserService: &fakeclient.RepoUserService{
+					Revoker: fakeclient.RepoRevoker{
+						ReturnsRevokeResponse: &api.RevokeRepoResponse{
+							Status: api.StatusFailed,
+						},
+					},
+				},
+			},
+			out: "",
+			err: ErrRevokeFailed("dev1 (Developer Uno)", "namespace/repo"),
 		},
 		// TODO SHDEV-1029: Add cases for confirm and abort after extracting AskForConfirmation out of ui.IO.
 	}
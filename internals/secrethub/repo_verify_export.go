@@ -0,0 +1,77 @@
+package secrethub
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// RepoVerifyExportCommand verifies and optionally decrypts a file created by the export command.
+type RepoVerifyExportCommand struct {
+	exportFile string
+	outFile    string
+	identity   string
+	force      bool
+	io         ui.IO
+	readFile   func(filename string) ([]byte, error)
+}
+
+// NewRepoVerifyExportCommand creates a new RepoVerifyExportCommand.
+func NewRepoVerifyExportCommand(io ui.IO) *RepoVerifyExportCommand {
+	return &RepoVerifyExportCommand{
+		io:       io,
+		readFile: ioutil.ReadFile,
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *RepoVerifyExportCommand) Register(r command.Registerer) {
+	clause := r.Command("verify-export", "Decrypt an export file and verify its contents against its manifest.")
+	clause.Arg("export-file", "The file created with the export command").Required().StringVar(&cmd.exportFile)
+	clause.Flag("identity", "The file containing the OpenPGP private key the export was encrypted to. When the key is protected with a passphrase, you are prompted for it. Without this flag, you are prompted for the passphrase the export was encrypted with.").PlaceHolder("KEY-FILE").StringVar(&cmd.identity)
+	clause.Flag("out-file", "Write the decrypted and verified zip file to the given file. Warning: this writes all secrets unencrypted to disk.").Short('o').StringVar(&cmd.outFile)
+	registerForceFlag(clause).BoolVar(&cmd.force)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run decrypts the export file and verifies the digests in its manifest.
+func (cmd *RepoVerifyExportCommand) Run() error {
+	raw, err := readExportFile(cmd.exportFile, cmd.identity, cmd.io, cmd.readFile)
+	if err != nil {
+		return err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return ErrReadFile(cmd.exportFile, err)
+	}
+
+	manifest, err := verifyExportArchive(archive)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.io.Stdout(), "Verified %d secret version(s) of %s exported at %s\n", len(manifest.Secrets), manifest.Repo, manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+
+	if cmd.outFile != "" {
+		_, err := os.Stat(cmd.outFile)
+		if err == nil && !cmd.force {
+			return ErrFileAlreadyExists
+		}
+
+		err = ioutil.WriteFile(cmd.outFile, raw, 0600)
+		if err != nil {
+			return ErrCannotWrite(cmd.outFile, err)
+		}
+
+		fmt.Fprintf(cmd.io.Stdout(), "Decrypted export written to %s\n", cmd.outFile)
+	}
+
+	return nil
+}
@@ -2,9 +2,11 @@ package secrethub
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -13,9 +15,12 @@ import (
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 
+	"github.com/secrethub/secrethub-cli/internals/cli/filemode"
 	"github.com/secrethub/secrethub-cli/internals/cli/masker"
+	"github.com/secrethub/secrethub-cli/internals/cli/pty"
 	"github.com/secrethub/secrethub-cli/internals/cli/validation"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+	"github.com/secrethub/secrethub-go/internals/api"
 	"github.com/secrethub/secrethub-go/internals/errio"
 )
 
@@ -31,6 +36,20 @@ var (
 	ErrParsingTemplate        = errRun.Code("template_parsing_failed").ErrorPref("error while processing template file '%s': %s")
 	ErrInvalidTemplateVar     = errRun.Code("invalid_template_var").ErrorPref("template variable '%s' is invalid: template variables may only contain uppercase letters, digits, and the '_' (underscore) and are not allowed to start with a number")
 	ErrSecretsNotAllowedInKey = errRun.Code("secret_in_key").Error("secrets are not allowed in run template keys")
+	ErrUnknownSignal          = errRun.Code("unknown_signal").ErrorPref("unknown signal: %s")
+	ErrInvalidRestartInterval = errRun.Code("invalid_restart_interval").Error("the --restart-check-interval must be positive")
+	ErrInvalidSecretFile      = errRun.Code("invalid_secret_file").ErrorPref("invalid --secret-file %s: the name must be a POSIX environment variable name")
+	ErrCannotWriteSecretFile  = errRun.Code("secret_file_write_error").ErrorPref("could not write the secret file for %s: %s")
+	ErrCannotWriteCredentials = errRun.Code("credentials_write_error").ErrorPref("could not write the --load-credential files: %s")
+	ErrInvalidInject          = errRun.Code("invalid_inject").ErrorPref("invalid --inject %s: the value should be of the form <template>:<out-file>")
+	ErrInjectFileExists       = errRun.Code("inject_file_exists").ErrorPref("the --inject out file %s already exists: it is removed when the command exits, so remove it first")
+	ErrInvalidSignalMap       = errRun.Code("invalid_signal_map").ErrorPref("invalid --signal-map %s: the value should be of the form FROM:TO, e.g. TERM:QUIT")
+	ErrInitNotSupported       = errRun.Code("init_not_supported").Error("--init is not supported on this platform")
+	ErrSetChildSubreaper      = errRun.Code("set_child_subreaper_failed").ErrorPref("could not register as child subreaper for --init: %s")
+	ErrInvalidCredentialName  = errRun.Code("invalid_credential_name").ErrorPref("invalid --load-credential name %s: the name cannot be empty, . or .. and cannot contain a /")
+	ErrInvalidEnvGlob         = errRun.Code("invalid_env_glob").ErrorPref("invalid glob pattern %s for --%s: %s")
+	ErrOpenTerminal           = errRun.Code("open_terminal_failed").ErrorPref("could not run the command in a terminal: %s")
+	ErrInvalidEnvDir          = errRun.Code("invalid_env_dir").ErrorPref("cannot source environment variables from the --env-dir directories:%s")
 )
 
 const (
@@ -42,6 +61,8 @@ const (
 	// prefix of the values of environment variables that will be
 	// substituted with secrets
 	secretReferencePrefix = "secrethub://"
+	// envSourceSecretFile is the source of the variables with the paths of secret files.
+	envSourceSecretFile = "--secret-file"
 )
 
 // RunCommand runs a program and passes environment variables to it that are
@@ -49,22 +70,48 @@ const (
 // The yml files write to .secretsenv/<env-name> when running the set command.
 type RunCommand struct {
 	io                   ui.IO
+	stderr               io.Writer
 	osEnv                []string
 	command              []string
 	environment          *environment
 	noMasking            bool
 	maskingTimeout       time.Duration
+	maskEncodings        masker.Encodings
 	newClient            newClientFunc
 	ignoreMissingSecrets bool
+	restartOnChange      bool
+	restartSignal        string
+	restartGracePeriod   time.Duration
+	restartInterval      time.Duration
+	cacheConfig          *SecretCacheConfig
+	secretFiles          map[string]string
+	injects              []string
+	fileMode             filemode.FileMode
+	tty                  bool
+	terminal             *runTerminal
+	initMode             bool
+	signalMap            []string
+	killTimeout          time.Duration
+	init                 *initProcess
+	loadCredentials      map[string]string
+	systemdNotify        string
+	notifier             *systemdNotifier
+	envFilter            envFilter
+	dryRun               bool
+	output               *OutputConfig
 }
 
 // NewRunCommand creates a new RunCommand.
-func NewRunCommand(io ui.IO, newClient newClientFunc) *RunCommand {
+func NewRunCommand(io ui.IO, newClient newClientFunc, cacheConfig *SecretCacheConfig, output *OutputConfig) *RunCommand {
 	return &RunCommand{
+		cacheConfig: cacheConfig,
 		io:          io,
+		stderr:      os.Stderr,
 		osEnv:       os.Environ(),
-		environment: newEnvironment(io),
+		environment: newEnvironment(io, newClient),
 		newClient:   newClient,
+		secretFiles: make(map[string]string),
+		output:      output,
 	}
 }
 
@@ -72,6 +119,7 @@ func NewRunCommand(io ui.IO, newClient newClientFunc) *RunCommand {
 func (cmd *RunCommand) Register(r command.Registerer) {
 	const helpShort = "Pass secrets as environment variables to a process."
 	const helpLong = "To protect against secrets leaking via stdout and stderr, those output streams are monitored for secrets. Detected secrets are automatically masked by replacing them with \"" + maskString + "\". " +
+		"The lines of multi-line secrets are masked as well, and so are the encoded secrets in any of the --mask-encodings, such as base64. " +
 		"The output is buffered to detect secrets, but to avoid blocking the buffering is limited to a maximum duration as defined by the --masking-timeout flag. " +
 		"Therefore, you should regard the masking as a best effort attempt and should always prevent secrets ending up on stdout and stderr in the first place."
 
@@ -80,8 +128,34 @@ func (cmd *RunCommand) Register(r command.Registerer) {
 	clause.Alias("exec")
 	clause.Arg("command", "The command to execute").Required().StringsVar(&cmd.command)
 	clause.Flag("no-masking", "Disable masking of secrets on stdout and stderr").BoolVar(&cmd.noMasking)
+	clause.Flag("mask-encodings", "A comma-separated list of the encodings of which the encoded secrets are also masked, or none. Supported encodings are base64 (both standard and URL-safe), url, json and hex. Defaults to base64 and json. Set it to none to only mask the secrets themselves.").Default(masker.DefaultEncodings.String()).SetValue(&cmd.maskEncodings)
+	clause.Flag("tty", "Run the command in a pseudo-terminal, so that interactive programs keep working while the output is masked. "+
+		"The terminal is put in raw mode, so that all input, including Ctrl-C, is passed to the command. The output to stderr is written to stdout. Only supported on Linux and macOS.").BoolVar(&cmd.tty)
+	clause.Flag("init", "Behave as the init process of a container: reap orphaned processes, pass signals to the process group of the command and kill it when it does not exit within the --kill-timeout after it is asked to terminate. "+
+		"Enabled automatically when running as PID 1. When not running as PID 1, orphaned processes are only reaped on Linux, where the CLI registers itself as their child subreaper. Not supported on Windows.").BoolVar(&cmd.initMode)
+	clause.Flag("signal-map", "Pass a signal to the command as another signal with --init, with `FROM:TO`, e.g. TERM:QUIT. Can be repeated.").StringsVar(&cmd.signalMap)
+	clause.Flag("kill-timeout", "The time the command gets to exit with --init after receiving SIGTERM, SIGINT, SIGQUIT or SIGHUP, before it is killed. Set to 0 to never kill the command.").Default("10s").DurationVar(&cmd.killTimeout)
+	clause.Flag("systemd-notify", "How the notifications of the command are passed to systemd when it runs as a service of Type=notify: "+
+		"proxy passes them on from this process, passthrough lets the command send them directly, which requires NotifyAccess=all, and ready notifies systemd once the command has started, for commands that do not send notifications.").Default(systemdNotifyProxy).EnumVar(&cmd.systemdNotify, systemdNotifyProxy, systemdNotifyPassthrough, systemdNotifyReady)
+	clause.Flag("dry-run", "Print the variables the command would get with the source of their value, the sources it overrides and the versions of the secrets it contains, instead of running the command. Values are never printed. "+
+		"Variables that are only passed on from the OS environment are left out.").BoolVar(&cmd.dryRun)
 	clause.Flag("masking-timeout", "The maximum time output is buffered. Warning: lowering this value increases the chance of secrets not being masked.").Default("1s").DurationVar(&cmd.maskingTimeout)
 	clause.Flag("ignore-missing-secrets", "Do not return an error when a secret does not exist and use an empty value instead.").BoolVar(&cmd.ignoreMissingSecrets)
+	clause.Flag("restart-on-change", "Keep checking the secrets in the environment for new versions and restart the command with the new values when a secret changes.").BoolVar(&cmd.restartOnChange)
+	clause.Flag("restart-signal", "The signal sent to the command to stop it before it is restarted with --restart-on-change.").Default("SIGTERM").StringVar(&cmd.restartSignal)
+	clause.Flag("restart-grace-period", "The time the command gets to exit after receiving the --restart-signal, before it is killed.").Default("10s").DurationVar(&cmd.restartGracePeriod)
+	clause.Flag("restart-check-interval", "The interval at which to check for new secret versions when using --restart-on-change.").Default("1m").DurationVar(&cmd.restartInterval)
+	clause.Flag("secret-file", "Write the secret at a given path to a file that only exists while the command runs, and set the environment variable to the path of the file with `NAME=<path>`. "+
+		"On Linux, the file is an anonymous in-memory file (memfd) passed to the command. Otherwise, it is a file in a private directory in $XDG_RUNTIME_DIR, /dev/shm or the temporary directory. "+
+		"The file can only be read by the user and is removed when the command exits.").StringMapVar(&cmd.secretFiles)
+	clause.Flag("load-credential", "Write the secret at a given path to a credential file with `NAME=<path>`, the way systemd does for LoadCredential=. "+
+		"The files are written to a private directory that is removed when the command exits and $CREDENTIALS_DIRECTORY is set to its path. Credentials in the $CREDENTIALS_DIRECTORY of this process remain available.").StringMapVar(&cmd.loadCredentials)
+	clause.Flag("clean-env", "Start the command with an empty environment, instead of the environment of this process. Only the variables that are sourced by SecretHub and the variables that match a --pass-env glob are set.").BoolVar(&cmd.envFilter.clean)
+	clause.Flag("pass-env", "Pass the variables of this process matching the glob to the command, e.g. `LC_*`. Use it with --clean-env to only pass the given variables, or to pass SECRETHUB_ variables. Can be repeated.").StringsVar(&cmd.envFilter.pass)
+	clause.Flag("drop-env", "Do not pass the variables of this process matching the glob to the command, e.g. `AWS_*`. Takes precedence over --pass-env. Can be repeated.").StringsVar(&cmd.envFilter.drop)
+	clause.Flag("keep-secrethub-env", "Pass the SECRETHUB_ variables of this process to the command. By default they are removed, so that the command cannot use the credential of the CLI, e.g. in SECRETHUB_CREDENTIAL.").BoolVar(&cmd.envFilter.keepSecretHub)
+	clause.Flag("inject", "Inject secrets into a template and write it to a file that is removed when the command exits, with `<template>:<out-file>`. The template is rendered with the variables and --template-version of the environment. Can be repeated.").StringsVar(&cmd.injects)
+	clause.Flag("file-mode", "The file mode of the files written with --inject.").Default("0600").SetValue(&cmd.fileMode)
 	cmd.environment.register(clause)
 	command.BindAction(clause, cmd.Run)
 }
@@ -89,66 +163,137 @@ func (cmd *RunCommand) Register(r command.Registerer) {
 // Run reads files from the .secretsenv/<env-name> directory, sets them as environment variables and runs the given command.
 // Note that the environment variables are only passed to the child process and not exported globally, which is nice.
 func (cmd *RunCommand) Run() error {
-	environment, secrets, err := cmd.sourceEnvironment()
+	var restartSignal syscall.Signal
+	if cmd.restartOnChange {
+		var err error
+		restartSignal, err = parseSignal(cmd.restartSignal)
+		if err != nil {
+			return err
+		}
+
+		if cmd.restartInterval <= 0 {
+			return ErrInvalidRestartInterval
+		}
+	}
+
+	err := cmd.envFilter.validate()
 	if err != nil {
 		return err
 	}
 
-	// This makes sure commands encapsulated in quotes also work.
-	if len(cmd.command) == 1 {
-		cmd.command = strings.Split(cmd.command[0], " ")
+	if cmd.dryRun {
+		return cmd.printSources()
 	}
 
-	valuesToMask := make([][]byte, 0, len(secrets))
-	for _, val := range secrets {
-		if val != "" {
-			valuesToMask = append(valuesToMask, []byte(val))
+	if cmd.initMode || isInitProcess() {
+		signalMap, err := parseSignalMap(cmd.signalMap)
+		if err != nil {
+			return err
+		}
+
+		cmd.init, err = newInitProcess(signalMap, cmd.killTimeout)
+		if err != nil {
+			return err
 		}
+		defer cmd.init.stop()
 	}
 
-	maskedStdout := masker.NewMaskedWriter(cmd.io.Stdout(), valuesToMask, maskString, cmd.maskingTimeout)
-	maskedStderr := masker.NewMaskedWriter(os.Stderr, valuesToMask, maskString, cmd.maskingTimeout)
+	environment, fileContents, secrets, versions, err := cmd.sourceEnvironment()
+	if err != nil {
+		return err
+	}
 
-	command := exec.Command(cmd.command[0], cmd.command[1:]...)
-	command.Env = environment
-	command.Stdin = os.Stdin
+	files, err := newRunFiles(fileContents, cmd.fileMode.FileMode())
+	if err != nil {
+		return err
+	}
+	defer files.close()
+
+	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
+	cmd.notifier, err = newSystemdNotifier(cmd.systemdNotify, osEnv)
+	if err != nil {
+		return err
+	}
+	if cmd.notifier != nil {
+		defer cmd.notifier.close()
+	}
+
+	// This makes sure commands encapsulated in quotes also work.
+	if len(cmd.command) == 1 {
+		cmd.command = strings.Split(cmd.command[0], " ")
+	}
+
+	maskedStdout := masker.NewMaskedWriter(cmd.io.Stdout(), cmd.valuesToMask(secrets), maskString, cmd.maskingTimeout)
+	maskedStderr := masker.NewMaskedWriter(os.Stderr, cmd.valuesToMask(secrets), maskString, cmd.maskingTimeout)
+
+	var stdout, stderr io.Writer
 	if cmd.noMasking {
-		command.Stdout = cmd.io.Stdout()
-		command.Stderr = os.Stderr
+		stdout = cmd.io.Stdout()
+		stderr = os.Stderr
 	} else {
-		command.Stdout = maskedStdout
-		command.Stderr = maskedStderr
+		stdout = maskedStdout
+		stderr = maskedStderr
 
 		go maskedStdout.Run()
 		go maskedStderr.Run()
 	}
 
-	err = command.Start()
+	if cmd.tty {
+		cmd.terminal, err = openTerminal(os.Stdin, stdout)
+		if err != nil {
+			return ErrOpenTerminal(err)
+		}
+	}
+
+	command := cmd.newChildCommand(environment, files, stdout, stderr)
+	err = cmd.startCommand(command)
 	if err != nil {
+		if cmd.terminal != nil {
+			cmd.terminal.close()
+		}
 		return ErrStartFailed(err)
 	}
+	if cmd.notifier != nil {
+		cmd.notifier.started()
+	}
 
-	done := make(chan bool, 1)
+	var commandErr error
+	if cmd.restartOnChange {
+		commandErr = cmd.restartOnSecretChange(command, files, versions, restartSignal, stdout, stderr, maskedStdout, maskedStderr)
+	} else {
+		done := make(chan bool, 1)
+		stopped := make(chan struct{})
+
+		// Pass all signals to child process
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals)
+
+		go func() {
+			defer close(stopped)
+			for {
+				select {
+				case s := <-signals:
+					cmd.forwardSignal(command, s)
+				case <-done:
+					signal.Stop(signals)
+					return
+				}
+			}
+		}()
 
-	// Pass all signals to child process
-	signals := make(chan os.Signal, 1)
-	signal.Notify(signals)
+		commandErr = cmd.waitCommand(command)
+		done <- true
+		// Wait until no signal is being forwarded anymore, as forwarding a signal uses the terminal.
+		<-stopped
+	}
 
-	go func() {
-		select {
-		case s := <-signals:
-			err := command.Process.Signal(s)
-			if err != nil && !strings.Contains(err.Error(), "process already finished") {
-				fmt.Fprintln(os.Stderr, ErrSignalFailed(err))
-			}
-		case <-done:
-			signal.Stop(signals)
-			return
+	// The remaining output in the terminal is read before the output is flushed.
+	if cmd.terminal != nil {
+		err = cmd.terminal.close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
 		}
-	}()
-
-	commandErr := command.Wait()
-	done <- true
+	}
 
 	if !cmd.noMasking {
 		err = maskedStdout.Flush()
@@ -161,17 +306,20 @@ func (cmd *RunCommand) Run() error {
 		}
 	}
 
+	// The secret files and injected templates are removed before exiting with the exit code of the command.
+	err = files.close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
 	if commandErr != nil {
 		// Check if the program exited with an error
-		exitErr, ok := commandErr.(*exec.ExitError)
+		waitStatus, ok := commandWaitStatus(commandErr)
 		if ok {
-			waitStatus, ok := exitErr.Sys().(syscall.WaitStatus)
-			if ok {
-				// Return the status code returned by the process
-				os.Exit(waitStatus.ExitStatus())
-				return nil
-			}
-
+			// Return the status code returned by the process, or 128 plus
+			// the number of the signal that killed the process.
+			os.Exit(exitCode(waitStatus))
+			return nil
 		}
 		return commandErr
 	}
@@ -179,18 +327,272 @@ func (cmd *RunCommand) Run() error {
 	return nil
 }
 
-// sourceEnvironment returns the environment of the subcommand, with all the secrets sourced
-// and the secret values that need to be masked.
-func (cmd *RunCommand) sourceEnvironment() ([]string, []string, error) {
-	_, passthroughEnv := parseKeyValueStringsToMap(cmd.osEnv)
+// printSources prints the sources of the variables the command would get, without reading the secrets.
+func (cmd *RunCommand) printSources() error {
+	cmd.environment.osEnvFilter = &cmd.envFilter
+	sourced, err := cmd.environment.sourcedEnv()
+	if err != nil {
+		return err
+	}
+
+	// The variables with the paths of the secret files override the environment.
+	fileValues, err := cmd.secretFileValues()
+	if err != nil {
+		return err
+	}
+	for name, value := range fileValues {
+		sourced[name] = append(sourced[name], sourcedValue{source: envSourceSecretFile, value: value})
+	}
+
+	client, err := cmd.newClient()
+	if err != nil {
+		return err
+	}
+
+	table, err := envSourcesTable(sourced, client.Secrets().Versions().GetWithoutData)
+	if err != nil {
+		return err
+	}
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
+
+// newChildCommand returns the command to run with the given environment, secret files and output streams.
+func (cmd *RunCommand) newChildCommand(environment []string, files *runFiles, stdout, stderr io.Writer) *exec.Cmd {
+	command := exec.Command(cmd.command[0], cmd.command[1:]...)
+	command.Env = append(environment, files.env...)
+	if cmd.notifier != nil {
+		command.Env = cmd.notifier.env(command.Env)
+	}
+	command.ExtraFiles = files.extraFiles
+	command.Stdin = os.Stdin
+	command.Stdout = stdout
+	command.Stderr = stderr
+	if cmd.terminal != nil {
+		cmd.terminal.Attach(command)
+	}
+	return command
+}
+
+// forwardSignal passes the signal to the process of the command. When the command
+// runs in a terminal, signals for resizing the terminal are not passed on, as
+// the terminal sends them itself when it is resized.
+func (cmd *RunCommand) forwardSignal(command *exec.Cmd, s os.Signal) {
+	if cmd.terminal != nil && pty.IsResizeSignal(s) {
+		return
+	}
+
+	var err error
+	if cmd.init != nil {
+		err = cmd.init.forward(command, s)
+	} else {
+		err = command.Process.Signal(s)
+	}
+	if err != nil && !isProcessFinished(err) {
+		fmt.Fprintln(os.Stderr, ErrSignalFailed(err))
+	}
+}
+
+// restartOnSecretChange waits for the started command to exit, while checking the secrets
+// with the given versions for new versions. When a secret has changed, the command is
+// stopped with the restart signal and started again with the new environment. Signals
+// are passed to the running command. It returns the error of the command once it exits
+// by itself. The files of the stopped command are replaced by the files of the new command.
+func (cmd *RunCommand) restartOnSecretChange(command *exec.Cmd, files *runFiles, versions map[string]int, restartSignal syscall.Signal, stdout, stderr io.Writer, maskedStdout, maskedStderr *masker.MaskedWriter) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(cmd.restartInterval)
+	defer ticker.Stop()
+
+	exited := cmd.waitForExit(command)
+	for {
+		select {
+		case err := <-exited:
+			return err
+		case s := <-signals:
+			cmd.forwardSignal(command, s)
+		case <-ticker.C:
+			changed, err := secretVersionsChanged(cmd.newClient, versions)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+
+			environment, fileContents, secrets, newVersions, err := cmd.sourceEnvironment()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+
+			fmt.Fprintln(os.Stderr, "A secret has changed, restarting the command.")
+			terminated, exitErr := cmd.stopProcess(command, exited, signals, restartSignal, cmd.restartGracePeriod)
+			if terminated {
+				// The command is not restarted when this process is asked to terminate while the command is stopping.
+				return exitErr
+			}
+
+			// The files of the stopped command are removed first, as the
+			// injected templates are written to the same paths again.
+			err = files.close()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+
+			newFiles, err := newRunFiles(fileContents, cmd.fileMode.FileMode())
+			if err != nil {
+				return err
+			}
+			*files = *newFiles
+
+			if !cmd.noMasking {
+				maskedStdout.AddMasks(cmd.valuesToMask(secrets))
+				maskedStderr.AddMasks(cmd.valuesToMask(secrets))
+			}
+
+			command = cmd.newChildCommand(environment, files, stdout, stderr)
+			err = cmd.startCommand(command)
+			if err != nil {
+				return ErrStartFailed(err)
+			}
+			exited = cmd.waitForExit(command)
+			versions = newVersions
+		}
+	}
+}
+
+// startCommand starts the command. With --init, the command is started in its own process group.
+func (cmd *RunCommand) startCommand(command *exec.Cmd) error {
+	if cmd.init != nil {
+		return cmd.init.start(command)
+	}
+	return command.Start()
+}
+
+// waitCommand waits for the command to exit.
+func (cmd *RunCommand) waitCommand(command *exec.Cmd) error {
+	if cmd.init != nil {
+		return cmd.init.wait(command)
+	}
+	return command.Wait()
+}
+
+// waitForExit waits for the command to exit in the background and sends
+// the resulting error on the returned channel.
+func (cmd *RunCommand) waitForExit(command *exec.Cmd) <-chan error {
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.waitCommand(command)
+	}()
+	return exited
+}
+
+// stopProcess sends the signal to the process of the command and waits for it to
+// exit. When it has not exited after the grace period, the process is killed.
+// With --init, the whole process group of the command is signaled and killed.
+// The signals received while waiting are passed to the command. It returns the
+// error the command exited with and whether a termination signal was received.
+func (cmd *RunCommand) stopProcess(command *exec.Cmd, exited <-chan error, signals <-chan os.Signal, s syscall.Signal, gracePeriod time.Duration) (bool, error) {
+	err := cmd.signalProcess(command, s)
+	if err != nil && !isProcessFinished(err) {
+		fmt.Fprintln(os.Stderr, ErrSignalFailed(err))
+	}
+
+	terminated := false
+	timeout := time.After(gracePeriod)
+	for {
+		select {
+		case exitErr := <-exited:
+			return terminated, exitErr
+		case received := <-signals:
+			cmd.forwardSignal(command, received)
+			sig, ok := received.(syscall.Signal)
+			if ok && isTerminationSignal(sig) {
+				terminated = true
+			}
+		case <-timeout:
+			err = cmd.signalProcess(command, syscall.SIGKILL)
+			if err != nil && !isProcessFinished(err) {
+				fmt.Fprintln(os.Stderr, ErrSignalFailed(err))
+			}
+			timeout = nil
+		}
+	}
+}
+
+// signalProcess sends the signal to the process of the command, or to its process group with --init.
+func (cmd *RunCommand) signalProcess(command *exec.Cmd, s syscall.Signal) error {
+	if cmd.init != nil {
+		return signalProcessGroup(command.Process, s)
+	}
+	return command.Process.Signal(s)
+}
+
+// valuesToMask returns the sequences to mask in the output of the command: the non-empty
+// secret values, the lines of multi-line secrets and the secrets in the --mask-encodings.
+func (cmd *RunCommand) valuesToMask(secrets []string) [][]byte {
+	values := make([][]byte, 0, len(secrets))
+	for _, val := range secrets {
+		if val != "" {
+			values = append(values, []byte(val))
+		}
+	}
+	return masker.Sequences(values, cmd.maskEncodings)
+}
+
+// sourceEnvironment returns the environment of the subcommand, with all the secrets sourced,
+// the contents of the secret files and injected templates, the secret values that need to
+// be masked and the versions of the sourced secrets by path.
+func (cmd *RunCommand) sourceEnvironment() ([]string, runFileContents, []string, map[string]int, error) {
+	_, passthroughEnv := parseKeyValueStringsToMap(cmd.envFilter.filter(cmd.osEnv))
 	newEnv := map[string]string{}
 
+	// Only the variables of the OS environment that pass the filter are sourced.
+	cmd.environment.osEnvFilter = &cmd.envFilter
+
 	envValues, err := cmd.environment.env()
 	if err != nil {
-		return nil, nil, err
+		return nil, runFileContents{}, nil, nil, err
 	}
 
-	var sr tpl.SecretReader = newSecretReader(cmd.newClient)
+	fileValues, err := cmd.secretFileValues()
+	if err != nil {
+		return nil, runFileContents{}, nil, nil, err
+	}
+
+	injectValues, err := cmd.injectValues()
+	if err != nil {
+		return nil, runFileContents{}, nil, nil, err
+	}
+
+	credentialValues, err := cmd.credentialValues()
+	if err != nil {
+		return nil, runFileContents{}, nil, nil, err
+	}
+
+	cachingReader := newCachingSecretReader(cmd.newClient, cmd.cacheConfig.cache(cmd.stderr))
+	values := make([]value, 0, len(envValues)+len(fileValues)+len(injectValues)+len(credentialValues))
+	for _, value := range envValues {
+		values = append(values, value)
+	}
+	for _, value := range fileValues {
+		values = append(values, value)
+	}
+	for _, value := range injectValues {
+		values = append(values, value)
+	}
+	for _, value := range credentialValues {
+		values = append(values, value)
+	}
+	err = prefetchSecrets(cachingReader, values...)
+	if err != nil {
+		return nil, runFileContents{}, nil, nil, err
+	}
+
+	var sr tpl.SecretReader = cachingReader
 	if cmd.ignoreMissingSecrets {
 		sr = newIgnoreMissingSecretReader(sr)
 	}
@@ -199,14 +601,148 @@ func (cmd *RunCommand) sourceEnvironment() ([]string, []string, error) {
 	for name, value := range envValues {
 		newEnv[name], err = value.resolve(secretReader)
 		if err != nil {
-			return nil, nil, err
+			return nil, runFileContents{}, nil, nil, err
 		}
 	}
 
+	osEnv, _ := parseKeyValueStringsToMap(cmd.osEnv)
+	fileContents := runFileContents{
+		secrets:                 make(map[string]string, len(fileValues)),
+		templates:               make(map[string]string, len(injectValues)),
+		credentials:             make(map[string]string, len(credentialValues)),
+		inheritedCredentialsDir: osEnv[credentialsDirectoryEnvVar],
+	}
+	for name, value := range fileValues {
+		fileContents.secrets[name], err = value.resolve(secretReader)
+		if err != nil {
+			return nil, runFileContents{}, nil, nil, err
+		}
+		// The variable is set to the path of the file instead.
+		delete(newEnv, name)
+	}
+
+	for outFile, value := range injectValues {
+		fileContents.templates[outFile], err = value.resolve(secretReader)
+		if err != nil {
+			return nil, runFileContents{}, nil, nil, err
+		}
+	}
+
+	for name, value := range credentialValues {
+		fileContents.credentials[name], err = value.resolve(secretReader)
+		if err != nil {
+			return nil, runFileContents{}, nil, nil, err
+		}
+	}
+
+	// Secrets that do not exist are ignored with --ignore-missing-secrets and get version 0.
+	readVersions := cachingReader.Versions()
+	versions := make(map[string]int)
+	for _, path := range secretReader.Paths() {
+		versions[path] = readVersions[path]
+	}
+
 	// Finally add the unparsed variables
 	processedOsEnv := append(passthroughEnv, mapToKeyValueStrings(newEnv)...)
 
-	return processedOsEnv, secretReader.Values(), nil
+	return processedOsEnv, fileContents, secretReader.Values(), versions, nil
+}
+
+// injectValues returns the templates of the --inject flags by their output path.
+// They are parsed with the template version and variables of the environment.
+func (cmd *RunCommand) injectValues() (map[string]value, error) {
+	values := make(map[string]value, len(cmd.injects))
+	if len(cmd.injects) == 0 {
+		return values, nil
+	}
+
+	varReader, err := cmd.environment.variableReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, inject := range cmd.injects {
+		inFile, outFile, err := parseInjectFlag(inject)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := cmd.environment.readFile(inFile)
+		if err != nil {
+			return nil, ErrCannotReadFile(inFile, err)
+		}
+
+		parser, err := getTemplateParser(raw, cmd.environment.templateVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		template, err := parser.Parse(string(raw), 1, 1)
+		if err != nil {
+			return nil, ErrParsingTemplate(inFile, err)
+		}
+
+		values[outFile] = newTemplateValue(inFile, template, varReader)
+	}
+	return values, nil
+}
+
+// parseInjectFlag splits the value of an --inject flag into the template and the out file.
+// A colon that is part of a Windows volume name, e.g. C:, is not seen as the separator.
+func parseInjectFlag(inject string) (string, string, error) {
+	volume := filepath.VolumeName(inject)
+	i := strings.Index(inject[len(volume):], ":")
+	if i < 0 {
+		return "", "", ErrInvalidInject(inject)
+	}
+	i += len(volume)
+
+	inFile, outFile := inject[:i], inject[i+1:]
+	if inFile == "" || outFile == "" {
+		return "", "", ErrInvalidInject(inject)
+	}
+	return inFile, outFile, nil
+}
+
+// secretFileValues returns the secrets to write to files for the --secret-file flags,
+// by the name of the environment variable that is set to the path of the file.
+// Like secrethub:// references, the path can point to a field: path/to/secret#field.
+func (cmd *RunCommand) secretFileValues() (map[string]value, error) {
+	values := make(map[string]value, len(cmd.secretFiles))
+	for name, ref := range cmd.secretFiles {
+		if !validation.IsEnvarNamePosix(name) {
+			return nil, ErrInvalidSecretFile(name)
+		}
+
+		path, field := tpl.SplitField(ref)
+		err := api.ValidateSecretPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		values[name] = &secretValue{path: path, field: field}
+	}
+	return values, nil
+}
+
+// credentialValues returns the secrets to write to credential files for the --load-credential flags,
+// by the name of their credential. Like systemd, names cannot contain a slash and cannot be . or ..
+func (cmd *RunCommand) credentialValues() (map[string]value, error) {
+	values := make(map[string]value, len(cmd.loadCredentials))
+	for name, ref := range cmd.loadCredentials {
+		if name == "" || name == "." || name == ".." || strings.ContainsRune(name, '/') {
+			return nil, ErrInvalidCredentialName(name)
+		}
+
+		path, field := tpl.SplitField(ref)
+		err := api.ValidateSecretPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		values[name] = &secretValue{path: path, field: field}
+	}
+	return values, nil
 }
 
 // mapToKeyValueStrings converts a map to a slice of key=value pairs.
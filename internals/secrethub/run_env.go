@@ -0,0 +1,137 @@
+package secrethub
+
+import (
+	"path"
+	"strings"
+)
+
+// secrethubEnvVarPrefix is the prefix of the environment variables that configure
+// the CLI, such as SECRETHUB_CREDENTIAL and SECRETHUB_CREDENTIAL_PASSPHRASE.
+const secrethubEnvVarPrefix = "SECRETHUB_"
+
+// envFilter decides which variables of the OS environment are passed to the command.
+// The variables that are sourced from secrets, env files and flags are always passed.
+// A nil filter passes all variables.
+type envFilter struct {
+	// clean only passes the variables that match a pass glob.
+	clean bool
+	// pass are the globs of the variables that are passed, also when they are removed otherwise.
+	pass []string
+	// drop are the globs of the variables that are never passed.
+	drop []string
+	// keepSecretHub passes the SECRETHUB_ variables, which are removed by default
+	// so that the command cannot use the credential of the CLI.
+	keepSecretHub bool
+}
+
+// validate returns an error when a glob is invalid.
+func (f *envFilter) validate() error {
+	for _, globs := range []struct {
+		flag  string
+		globs []string
+	}{
+		{"pass-env", f.pass},
+		{"drop-env", f.drop},
+	} {
+		for _, glob := range globs.globs {
+			err := validateGlob(glob)
+			if err != nil {
+				return ErrInvalidEnvGlob(glob, globs.flag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// passes returns whether the variable with the given name is passed to the command.
+func (f *envFilter) passes(name string) bool {
+	if f == nil {
+		return true
+	}
+
+	if matchesAnyGlob(name, f.drop) {
+		return false
+	}
+	if matchesAnyGlob(name, f.pass) {
+		return true
+	}
+	if f.clean {
+		return false
+	}
+	return f.keepSecretHub || !strings.HasPrefix(name, secrethubEnvVarPrefix)
+}
+
+// filter returns the NAME=value pairs of the variables that are passed to the command.
+func (f *envFilter) filter(environment []string) []string {
+	if f == nil {
+		return environment
+	}
+
+	result := make([]string, 0, len(environment))
+	for _, envvar := range environment {
+		if f.passes(strings.SplitN(envvar, "=", 2)[0]) {
+			result = append(result, envvar)
+		}
+	}
+	return result
+}
+
+// matchesAnyGlob returns whether the name matches any of the globs, which are validated beforehand.
+func matchesAnyGlob(name string, globs []string) bool {
+	for _, glob := range globs {
+		matched, _ := path.Match(glob, name)
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateGlob returns path.ErrBadPattern when the glob is malformed. This is checked
+// separately, because path.Match only reports a malformed glob when it reads the
+// malformed part, which depends on the name that is matched.
+func validateGlob(glob string) error {
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '\\':
+			i++
+			if i == len(glob) {
+				return path.ErrBadPattern
+			}
+		case '[':
+			i++
+			if i < len(glob) && glob[i] == '^' {
+				i++
+			}
+			// A character class contains at least one character or range.
+			for n := 0; i == len(glob) || glob[i] != ']' || n == 0; n++ {
+				var err error
+				i, err = skipClassChar(glob, i)
+				if err != nil {
+					return err
+				}
+				if i < len(glob) && glob[i] == '-' {
+					i, err = skipClassChar(glob, i+1)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// skipClassChar returns the index after the possibly escaped character of a character class at index i.
+func skipClassChar(glob string, i int) (int, error) {
+	if i == len(glob) || glob[i] == '-' || glob[i] == ']' {
+		return 0, path.ErrBadPattern
+	}
+	if glob[i] == '\\' {
+		i++
+		if i == len(glob) {
+			return 0, path.ErrBadPattern
+		}
+	}
+	return i + 1, nil
+}
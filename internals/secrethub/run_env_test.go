@@ -0,0 +1,143 @@
+package secrethub
+
+import (
+	"path"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestEnvFilter(t *testing.T) {
+	environment := []string{
+		"HOME=/root",
+		"LC_ALL=C",
+		"LC_TIME=C",
+		"AWS_SECRET_ACCESS_KEY=key",
+		"SECRETHUB_CREDENTIAL=credential",
+		"SECRETHUB_CREDENTIAL_PASSPHRASE=passphrase",
+		"SECRETHUB_VAR_ENV=prod",
+	}
+
+	cases := map[string]struct {
+		filter   *envFilter
+		expected []string
+	}{
+		"nil": {
+			filter:   nil,
+			expected: environment,
+		},
+		"default": {
+			filter:   &envFilter{},
+			expected: []string{"HOME=/root", "LC_ALL=C", "LC_TIME=C", "AWS_SECRET_ACCESS_KEY=key"},
+		},
+		"keep secrethub": {
+			filter:   &envFilter{keepSecretHub: true},
+			expected: environment,
+		},
+		"clean": {
+			filter:   &envFilter{clean: true},
+			expected: []string{},
+		},
+		"clean with pass": {
+			filter:   &envFilter{clean: true, pass: []string{"HOME", "LC_*"}},
+			expected: []string{"HOME=/root", "LC_ALL=C", "LC_TIME=C"},
+		},
+		"pass secrethub variable": {
+			filter:   &envFilter{pass: []string{"SECRETHUB_VAR_*"}},
+			expected: []string{"HOME=/root", "LC_ALL=C", "LC_TIME=C", "AWS_SECRET_ACCESS_KEY=key", "SECRETHUB_VAR_ENV=prod"},
+		},
+		"drop": {
+			filter:   &envFilter{drop: []string{"AWS_*", "LC_?IME"}},
+			expected: []string{"HOME=/root", "LC_ALL=C"},
+		},
+		"drop has precedence over pass": {
+			filter:   &envFilter{clean: true, pass: []string{"LC_*"}, drop: []string{"LC_TIME"}},
+			expected: []string{"LC_ALL=C"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.filter.filter(environment), tc.expected)
+		})
+	}
+}
+
+func TestEnvFilter_Validate(t *testing.T) {
+	cases := map[string]struct {
+		filter envFilter
+		err    error
+	}{
+		"valid": {
+			filter: envFilter{pass: []string{"LC_*", "HOME"}, drop: []string{"AWS_[A-Z]*"}},
+		},
+		"invalid pass": {
+			filter: envFilter{pass: []string{"LC_["}},
+			err:    ErrInvalidEnvGlob("LC_[", "pass-env", path.ErrBadPattern),
+		},
+		"invalid drop": {
+			filter: envFilter{drop: []string{"AWS_[A-"}},
+			err:    ErrInvalidEnvGlob("AWS_[A-", "drop-env", path.ErrBadPattern),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.filter.validate(), tc.err)
+		})
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	cases := map[string]error{
+		"HOME":         nil,
+		"LC_*":         nil,
+		"LC_?IME":      nil,
+		"AWS_[A-Z]*":   nil,
+		"AWS_[^A-Z_]*": nil,
+		`A\*`:          nil,
+		`[\]]`:         nil,
+		"[":            path.ErrBadPattern,
+		"LC_[":         path.ErrBadPattern,
+		"LC_[]":        path.ErrBadPattern,
+		"LC_[^]":       path.ErrBadPattern,
+		"AWS_[A-]":     path.ErrBadPattern,
+		"AWS_[-Z]":     path.ErrBadPattern,
+		`AWS_\`:        path.ErrBadPattern,
+		`AWS_[A\`:      path.ErrBadPattern,
+	}
+
+	for glob, expected := range cases {
+		t.Run(glob, func(t *testing.T) {
+			assert.Equal(t, validateGlob(glob), expected)
+		})
+	}
+}
+
+// TestEnvironment_EnvFileExpansionIsFiltered tests that the variables removed
+// from the OS environment cannot be passed on by expanding them in the env file.
+func TestEnvironment_EnvFileExpansionIsFiltered(t *testing.T) {
+	env := &environment{
+		osStat:          osStatFunc("secrethub.env", nil),
+		readFile:        readFileFunc("secrethub.env", "HOME_DIR=${HOME}\nCREDENTIAL=${SECRETHUB_CREDENTIAL}"),
+		osEnv:           []string{"HOME=/root", "SECRETHUB_CREDENTIAL=credential"},
+		envar:           map[string]string{},
+		templateVars:    map[string]string{"secrethub_credential": "variable"},
+		templateVersion: "2",
+		osEnvFilter:     &envFilter{},
+	}
+
+	sourced, err := env.env()
+	assert.OK(t, err)
+
+	actual := make(map[string]string, len(sourced))
+	for name, value := range sourced {
+		actual[name], err = value.resolve(nil)
+		assert.OK(t, err)
+	}
+	assert.Equal(t, actual, map[string]string{
+		"HOME":       "/root",
+		"HOME_DIR":   "/root",
+		"CREDENTIAL": "variable",
+	})
+}
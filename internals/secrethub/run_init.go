@@ -0,0 +1,175 @@
+package secrethub
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// initProcess runs the command the way an init process (PID 1) of a container should.
+// It reaps all orphaned processes, passes signals to the process group of the command,
+// optionally mapped to other signals, and kills the process group when the command
+// does not exit in time after it was asked to terminate.
+type initProcess struct {
+	reaper      *reaper
+	signalMap   map[syscall.Signal]syscall.Signal
+	killTimeout time.Duration
+
+	mu        sync.Mutex
+	killTimer *time.Timer
+}
+
+// newInitProcess starts reaping the child processes. When this process is not
+// the init process, it becomes the child subreaper where supported, so that
+// orphaned processes are re-parented to it.
+func newInitProcess(signalMap map[syscall.Signal]syscall.Signal, killTimeout time.Duration) (*initProcess, error) {
+	if !initSupported {
+		return nil, ErrInitNotSupported
+	}
+
+	err := setChildSubreaper()
+	if err != nil {
+		return nil, err
+	}
+
+	return &initProcess{
+		reaper:      newReaper(),
+		signalMap:   signalMap,
+		killTimeout: killTimeout,
+	}, nil
+}
+
+// start starts the command in its own process group.
+func (p *initProcess) start(command *exec.Cmd) error {
+	setProcessGroup(command)
+	return p.reaper.start(command)
+}
+
+// wait waits for the command to exit. The timer to kill the command is stopped
+// once it exits, so that it does not affect a command that is started next.
+func (p *initProcess) wait(command *exec.Cmd) error {
+	err := p.reaper.wait(command)
+
+	p.mu.Lock()
+	if p.killTimer != nil {
+		p.killTimer.Stop()
+		p.killTimer = nil
+	}
+	p.mu.Unlock()
+
+	return err
+}
+
+// forward passes the signal, or the signal it is mapped to, to the process group of the command.
+// When the signal asks the command to terminate, the process group is killed if the command
+// has not exited after the kill timeout.
+func (p *initProcess) forward(command *exec.Cmd, s os.Signal) error {
+	if isChildSignal(s) {
+		return nil
+	}
+
+	sig, ok := s.(syscall.Signal)
+	if !ok {
+		return command.Process.Signal(s)
+	}
+
+	if isTerminationSignal(sig) && p.killTimeout > 0 {
+		p.mu.Lock()
+		if p.killTimer == nil {
+			p.killTimer = time.AfterFunc(p.killTimeout, func() {
+				fmt.Fprintf(os.Stderr, "The command did not exit within %s after it was asked to terminate, killing it.\n", p.killTimeout)
+				err := signalProcessGroup(command.Process, syscall.SIGKILL)
+				if err != nil && !isProcessFinished(err) {
+					fmt.Fprintln(os.Stderr, ErrSignalFailed(err))
+				}
+			})
+		}
+		p.mu.Unlock()
+	}
+
+	mapped, ok := p.signalMap[sig]
+	if ok {
+		sig = mapped
+	}
+	return signalProcessGroup(command.Process, sig)
+}
+
+// stop stops reaping the child processes.
+func (p *initProcess) stop() {
+	p.reaper.stop()
+}
+
+// isTerminationSignal returns whether the signal asks a process to terminate.
+func isTerminationSignal(s syscall.Signal) bool {
+	return s == syscall.SIGTERM || s == syscall.SIGINT || s == syscall.SIGQUIT || s == syscall.SIGHUP
+}
+
+// isProcessFinished returns whether the error is returned because the process has already exited.
+func isProcessFinished(err error) bool {
+	return err == syscall.ESRCH || strings.Contains(err.Error(), "process already finished")
+}
+
+// parseSignalMap parses the --signal-map flags, which map a signal to the
+// signal that is passed to the command instead, e.g. TERM:QUIT.
+func parseSignalMap(values []string) (map[syscall.Signal]syscall.Signal, error) {
+	signalMap := make(map[syscall.Signal]syscall.Signal, len(values))
+	for _, value := range values {
+		parts := strings.Split(value, ":")
+		if len(parts) != 2 {
+			return nil, ErrInvalidSignalMap(value)
+		}
+
+		from, err := parseSignal(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := parseSignal(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		signalMap[from] = to
+	}
+	return signalMap, nil
+}
+
+// exitStatusError is returned when a command that is reaped by the init process
+// does not exit successfully.
+type exitStatusError struct {
+	status syscall.WaitStatus
+}
+
+func (e exitStatusError) Error() string {
+	if e.status.Signaled() {
+		return "signal: " + e.status.Signal().String()
+	}
+	return fmt.Sprintf("exit status %d", e.status.ExitStatus())
+}
+
+// commandWaitStatus returns the wait status of the command when the error is
+// returned because the command did not exit successfully.
+func commandWaitStatus(err error) (syscall.WaitStatus, bool) {
+	switch e := err.(type) {
+	case exitStatusError:
+		return e.status, true
+	case *exec.ExitError:
+		status, ok := e.Sys().(syscall.WaitStatus)
+		return status, ok
+	}
+	var status syscall.WaitStatus
+	return status, false
+}
+
+// exitCode returns the exit code of a command the way a POSIX shell does: the exit status
+// when the command exited, or 128 plus the number of the signal that killed the command.
+func exitCode(status syscall.WaitStatus) int {
+	if status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return status.ExitStatus()
+}
@@ -0,0 +1,20 @@
+package secrethub
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// setChildSubreaper marks this process as a child subreaper, so that orphaned
+// descendants are re-parented to it instead of to PID 1 and can be reaped by it.
+// It does nothing when this process already is the init process.
+func setChildSubreaper() error {
+	if isInitProcess() {
+		return nil
+	}
+
+	err := unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0)
+	if err != nil {
+		return ErrSetChildSubreaper(err)
+	}
+	return nil
+}
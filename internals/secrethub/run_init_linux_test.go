@@ -0,0 +1,22 @@
+package secrethub
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestNewInitProcess_ChildSubreaper(t *testing.T) {
+	p, err := newInitProcess(nil, time.Second)
+	assert.OK(t, err)
+	defer p.reaper.stop()
+
+	var subreaper int32
+	err = unix.Prctl(unix.PR_GET_CHILD_SUBREAPER, uintptr(unsafe.Pointer(&subreaper)), 0, 0, 0)
+	assert.OK(t, err)
+	assert.Equal(t, subreaper, int32(1))
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package secrethub
+
+// setChildSubreaper does nothing, as child subreapers are only supported on Linux.
+// Orphaned descendants are only reaped when this process is the init process.
+func setChildSubreaper() error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package secrethub
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestParseSignalMap(t *testing.T) {
+	cases := map[string]struct {
+		values   []string
+		expected map[syscall.Signal]syscall.Signal
+		err      error
+	}{
+		"none": {
+			expected: map[syscall.Signal]syscall.Signal{},
+		},
+		"names": {
+			values: []string{"TERM:QUIT", "SIGINT:SIGTERM"},
+			expected: map[syscall.Signal]syscall.Signal{
+				syscall.SIGTERM: syscall.SIGQUIT,
+				syscall.SIGINT:  syscall.SIGTERM,
+			},
+		},
+		"numbers": {
+			values: []string{"15:9"},
+			expected: map[syscall.Signal]syscall.Signal{
+				syscall.Signal(15): syscall.Signal(9),
+			},
+		},
+		"missing separator": {
+			values: []string{"TERM"},
+			err:    ErrInvalidSignalMap("TERM"),
+		},
+		"too many separators": {
+			values: []string{"TERM:QUIT:INT"},
+			err:    ErrInvalidSignalMap("TERM:QUIT:INT"),
+		},
+		"unknown signal": {
+			values: []string{"TERM:FOO"},
+			err:    ErrUnknownSignal("FOO"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseSignalMap(tc.values)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+//go:build !windows
+// +build !windows
+
+package secrethub
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// initSupported is true, as the init process behavior is supported on this platform.
+const initSupported = true
+
+// isInitProcess returns whether this process is the init process, e.g. when it is
+// the entrypoint of a container.
+func isInitProcess() bool {
+	return os.Getpid() == 1
+}
+
+// reaper waits for all child processes that exit, including orphaned processes that
+// are re-parented to this process. Processes that are not reaped remain zombies.
+// The exit status of the commands that are started with the reaper is passed on
+// to the callers waiting for them.
+type reaper struct {
+	mu      sync.Mutex
+	waiting map[int]chan syscall.WaitStatus
+	signals chan os.Signal
+	done    chan struct{}
+}
+
+// newReaper starts reaping child processes whenever one exits.
+func newReaper() *reaper {
+	r := &reaper{
+		waiting: make(map[int]chan syscall.WaitStatus),
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	signal.Notify(r.signals, syscall.SIGCHLD)
+
+	go func() {
+		for {
+			select {
+			case <-r.signals:
+				r.reap()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// start starts the command. The process of the command cannot be reaped before
+// it is registered, so that its exit status is always passed to wait.
+func (r *reaper) start(command *exec.Cmd) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := command.Start()
+	if err != nil {
+		return err
+	}
+	r.waiting[command.Process.Pid] = make(chan syscall.WaitStatus, 1)
+	return nil
+}
+
+// wait waits for the command to exit and returns an exitStatusError when
+// the command does not exit successfully.
+func (r *reaper) wait(command *exec.Cmd) error {
+	r.mu.Lock()
+	exited := r.waiting[command.Process.Pid]
+	r.mu.Unlock()
+
+	status := <-exited
+
+	r.mu.Lock()
+	delete(r.waiting, command.Process.Pid)
+	r.mu.Unlock()
+
+	// The process is already reaped, so waiting for the command results in an
+	// error. It is only needed to wait until all output of the command is copied.
+	_ = command.Wait()
+
+	if status.Exited() && status.ExitStatus() == 0 {
+		return nil
+	}
+	return exitStatusError{status: status}
+}
+
+// reap reaps all child processes that have exited.
+func (r *reaper) reap() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for {
+		var status syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil || pid <= 0 {
+			return
+		}
+
+		exited, ok := r.waiting[pid]
+		if ok {
+			exited <- status
+		}
+	}
+}
+
+// stop stops reaping child processes.
+func (r *reaper) stop() {
+	signal.Stop(r.signals)
+	close(r.done)
+}
+
+// isChildSignal returns whether the signal is sent because a child process exited.
+func isChildSignal(s os.Signal) bool {
+	return s == syscall.SIGCHLD
+}
+
+// setProcessGroup makes the command start in a new process group, so that signals can
+// be sent to it and all processes it starts. Commands that start in a new session,
+// e.g. to run in a terminal, already start in a new process group.
+func setProcessGroup(command *exec.Cmd) {
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	if !command.SysProcAttr.Setsid {
+		command.SysProcAttr.Setpgid = true
+	}
+}
+
+// signalProcessGroup sends the signal to the process group the process is the leader of.
+func signalProcessGroup(process *os.Process, s syscall.Signal) error {
+	return syscall.Kill(-process.Pid, s)
+}
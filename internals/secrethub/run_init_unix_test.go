@@ -0,0 +1,151 @@
+//go:build !windows
+// +build !windows
+
+package secrethub
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := map[string]struct {
+		script   string
+		expected int
+	}{
+		"exit status": {
+			script:   "exit 3",
+			expected: 3,
+		},
+		"killed by signal": {
+			script:   "kill -TERM $$",
+			expected: 128 + int(syscall.SIGTERM),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := exec.Command("/bin/sh", "-c", tc.script).Run()
+
+			status, ok := commandWaitStatus(err)
+			assert.Equal(t, ok, true)
+			assert.Equal(t, exitCode(status), tc.expected)
+		})
+	}
+}
+
+func TestInitProcess(t *testing.T) {
+	cases := map[string]struct {
+		script      string
+		signalMap   map[syscall.Signal]syscall.Signal
+		killTimeout time.Duration
+		expected    int
+	}{
+		"signal process group": {
+			// The wait in the trap only returns when the background process also receives the signal.
+			script:   `trap 'wait; exit 5' TERM; sh -c 'trap "exit 0" TERM; echo ready; while true; do sleep 0.01; done' & wait`,
+			expected: 5,
+		},
+		"mapped signal": {
+			script:    "trap 'exit 7' USR1; trap 'exit 8' TERM; echo ready; sleep 10 & wait",
+			signalMap: map[syscall.Signal]syscall.Signal{syscall.SIGTERM: syscall.SIGUSR1},
+			expected:  7,
+		},
+		"kill after timeout": {
+			script:      "trap '' TERM; echo ready; sleep 10 & wait",
+			killTimeout: 100 * time.Millisecond,
+			expected:    128 + int(syscall.SIGKILL),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			init, err := newInitProcess(tc.signalMap, tc.killTimeout)
+			assert.OK(t, err)
+			defer init.stop()
+
+			command := exec.Command("/bin/sh", "-c", tc.script)
+			stdout, err := command.StdoutPipe()
+			assert.OK(t, err)
+
+			err = init.start(command)
+			assert.OK(t, err)
+
+			// Wait for the traps to be set.
+			_, err = bufio.NewReader(stdout).ReadString('\n')
+			assert.OK(t, err)
+
+			err = init.forward(command, syscall.SIGTERM)
+			assert.OK(t, err)
+
+			err = init.wait(command)
+			status, ok := commandWaitStatus(err)
+			assert.Equal(t, ok, true)
+			assert.Equal(t, exitCode(status), tc.expected)
+		})
+	}
+}
+
+func TestRunCommand_StopProcess(t *testing.T) {
+	cases := map[string]struct {
+		script     string
+		signal     os.Signal
+		terminated bool
+		expected   int
+	}{
+		"exits": {
+			script:   "trap 'exit 3' TERM; echo ready; sleep 10 & wait",
+			expected: 3,
+		},
+		"killed after grace period": {
+			script:   "trap '' TERM; echo ready; sleep 10 & wait",
+			expected: 128 + int(syscall.SIGKILL),
+		},
+		"terminated during grace period": {
+			script:     "trap '' TERM; trap 'exit 4' INT; echo ready; sleep 10 & wait",
+			signal:     syscall.SIGINT,
+			terminated: true,
+			expected:   4,
+		},
+		"other signal during grace period": {
+			script:   "trap '' TERM; trap 'exit 5' USR1; echo ready; sleep 10 & wait",
+			signal:   syscall.SIGUSR1,
+			expected: 5,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cmd := RunCommand{}
+			command := exec.Command("/bin/sh", "-c", tc.script)
+			stdout, err := command.StdoutPipe()
+			assert.OK(t, err)
+
+			err = cmd.startCommand(command)
+			assert.OK(t, err)
+			exited := cmd.waitForExit(command)
+
+			// Wait for the traps to be set.
+			_, err = bufio.NewReader(stdout).ReadString('\n')
+			assert.OK(t, err)
+
+			signals := make(chan os.Signal, 1)
+			if tc.signal != nil {
+				signals <- tc.signal
+			}
+
+			terminated, err := cmd.stopProcess(command, exited, signals, syscall.SIGTERM, time.Second)
+			assert.Equal(t, terminated, tc.terminated)
+
+			status, ok := commandWaitStatus(err)
+			assert.Equal(t, ok, true)
+			assert.Equal(t, exitCode(status), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package secrethub
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// initSupported is false, as there is no init process on Windows.
+const initSupported = false
+
+// isInitProcess returns false, as there is no init process on Windows.
+func isInitProcess() bool {
+	return false
+}
+
+// reaper is not used on Windows, as there are no zombie processes.
+type reaper struct{}
+
+func newReaper() *reaper {
+	return &reaper{}
+}
+
+func (r *reaper) start(command *exec.Cmd) error {
+	return command.Start()
+}
+
+func (r *reaper) wait(command *exec.Cmd) error {
+	return command.Wait()
+}
+
+func (r *reaper) stop() {}
+
+func isChildSignal(s os.Signal) bool {
+	return false
+}
+
+func setProcessGroup(command *exec.Cmd) {}
+
+func signalProcessGroup(process *os.Process, s syscall.Signal) error {
+	return process.Signal(s)
+}
@@ -0,0 +1,142 @@
+package secrethub
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/sdnotify"
+)
+
+// The modes of --systemd-notify.
+const (
+	systemdNotifyProxy       = "proxy"
+	systemdNotifyPassthrough = "passthrough"
+	systemdNotifyReady       = "ready"
+)
+
+// systemdNotifier passes the notifications of the command to systemd, when
+// the command runs as a systemd service of Type=notify. Systemd sets the
+// NOTIFY_SOCKET of a service of that type.
+type systemdNotifier struct {
+	mode     string
+	socket   string
+	watchdog time.Duration
+	proxy    *sdnotify.Proxy
+
+	ready        bool
+	stopWatchdog chan struct{}
+}
+
+// newSystemdNotifier returns a notifier for the given mode, or nil when the
+// command does not run as a systemd service that sends notifications.
+func newSystemdNotifier(mode string, osEnv map[string]string) (*systemdNotifier, error) {
+	socket := osEnv[sdnotify.EnvNotifySocket]
+	if socket == "" {
+		return nil, nil
+	}
+
+	n := &systemdNotifier{
+		mode:   mode,
+		socket: socket,
+	}
+
+	usec, err := strconv.ParseInt(osEnv[sdnotify.EnvWatchdogUsec], 10, 64)
+	if err == nil && usec > 0 {
+		n.watchdog = time.Duration(usec) * time.Microsecond
+	}
+
+	if mode == systemdNotifyProxy {
+		n.proxy, err = sdnotify.NewProxy(secretFileDir(), socket)
+		if err != nil {
+			return nil, err
+		}
+		go n.proxy.Run(func(err error) {
+			fmt.Fprintln(os.Stderr, err)
+		})
+	}
+	return n, nil
+}
+
+// env returns the environment of the command for the mode of the notifier:
+//
+//   - proxy: the command sends its notifications to the proxy.
+//   - passthrough: the command sends its notifications directly to systemd,
+//     which requires NotifyAccess=all in the unit.
+//   - ready: the command does not send notifications itself.
+//
+// The notification variables are set by the notifier, so that they are also set
+// when the command does not get the environment of this process. The WATCHDOG_PID
+// is never set, as it is the PID of this process, which would stop the command
+// from notifying the watchdog.
+func (n *systemdNotifier) env(environment []string) []string {
+	result := make([]string, 0, len(environment)+2)
+	for _, envvar := range environment {
+		switch strings.SplitN(envvar, "=", 2)[0] {
+		case sdnotify.EnvNotifySocket, sdnotify.EnvWatchdogUsec, sdnotify.EnvWatchdogPID:
+			continue
+		}
+		result = append(result, envvar)
+	}
+
+	switch n.mode {
+	case systemdNotifyProxy:
+		result = append(result, sdnotify.EnvNotifySocket+"="+n.proxy.Socket())
+	case systemdNotifyPassthrough:
+		result = append(result, sdnotify.EnvNotifySocket+"="+n.socket)
+	default:
+		return result
+	}
+	if n.watchdog > 0 {
+		result = append(result, sdnotify.EnvWatchdogUsec+"="+strconv.FormatInt(int64(n.watchdog/time.Microsecond), 10))
+	}
+	return result
+}
+
+// started is called when the command has started. In the ready mode, systemd is notified
+// that the service is ready once the command has first started and the watchdog is
+// notified for as long as this process runs.
+func (n *systemdNotifier) started() {
+	if n.mode != systemdNotifyReady || n.ready {
+		return
+	}
+	n.ready = true
+
+	err := sdnotify.Send(n.socket, sdnotify.Ready)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	if n.watchdog > 0 {
+		n.stopWatchdog = make(chan struct{})
+		go func() {
+			// The watchdog is notified twice per interval, as recommended by systemd.
+			ticker := time.NewTicker(n.watchdog / 2)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					err := sdnotify.Send(n.socket, sdnotify.Watchdog)
+					if err != nil {
+						fmt.Fprintln(os.Stderr, err)
+					}
+				case <-n.stopWatchdog:
+					return
+				}
+			}
+		}()
+	}
+}
+
+// close stops passing on notifications.
+func (n *systemdNotifier) close() error {
+	if n.stopWatchdog != nil {
+		close(n.stopWatchdog)
+	}
+	if n.proxy != nil {
+		return n.proxy.Close()
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/sdnotify"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+// listenFakeNotifySocket listens on a notify socket like the one systemd gives to a service of Type=notify.
+func listenFakeNotifySocket(t *testing.T, dir string) *net.UnixConn {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: filepath.Join(dir, "notify"), Net: "unixgram"})
+	assert.OK(t, err)
+	return conn
+}
+
+// receiveNotification returns the next notification sent to the fake notify socket.
+func receiveNotification(t *testing.T, conn *net.UnixConn) string {
+	err := conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	assert.OK(t, err)
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	assert.OK(t, err)
+	return string(buf[:n])
+}
+
+func TestNewSystemdNotifier_NoSocket(t *testing.T) {
+	notifier, err := newSystemdNotifier(systemdNotifyProxy, map[string]string{})
+	assert.OK(t, err)
+	assert.Equal(t, notifier == nil, true)
+}
+
+func TestSystemdNotifier_Env(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-notify")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	conn := listenFakeNotifySocket(t, dir)
+	defer conn.Close()
+	socket := conn.LocalAddr().String()
+
+	environment := []string{
+		"HOME=/root",
+		sdnotify.EnvNotifySocket + "=" + socket,
+		sdnotify.EnvWatchdogUsec + "=30000000",
+		sdnotify.EnvWatchdogPID + "=1234",
+	}
+
+	cases := map[string]struct {
+		mode     string
+		expected func(n *systemdNotifier) []string
+	}{
+		"proxy": {
+			mode: systemdNotifyProxy,
+			expected: func(n *systemdNotifier) []string {
+				return []string{
+					"HOME=/root",
+					sdnotify.EnvNotifySocket + "=" + n.proxy.Socket(),
+					sdnotify.EnvWatchdogUsec + "=30000000",
+				}
+			},
+		},
+		"passthrough": {
+			mode: systemdNotifyPassthrough,
+			expected: func(n *systemdNotifier) []string {
+				return []string{
+					"HOME=/root",
+					sdnotify.EnvNotifySocket + "=" + socket,
+					sdnotify.EnvWatchdogUsec + "=30000000",
+				}
+			},
+		},
+		"ready": {
+			mode: systemdNotifyReady,
+			expected: func(n *systemdNotifier) []string {
+				return []string{
+					"HOME=/root",
+				}
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			osEnv, _ := parseKeyValueStringsToMap(environment)
+			notifier, err := newSystemdNotifier(tc.mode, osEnv)
+			assert.OK(t, err)
+			defer notifier.close()
+
+			assert.Equal(t, notifier.env(environment), tc.expected(notifier))
+		})
+	}
+}
+
+func TestSystemdNotifier_EnvCleanEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-notify")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	conn := listenFakeNotifySocket(t, dir)
+	defer conn.Close()
+
+	notifier, err := newSystemdNotifier(systemdNotifyPassthrough, map[string]string{
+		sdnotify.EnvNotifySocket: conn.LocalAddr().String(),
+		sdnotify.EnvWatchdogUsec: "30000000",
+	})
+	assert.OK(t, err)
+	defer notifier.close()
+
+	// The notification variables are set when the command does not get the environment of this process.
+	expected := []string{
+		sdnotify.EnvNotifySocket + "=" + conn.LocalAddr().String(),
+		sdnotify.EnvWatchdogUsec + "=30000000",
+	}
+	assert.Equal(t, notifier.env([]string{}), expected)
+}
+
+func TestSystemdNotifier_Proxy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-notify")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	conn := listenFakeNotifySocket(t, dir)
+	defer conn.Close()
+
+	environment := []string{sdnotify.EnvNotifySocket + "=" + conn.LocalAddr().String()}
+	osEnv, _ := parseKeyValueStringsToMap(environment)
+	notifier, err := newSystemdNotifier(systemdNotifyProxy, osEnv)
+	assert.OK(t, err)
+	defer notifier.close()
+
+	// The command sends its notifications to the socket in its environment.
+	var socket string
+	for _, envvar := range notifier.env(environment) {
+		socket = strings.TrimPrefix(envvar, sdnotify.EnvNotifySocket+"=")
+	}
+	assert.Equal(t, socket, notifier.proxy.Socket())
+
+	notifier.started()
+	err = sdnotify.Send(socket, sdnotify.Ready)
+	assert.OK(t, err)
+	assert.Equal(t, receiveNotification(t, conn), sdnotify.Ready)
+}
+
+func TestSystemdNotifier_Ready(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-notify")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	conn := listenFakeNotifySocket(t, dir)
+	defer conn.Close()
+
+	notifier, err := newSystemdNotifier(systemdNotifyReady, map[string]string{
+		sdnotify.EnvNotifySocket: conn.LocalAddr().String(),
+		sdnotify.EnvWatchdogUsec: "20000",
+	})
+	assert.OK(t, err)
+	defer notifier.close()
+
+	notifier.started()
+	assert.Equal(t, receiveNotification(t, conn), sdnotify.Ready)
+	assert.Equal(t, receiveNotification(t, conn), sdnotify.Watchdog)
+	assert.Equal(t, receiveNotification(t, conn), sdnotify.Watchdog)
+
+	// Systemd is only notified once that the service is ready, also when the command is restarted.
+	notifier.started()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, receiveNotification(t, conn), sdnotify.Watchdog)
+	}
+}
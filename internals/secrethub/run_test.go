@@ -304,7 +304,7 @@ func TestParseDotEnv(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			actual, err := parseDotEnv(strings.NewReader(tc.raw))
+			actual, err := parseDotEnv(strings.NewReader(tc.raw), dotEnvExpansion{})
 
 			elemEqual(t, actual, tc.expected)
 			assert.Equal(t, err, tc.err)
@@ -367,6 +367,7 @@ func TestParseYML(t *testing.T) {
 func TestNewEnv(t *testing.T) {
 	cases := map[string]struct {
 		raw               string
+		osEnv             map[string]string
 		replacements      map[string]string
 		templateVarReader tpl.VariableReader
 		expected          map[string]string
@@ -408,6 +409,41 @@ func TestNewEnv(t *testing.T) {
 				"key": "value",
 			},
 		},
+		"OS variable with template syntax": {
+			raw: "foo=${HOST}\nbar=\"${HOST}\"\nbaz=${foo}",
+			osEnv: map[string]string{
+				"HOST": "{{ x/y/z }}",
+			},
+			replacements: map[string]string{
+				"x/y/z": "secret",
+			},
+			expected: map[string]string{
+				"foo": "{{ x/y/z }}",
+				"bar": "{{ x/y/z }}",
+				"baz": "{{ x/y/z }}",
+			},
+		},
+		"template variable takes precedence over OS variable": {
+			raw: "foo=${app}",
+			osEnv: map[string]string{
+				"app": "os",
+			},
+			templateVarReader: fakes.FakeVariableReader{
+				Variables: map[string]string{
+					"app": "variable",
+				},
+			},
+			expected: map[string]string{
+				"foo": "variable",
+			},
+		},
+		"v1 OS variable with template syntax": {
+			raw: "foo=${HOST}\nbar=${path/to/secret}",
+			osEnv: map[string]string{
+				"HOST": "${x/y/z}",
+			},
+			err: ErrTemplate(1, errors.New("the value of ${HOST} cannot be used because it contains template syntax")),
+		},
 		"success yml": {
 			raw: "foo: bar\nbaz: ${path/to/secret}",
 			replacements: map[string]string{
@@ -445,7 +481,7 @@ func TestNewEnv(t *testing.T) {
 			parser, err := getTemplateParser([]byte(tc.raw), "auto")
 			assert.OK(t, err)
 
-			env, err := NewEnv("secrethub.env", strings.NewReader(tc.raw), tc.templateVarReader, parser)
+			env, err := NewEnv("secrethub.env", strings.NewReader(tc.raw), tc.osEnv, tc.templateVarReader, parser)
 			if err != nil {
 				assert.Equal(t, err, tc.err)
 			} else {
@@ -704,6 +740,43 @@ func TestRunCommand_environment(t *testing.T) {
 			},
 			err: ErrCannotReadFile("foo.env", &os.PathError{Op: "open", Path: "foo.env", Err: os.ErrNotExist}),
 		},
+		"env dir overridden by env file": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:          osStatFunc("secrethub.env", nil),
+					readFile:        readFileFunc("secrethub.env", "APP_USER=admin"),
+					envFile:         "secrethub.env",
+					templateVersion: "2",
+					envDirs:         map[string]string{"APP_": "company/app/prod"},
+					newClient: func() (secrethub.ClientInterface, error) {
+						return fakeclient.Client{
+							DirService: &fakeclient.DirService{
+								TreeGetter: fakeclient.TreeGetter{
+									ReturnsTree: &api.Tree{
+										RootDir: &api.Dir{
+											Secrets: []*api.Secret{{Name: "user"}, {Name: "password"}},
+										},
+									},
+								},
+							},
+						}, nil
+					},
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte("bbb")},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedSecrets: []string{"bbb"},
+			expectedEnv:     []string{"APP_USER=admin", "APP_PASSWORD=bbb"},
+		},
 		"custom env file success": {
 			command: RunCommand{
 				environment: &environment{
@@ -949,7 +1022,67 @@ func TestRunCommand_environment(t *testing.T) {
 					}, nil
 				},
 			},
-			expectedEnv: []string{"TEST=foo", "SECRETHUB_VAR_VARIABLE=bar"},
+			expectedEnv: []string{"TEST=foo"},
+		},
+		"secrethub variables are removed": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:   osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile: readFileFunc("secrethub.env", ""),
+					osEnv:    []string{"HOME=/root", "SECRETHUB_CREDENTIAL=credential", "SECRETHUB_CREDENTIAL_PASSPHRASE=passphrase"},
+				},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"HOME=/root"},
+		},
+		"--keep-secrethub-env": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:   osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile: readFileFunc("secrethub.env", ""),
+					osEnv:    []string{"HOME=/root", "SECRETHUB_CREDENTIAL=credential"},
+				},
+				envFilter: envFilter{keepSecretHub: true},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"HOME=/root", "SECRETHUB_CREDENTIAL=credential"},
+		},
+		"--clean-env with --pass-env": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:       osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile:     readFileFunc("secrethub.env", ""),
+					osEnv:        []string{"HOME=/root", "LC_ALL=C", "AWS_SECRET_ACCESS_KEY=key", "SECRETHUB_VAR_FOO=bar"},
+					envar:        map[string]string{"SECRETHUB_PASSWORD": "path/to/secret"},
+					templateVars: map[string]string{},
+				},
+				envFilter: envFilter{clean: true, pass: []string{"LC_*"}},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte("aaa")},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedSecrets: []string{"aaa"},
+			expectedEnv:     []string{"LC_ALL=C", "SECRETHUB_PASSWORD=aaa"},
+		},
+		"--drop-env": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:   osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile: readFileFunc("secrethub.env", ""),
+					osEnv:    []string{"HOME=/root", "AWS_SECRET_ACCESS_KEY=key", "TEST=secrethub://path/to/secret"},
+				},
+				envFilter: envFilter{drop: []string{"AWS_*", "TEST"}},
+			},
+			expectedSecrets: []string{},
+			expectedEnv:     []string{"HOME=/root"},
 		},
 		"v1 template syntax success": {
 			command: RunCommand{
@@ -974,11 +1107,33 @@ func TestRunCommand_environment(t *testing.T) {
 			expectedSecrets: []string{"bbb"},
 			expectedEnv:     []string{"TEST=bbb"},
 		},
+		"filtered secret": {
+			command: RunCommand{
+				environment: &environment{
+					osStat:          osStatFunc("secrethub.env", nil),
+					readFile:        readFileFunc("secrethub.env", "TEST={{ path/to/secret | base64 }}"),
+					templateVersion: "3",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte("bbb")},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedSecrets: []string{"bbb", "YmJi"},
+			expectedEnv:     []string{"TEST=YmJi"},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			env, secrets, err := tc.command.sourceEnvironment()
+			env, _, secrets, _, err := tc.command.sourceEnvironment()
 
 			sort.Strings(env)
 			sort.Strings(tc.expectedEnv)
@@ -992,6 +1147,47 @@ func TestRunCommand_environment(t *testing.T) {
 	}
 }
 
+func TestRunCommand_DryRun(t *testing.T) {
+	io := ui.NewFakeIO()
+	cmd := RunCommand{
+		io:      io,
+		command: []string{"/bin/sh", "-c", "exit 1"},
+		environment: &environment{
+			osStat:          osStatFunc("secrethub.env", nil),
+			readFile:        readFileFunc("secrethub.env", "DB_PASSWORD={{ company/app/db/password }}\nDB_HOST=db.example.com"),
+			osEnv:           []string{"HOME=/root", "DB_HOST=localhost", "SECRETHUB_CREDENTIAL=credential"},
+			envar:           map[string]string{},
+			templateVersion: "2",
+		},
+		secretFiles: map[string]string{
+			"TLS_KEY": "company/app/tls/key",
+		},
+		dryRun: true,
+		newClient: func() (secrethub.ClientInterface, error) {
+			return fakeclient.Client{
+				SecretService: &fakeclient.SecretService{
+					VersionService: &fakeclient.SecretVersionService{
+						WithDataGetter: fakeclient.WithDataGetter{
+							Err: errors.New("secrets must not be read"),
+						},
+						WithoutDataGetter: fakeclient.WithoutDataGetter{
+							ReturnsVersion: &api.SecretVersion{Version: 2},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	err := cmd.Run()
+	assert.OK(t, err)
+	assert.Equal(t, io.StdOut.String(), ""+
+		"NAME         SOURCE         SECRET  SECRET VERSIONS            OVERRIDES\n"+
+		"DB_HOST      secrethub.env  no      -                          os environment\n"+
+		"DB_PASSWORD  secrethub.env  yes     company/app/db/password:2  -\n"+
+		"TLS_KEY      --secret-file  yes     company/app/tls/key:2      -\n")
+}
+
 func TestRunCommand_RunWithFile(t *testing.T) {
 	readFileWithContent := func(content string) func(string) ([]byte, error) {
 		return func(_ string) ([]byte, error) {
@@ -1040,6 +1236,179 @@ func TestRunCommand_RunWithFile(t *testing.T) {
 			},
 			expectedStdOut: "bbb\n",
 		},
+		"--secret-file flag": {
+			script: "cat $TEST_FILE",
+			command: RunCommand{
+				command:   []string{"/bin/sh", "./test.sh"},
+				noMasking: true,
+				environment: &environment{
+					osStat:          osStatOnlySecretHubEnv,
+					readFile:        readFileWithContent(""),
+					envFile:         "secrethub.env",
+					templateVersion: "2",
+				},
+				secretFiles: map[string]string{
+					"TEST_FILE": "test/test/test",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte("bbb")},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedStdOut: "bbb",
+		},
+		"--load-credential flag": {
+			script: "cat $CREDENTIALS_DIRECTORY/db-password",
+			command: RunCommand{
+				command:   []string{"/bin/sh", "./test.sh"},
+				noMasking: true,
+				environment: &environment{
+					osStat:          osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile:        readFileWithContent(""),
+					templateVersion: "2",
+				},
+				loadCredentials: map[string]string{
+					"db-password": "test/test/test",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte("bbb")},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedStdOut: "bbb",
+		},
+		"--load-credential flag with invalid name": {
+			command: RunCommand{
+				command: []string{"/bin/sh", "./test.sh"},
+				environment: &environment{
+					osStat:          osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile:        readFileWithContent(""),
+					templateVersion: "2",
+				},
+				loadCredentials: map[string]string{
+					"../db-password": "test/test/test",
+				},
+			},
+			err: ErrInvalidCredentialName("../db-password"),
+		},
+		"--inject flag": {
+			script: "cat " + filepath.Join(os.TempDir(), "secrethub-test-inject.conf"),
+			command: RunCommand{
+				command:   []string{"/bin/sh", "./test.sh"},
+				noMasking: true,
+				environment: &environment{
+					osStat:          osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile:        readFileWithContent("password: {{ test/test/test }}"),
+					templateVersion: "2",
+				},
+				injects: []string{"app.conf.tpl:" + filepath.Join(os.TempDir(), "secrethub-test-inject.conf")},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte("bbb")},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedStdOut: "password: bbb\n",
+		},
+		"--inject flag without out file": {
+			command: RunCommand{
+				command: []string{"/bin/sh", "./test.sh"},
+				environment: &environment{
+					osStat:          osStatFunc("secrethub.env", os.ErrNotExist),
+					readFile:        readFileWithContent(""),
+					templateVersion: "2",
+				},
+				injects: []string{"app.conf.tpl"},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{}, nil
+				},
+			},
+			err: ErrInvalidInject("app.conf.tpl"),
+		},
+		"--tty flag": {
+			script: "echo $TEST; test -t 0 && test -t 1 && echo terminal",
+			command: RunCommand{
+				command: []string{"/bin/sh", "./test.sh"},
+				tty:     true,
+				environment: &environment{
+					osStat:   osStatOnlySecretHubEnv,
+					envFile:  "secrethub.env",
+					readFile: readFileWithContent(""),
+					envar: map[string]string{
+						"TEST": "test/test/test",
+					},
+					templateVersion: "2",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte("bbb")},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedStdOut: maskString + "\r\nterminal\r\n",
+		},
+		"--init flag": {
+			script: "echo $TEST",
+			command: RunCommand{
+				command:  []string{"/bin/sh", "./test.sh"},
+				initMode: true,
+				environment: &environment{
+					osStat:   osStatOnlySecretHubEnv,
+					envFile:  "secrethub.env",
+					readFile: readFileWithContent(""),
+					envar: map[string]string{
+						"TEST": "test/test/test",
+					},
+					templateVersion: "2",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte("bbb")},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedStdOut: maskString + "\n",
+		},
+		"--init flag with invalid signal map": {
+			command: RunCommand{
+				command:   []string{"/bin/sh", "./test.sh"},
+				initMode:  true,
+				signalMap: []string{"TERM"},
+			},
+			err: ErrInvalidSignalMap("TERM"),
+		},
 		"secret masking": {
 			script: "echo $TEST",
 			command: RunCommand{
@@ -1067,6 +1436,58 @@ func TestRunCommand_RunWithFile(t *testing.T) {
 			},
 			expectedStdOut: maskString + "\n",
 		},
+		"field masking": {
+			script: "echo $TEST",
+			command: RunCommand{
+				command: []string{"/bin/sh", "./test.sh"},
+				environment: &environment{
+					osStat:          osStatOnlySecretHubEnv,
+					envFile:         "secrethub.env",
+					readFile:        readFileWithContent(""),
+					osEnv:           []string{"TEST=secrethub://test/test/test#password"},
+					templateVersion: "2",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte(`{"user": "admin", "password": "bbb"}`)},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedStdOut: maskString + "\n",
+		},
+		"--secret-file field masking": {
+			script: "cat $TEST_FILE",
+			command: RunCommand{
+				command: []string{"/bin/sh", "./test.sh"},
+				environment: &environment{
+					osStat:          osStatOnlySecretHubEnv,
+					envFile:         "secrethub.env",
+					readFile:        readFileWithContent(""),
+					templateVersion: "2",
+				},
+				secretFiles: map[string]string{
+					"TEST_FILE": "test/test/test#password",
+				},
+				newClient: func() (secrethub.ClientInterface, error) {
+					return fakeclient.Client{
+						SecretService: &fakeclient.SecretService{
+							VersionService: &fakeclient.SecretVersionService{
+								WithDataGetter: fakeclient.WithDataGetter{
+									ReturnsVersion: &api.SecretVersion{Data: []byte(`{"user": "admin", "password": "bbb"}`)},
+								},
+							},
+						},
+					}, nil
+				},
+			},
+			expectedStdOut: maskString,
+		},
 	}
 
 	for name, tc := range cases {
@@ -1218,3 +1639,44 @@ func Test_parseKeyValueStringsToMap(t *testing.T) {
 		"=::=::\\",
 	})
 }
+
+func TestParseInjectFlag(t *testing.T) {
+	cases := map[string]struct {
+		in      string
+		inFile  string
+		outFile string
+		err     error
+	}{
+		"relative paths": {
+			in:      "app.conf.tpl:app.conf",
+			inFile:  "app.conf.tpl",
+			outFile: "app.conf",
+		},
+		"absolute paths": {
+			in:      "/etc/app/app.conf.tpl:/run/app/app.conf",
+			inFile:  "/etc/app/app.conf.tpl",
+			outFile: "/run/app/app.conf",
+		},
+		"no separator": {
+			in:  "app.conf.tpl",
+			err: ErrInvalidInject("app.conf.tpl"),
+		},
+		"no out file": {
+			in:  "app.conf.tpl:",
+			err: ErrInvalidInject("app.conf.tpl:"),
+		},
+		"no template": {
+			in:  ":app.conf",
+			err: ErrInvalidInject(":app.conf"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			inFile, outFile, err := parseInjectFlag(tc.in)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, inFile, tc.inFile)
+			assert.Equal(t, outFile, tc.outFile)
+		})
+	}
+}
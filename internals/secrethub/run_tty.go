@@ -0,0 +1,86 @@
+package secrethub
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/pty"
+)
+
+// terminalDrainTimeout is the maximum time to wait for the remaining output of the
+// pseudo-terminal after the command exits. Background processes started by the
+// command can keep the terminal open after the command has exited.
+const terminalDrainTimeout = 1 * time.Second
+
+// runTerminal is the pseudo-terminal the command is run in with --tty.
+// The input of the user is passed to it and its output is written to stdout.
+type runTerminal struct {
+	*pty.Terminal
+	restore    func() error
+	stopResize func()
+	outputDone chan struct{}
+}
+
+// openTerminal opens a pseudo-terminal for the command. When stdin is a terminal,
+// it is put in raw mode, so that all input, including control characters like
+// Ctrl-C, is passed to the command. The size of the pseudo-terminal follows the
+// size of the terminal of the user.
+func openTerminal(stdin *os.File, stdout io.Writer) (*runTerminal, error) {
+	terminal, err := pty.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &runTerminal{
+		Terminal:   terminal,
+		restore:    func() error { return nil },
+		stopResize: func() {},
+		outputDone: make(chan struct{}),
+	}
+
+	if pty.IsTerminal(stdin) {
+		_ = terminal.InheritSize(stdin)
+		t.stopResize = terminal.WatchSize(stdin)
+
+		t.restore, err = pty.MakeRaw(stdin)
+		if err != nil {
+			t.stopResize()
+			terminal.Close()
+			return nil, err
+		}
+	}
+
+	go func() {
+		_, _ = io.Copy(terminal, stdin)
+	}()
+
+	go func() {
+		_, _ = io.Copy(stdout, terminal)
+		close(t.outputDone)
+	}()
+
+	return t, nil
+}
+
+// close waits for the remaining output of the command, restores the terminal
+// of the user and closes the pseudo-terminal. It must be called before the
+// output is flushed.
+func (t *runTerminal) close() error {
+	err := t.CloseSlave()
+	select {
+	case <-t.outputDone:
+	case <-time.After(terminalDrainTimeout):
+	}
+
+	t.stopResize()
+	restoreErr := t.restore()
+	if err == nil {
+		err = restoreErr
+	}
+	closeErr := t.Terminal.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,329 @@
+package secrethub
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
+)
+
+// Errors
+var (
+	ErrCacheCredential             = errMain.Code("cache_credential").ErrorPref("could not load the credential to encrypt the secret cache: %s")
+	ErrCacheCredentialNotSupported = errMain.Code("cache_credential_not_supported").Error("the secret cache can only be encrypted with a key credential, either directly or through the agent, not with the aws identity provider")
+	ErrCacheNotConfigured          = errMain.Code("cache_not_configured").Error("no cache directory configured: set it with --cache-dir or the SECRETHUB_CACHE_DIR environment variable")
+	ErrReadCacheEntry              = errMain.Code("cache_entry_read_error").ErrorPref("could not read cache entry %s: %s")
+	ErrCacheEntryMismatch          = errMain.Code("cache_entry_mismatch").ErrorPref("cache entry %s does not contain %s")
+)
+
+const (
+	// cacheEntryExtension is the extension of the files in the cache directory.
+	cacheEntryExtension = ".json"
+	// cacheDirPerm is the file mode of the cache directory.
+	cacheDirPerm os.FileMode = 0700
+	// cacheEntryPerm is the file mode of the files in the cache directory.
+	cacheEntryPerm os.FileMode = 0600
+)
+
+// SecretCacheConfig configures the local cache of secrets that is used
+// when the SecretHub API cannot be reached.
+type SecretCacheConfig struct {
+	dir        string
+	ttl        time.Duration
+	cacheFirst bool
+	maxStale   time.Duration
+	loadKey    func() (credentials.Encrypter, credentials.Decrypter, error)
+}
+
+// NewSecretCacheConfig creates a new SecretCacheConfig. The cache is
+// encrypted with the key of the credential the client factory uses.
+func NewSecretCacheConfig(clientFactory ClientFactory) *SecretCacheConfig {
+	return &SecretCacheConfig{
+		loadKey: clientFactory.CacheKey,
+	}
+}
+
+// Register registers the flags for configuring the cache on the provided Registerer.
+func (c *SecretCacheConfig) Register(r FlagRegisterer) {
+	r.Flag("cache-dir", "Cache the secrets used by run and inject in this directory, encrypted with your account credential. When the SecretHub API cannot be reached, the cached secrets are used instead. Caching is disabled when no directory is set.").PlaceHolder("CACHE-DIR").StringVar(&c.dir)
+	r.Flag("cache-first", "Use cached secrets that are younger than the --cache-ttl without contacting the SecretHub API. By default, the cache is only used when the SecretHub API cannot be reached.").BoolVar(&c.cacheFirst)
+	r.Flag("cache-ttl", "The maximum age of a cached secret that is used without checking the SecretHub API for a newer version when --cache-first is set.").Default("0s").DurationVar(&c.ttl)
+	r.Flag("cache-max-stale", "The maximum age of a cached secret that is used when the SecretHub API cannot be reached. Set it to 0 to use cached secrets of any age.").Default("24h").DurationVar(&c.maxStale)
+}
+
+// cache returns the configured cache or nil when caching is disabled.
+// Warnings about the cache are written to the given writer.
+func (c *SecretCacheConfig) cache(warnings io.Writer) *secretCache {
+	if c == nil || c.dir == "" {
+		return nil
+	}
+
+	var ttl time.Duration
+	if c.cacheFirst {
+		ttl = c.ttl
+	}
+
+	return &secretCache{
+		dir:      c.dir,
+		ttl:      ttl,
+		maxStale: c.maxStale,
+		loadKey:  c.loadKey,
+		timeNow:  time.Now,
+		warnings: warnings,
+	}
+}
+
+// secretCache stores secret versions encrypted on disk. Only the encrypted
+// secrets are written to disk, decrypted values are only kept in memory,
+// which is locked when using the --mlock flag.
+type secretCache struct {
+	dir      string
+	ttl      time.Duration
+	maxStale time.Duration
+	loadKey  func() (credentials.Encrypter, credentials.Decrypter, error)
+	timeNow  func() time.Time
+	warnings io.Writer
+
+	keyOnce   sync.Once
+	keyErr    error
+	encrypter credentials.Encrypter
+	decrypter credentials.Decrypter
+}
+
+// cacheEntry is a secret version stored in the cache.
+type cacheEntry struct {
+	Path     string             `json:"path" yaml:"path"`
+	Version  int                `json:"version" yaml:"version"`
+	CachedAt time.Time          `json:"cached_at" yaml:"cached_at"`
+	Data     *api.EncryptedData `json:"data" yaml:"-"`
+}
+
+// GetWithData fetches the secret at the given path and caches it. When the API cannot
+// be reached, a cached version younger than the maximum staleness is returned and a
+// warning is printed. When a TTL is set, a cached version younger than the TTL is
+// returned without contacting the API.
+func (c *secretCache) GetWithData(versions secrethub.SecretVersionService, path string) (*api.SecretVersion, error) {
+	_, _, err := c.key()
+	if err != nil {
+		return versions.GetWithData(path)
+	}
+
+	entry, err := c.read(path)
+	if err != nil {
+		fmt.Fprintf(c.warnings, "Warning: ignoring the cache: %s\n", err)
+	}
+
+	if entry != nil && c.ttl > 0 && c.age(entry) < c.ttl {
+		secret, err := c.decrypt(entry)
+		if err == nil {
+			return secret, nil
+		}
+		fmt.Fprintf(c.warnings, "Warning: ignoring the cache: %s\n", err)
+	}
+
+	secret, err := versions.GetWithData(path)
+	if err == nil {
+		cacheErr := c.write(path, secret)
+		if cacheErr != nil {
+			fmt.Fprintf(c.warnings, "Warning: could not cache %s: %s\n", path, cacheErr)
+		}
+		return secret, nil
+	}
+
+	if !isNetworkError(err) || entry == nil || (c.maxStale > 0 && c.age(entry) > c.maxStale) {
+		return nil, err
+	}
+
+	secret, decryptErr := c.decrypt(entry)
+	if decryptErr != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(c.warnings, "Warning: could not reach the SecretHub API (%s), using version %d of %s cached at %s\n", err, entry.Version, path, entry.CachedAt.Local().Format(time.RFC3339))
+	return secret, nil
+}
+
+// List returns all entries in the cache, sorted by path.
+func (c *secretCache) List() ([]*cacheEntry, error) {
+	files, err := ioutil.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var entries []*cacheEntry
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != cacheEntryExtension {
+			continue
+		}
+
+		entry, err := c.readFile(filepath.Join(c.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+// Clear removes all entries from the cache and returns the number of removed entries.
+func (c *secretCache) Clear() (int, error) {
+	files, err := ioutil.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != cacheEntryExtension {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(c.dir, file.Name()))
+		if err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// age returns how long ago the entry was cached.
+func (c *secretCache) age(entry *cacheEntry) time.Duration {
+	return c.timeNow().Sub(entry.CachedAt)
+}
+
+// filename returns the file the secret at the given path is cached in.
+// The path is hashed so it can be used as a file name.
+func (c *secretCache) filename(path string) string {
+	digest := sha256.Sum256([]byte(path))
+	return filepath.Join(c.dir, hex.EncodeToString(digest[:])+cacheEntryExtension)
+}
+
+// read returns the cached entry for the given path or nil if it is not cached.
+func (c *secretCache) read(path string) (*cacheEntry, error) {
+	filename := c.filename(path)
+	entry, err := c.readFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if entry.Path != path {
+		return nil, ErrCacheEntryMismatch(filename, path)
+	}
+	return entry, nil
+}
+
+func (c *secretCache) readFile(filename string) (*cacheEntry, error) {
+	raw, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, err
+	} else if err != nil {
+		return nil, ErrReadCacheEntry(filename, err)
+	}
+
+	entry := &cacheEntry{}
+	err = json.Unmarshal(raw, entry)
+	if err != nil {
+		return nil, ErrReadCacheEntry(filename, err)
+	}
+	return entry, nil
+}
+
+// write encrypts the secret and stores it in the cache.
+func (c *secretCache) write(path string, secret *api.SecretVersion) error {
+	encrypter, _, err := c.key()
+	if err != nil {
+		return err
+	}
+
+	data, err := encrypter.Wrap(secret.Data)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(cacheEntry{
+		Path:     path,
+		Version:  secret.Version,
+		CachedAt: c.timeNow().UTC(),
+		Data:     data,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(c.dir, cacheDirPerm)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.filename(path), raw, cacheEntryPerm)
+}
+
+// decrypt returns the secret version stored in the entry.
+func (c *secretCache) decrypt(entry *cacheEntry) (*api.SecretVersion, error) {
+	_, decrypter, err := c.key()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decrypter.Unwrap(entry.Data)
+	if err != nil {
+		return nil, ErrReadCacheEntry(entry.Path, err)
+	}
+
+	return &api.SecretVersion{
+		Version: entry.Version,
+		Data:    data,
+	}, nil
+}
+
+// key loads the key to encrypt and decrypt the cache once. When the key cannot
+// be loaded, a single warning is printed and the cache is not used.
+func (c *secretCache) key() (credentials.Encrypter, credentials.Decrypter, error) {
+	c.keyOnce.Do(func() {
+		c.encrypter, c.decrypter, c.keyErr = c.loadKey()
+		if c.keyErr != nil && c.keyErr != ErrCacheCredentialNotSupported {
+			c.keyErr = ErrCacheCredential(c.keyErr)
+		}
+		if c.keyErr != nil {
+			fmt.Fprintf(c.warnings, "Warning: the secret cache is disabled: %s\n", c.keyErr)
+		}
+	})
+	return c.encrypter, c.decrypter, c.keyErr
+}
+
+// isNetworkError returns whether the error means that the SecretHub API could not be reached.
+func isNetworkError(err error) bool {
+	switch e := err.(type) {
+	case errio.PublicError:
+		return e.Namespace == "http" && (e.Code == "request_failed" || e.Code == "timeout")
+	case errio.PublicStatusError:
+		return e.StatusCode >= 500
+	case net.Error:
+		return true
+	}
+	return false
+}
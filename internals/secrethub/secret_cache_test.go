@@ -0,0 +1,218 @@
+package secrethub
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/internals/errio"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/credentials"
+)
+
+var errTestRequestFailed = errio.Namespace("http").Code("request_failed").ErrorPref("request to API server failed: %v")("connection refused")
+
+// failingSecretVersionService returns the configured secret version
+// or fails with the configured error when it is set.
+type failingSecretVersionService struct {
+	secrethub.SecretVersionService
+	secret *api.SecretVersion
+	err    error
+	calls  int
+}
+
+func (s *failingSecretVersionService) GetWithData(path string) (*api.SecretVersion, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.secret, nil
+}
+
+func newTestSecretCache(t *testing.T, dir string, now *time.Time) *secretCache {
+	key, err := credentials.GenerateRSACredential(1024)
+	assert.OK(t, err)
+
+	return &secretCache{
+		dir:      dir,
+		maxStale: time.Hour,
+		loadKey: func() (credentials.Encrypter, credentials.Decrypter, error) {
+			return key, key, nil
+		},
+		timeNow: func() time.Time {
+			return *now
+		},
+		warnings: &bytes.Buffer{},
+	}
+}
+
+func TestSecretCache_GetWithData(t *testing.T) {
+	cases := map[string]struct {
+		ttl        time.Duration
+		age        time.Duration
+		err        error
+		expected   *api.SecretVersion
+		expectErr  error
+		expectCall bool
+	}{
+		"fresh cache": {
+			ttl:      time.Minute,
+			age:      30 * time.Second,
+			err:      errTestRequestFailed,
+			expected: &api.SecretVersion{Version: 1, Data: []byte("cached")},
+		},
+		"fresh cache without ttl": {
+			age:        30 * time.Second,
+			expected:   &api.SecretVersion{Version: 2, Data: []byte("new")},
+			expectCall: true,
+		},
+		"expired cache": {
+			ttl:        time.Minute,
+			age:        2 * time.Minute,
+			expected:   &api.SecretVersion{Version: 2, Data: []byte("new")},
+			expectCall: true,
+		},
+		"network error": {
+			age:        2 * time.Minute,
+			err:        errTestRequestFailed,
+			expected:   &api.SecretVersion{Version: 1, Data: []byte("cached")},
+			expectCall: true,
+		},
+		"network error beyond max stale": {
+			age:        2 * time.Hour,
+			err:        errTestRequestFailed,
+			expectErr:  errTestRequestFailed,
+			expectCall: true,
+		},
+		"other error": {
+			age:        2 * time.Minute,
+			err:        api.ErrSecretNotFound,
+			expectErr:  api.ErrSecretNotFound,
+			expectCall: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "secrethub-cache")
+			assert.OK(t, err)
+			defer os.RemoveAll(dir)
+
+			now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+			cache := newTestSecretCache(t, dir, &now)
+			cache.ttl = tc.ttl
+
+			err = cache.write("namespace/repo/secret", &api.SecretVersion{Version: 1, Data: []byte("cached")})
+			assert.OK(t, err)
+
+			now = now.Add(tc.age)
+			versions := &failingSecretVersionService{
+				secret: &api.SecretVersion{Version: 2, Data: []byte("new")},
+				err:    tc.err,
+			}
+
+			actual, err := cache.GetWithData(versions, "namespace/repo/secret")
+
+			assert.Equal(t, err, tc.expectErr)
+			assert.Equal(t, actual, tc.expected)
+			assert.Equal(t, versions.calls > 0, tc.expectCall)
+		})
+	}
+}
+
+func TestSecretCache_GetWithData_PathMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-cache")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newTestSecretCache(t, dir, &now)
+	warnings := &bytes.Buffer{}
+	cache.warnings = warnings
+
+	err = cache.write("namespace/repo/other", &api.SecretVersion{Version: 1, Data: []byte("other")})
+	assert.OK(t, err)
+	err = os.Rename(cache.filename("namespace/repo/other"), cache.filename("namespace/repo/secret"))
+	assert.OK(t, err)
+
+	versions := &failingSecretVersionService{err: errTestRequestFailed}
+
+	actual, err := cache.GetWithData(versions, "namespace/repo/secret")
+
+	assert.Equal(t, err, errTestRequestFailed)
+	assert.Equal(t, actual, (*api.SecretVersion)(nil))
+	expected := "Warning: ignoring the cache: " + ErrCacheEntryMismatch(cache.filename("namespace/repo/secret"), "namespace/repo/secret").Error() + "\n"
+	assert.Equal(t, warnings.String(), expected)
+}
+
+func TestSecretCache_GetWithData_KeyNotSupported(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-cache")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newTestSecretCache(t, dir, &now)
+	warnings := &bytes.Buffer{}
+	cache.warnings = warnings
+	cache.loadKey = func() (credentials.Encrypter, credentials.Decrypter, error) {
+		return nil, nil, ErrCacheCredentialNotSupported
+	}
+
+	versions := &failingSecretVersionService{
+		secret: &api.SecretVersion{Version: 1, Data: []byte("secret")},
+	}
+
+	for _, path := range []string{"namespace/repo/foo", "namespace/repo/bar"} {
+		actual, err := cache.GetWithData(versions, path)
+		assert.OK(t, err)
+		assert.Equal(t, actual, versions.secret)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	assert.OK(t, err)
+	assert.Equal(t, len(files), 0)
+	assert.Equal(t, warnings.String(), "Warning: the secret cache is disabled: "+ErrCacheCredentialNotSupported.Error()+"\n")
+}
+
+func TestSecretCache_ListAndClear(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-cache")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newTestSecretCache(t, dir, &now)
+
+	versions := &failingSecretVersionService{
+		secret: &api.SecretVersion{Version: 3, Data: []byte("foo")},
+	}
+	for _, path := range []string{"namespace/repo/foo", "namespace/repo/bar"} {
+		_, err = cache.GetWithData(versions, path)
+		assert.OK(t, err)
+	}
+
+	entries, err := cache.List()
+	assert.OK(t, err)
+	assert.Equal(t, len(entries), 2)
+	assert.Equal(t, entries[0].Path, "namespace/repo/bar")
+	assert.Equal(t, entries[0].Version, 3)
+	assert.Equal(t, entries[0].CachedAt, now)
+	assert.Equal(t, entries[1].Path, "namespace/repo/foo")
+
+	files, err := ioutil.ReadDir(dir)
+	assert.OK(t, err)
+	for _, file := range files {
+		assert.Equal(t, file.Mode().Perm(), cacheEntryPerm)
+	}
+
+	removed, err := cache.Clear()
+	assert.OK(t, err)
+	assert.Equal(t, removed, 2)
+
+	entries, err = cache.List()
+	assert.OK(t, err)
+	assert.Equal(t, len(entries), 0)
+}
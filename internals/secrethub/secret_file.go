@@ -0,0 +1,254 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/posix"
+)
+
+// runFileContents contains the contents of the files that are written for the run command.
+type runFileContents struct {
+	// secrets are the secrets of the --secret-file flags by the name of their environment variable.
+	secrets map[string]string
+	// templates are the rendered templates of the --inject flags by their output path.
+	templates map[string]string
+	// credentials are the secrets of the --load-credential flags by the name of their credential.
+	credentials map[string]string
+	// inheritedCredentialsDir is the $CREDENTIALS_DIRECTORY of this process, of which
+	// the credentials are also made available to the command.
+	inheritedCredentialsDir string
+}
+
+// runFiles are the files that are written for the run command. They only exist while the command runs.
+type runFiles struct {
+	// env contains a NAME=path pair for every secret file.
+	env []string
+	// extraFiles are the anonymous in-memory secret files that are passed to the command.
+	extraFiles []*os.File
+	// dir is the private directory that contains the secret files that are not in memory.
+	dir string
+	// rendered are the paths of the rendered templates.
+	rendered []string
+	// credentialsDir is the private directory that contains the credential files.
+	credentialsDir string
+}
+
+// newRunFiles writes the files for the run command. Every secret is written to a
+// file that can only be read by the user, preferring anonymous in-memory files when
+// the system supports them. The names of the environment variables with the paths to
+// the secret files are the keys of the secrets. The templates are written to their
+// output path with the given file mode and must not overwrite existing files.
+// The files are removed when close is called, also when an error is returned.
+func newRunFiles(contents runFileContents, fileMode os.FileMode) (*runFiles, error) {
+	files := &runFiles{}
+	for _, name := range sortedKeys(contents.secrets) {
+		path, err := files.create(name, []byte(contents.secrets[name]))
+		if err != nil {
+			files.close()
+			return nil, ErrCannotWriteSecretFile(name, err)
+		}
+		files.env = append(files.env, name+"="+path)
+	}
+
+	for _, path := range sortedKeys(contents.templates) {
+		err := files.render(path, []byte(contents.templates[path]), fileMode)
+		if err != nil {
+			files.close()
+			return nil, err
+		}
+	}
+
+	if len(contents.credentials) > 0 {
+		err := files.createCredentials(contents.credentials, contents.inheritedCredentialsDir)
+		if err != nil {
+			files.close()
+			return nil, ErrCannotWriteCredentials(err)
+		}
+		files.env = append(files.env, credentialsDirectoryEnvVar+"="+files.credentialsDir)
+	}
+	return files, nil
+}
+
+// createCredentials writes the credentials to files in a new private directory, which can
+// only be read by the user, like systemd does for LoadCredential=. The credentials in the
+// inherited directory are linked to in the new directory, unless they are overridden.
+func (f *runFiles) createCredentials(credentials map[string]string, inheritedDir string) error {
+	var err error
+	f.credentialsDir, err = ioutil.TempDir(secretFileDir(), "secrethub-credentials-")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range sortedKeys(credentials) {
+		err = ioutil.WriteFile(filepath.Join(f.credentialsDir, name), []byte(credentials[name]), 0400)
+		if err != nil {
+			return err
+		}
+	}
+
+	if inheritedDir == "" {
+		return nil
+	}
+	inherited, err := ioutil.ReadDir(inheritedDir)
+	if err != nil {
+		return err
+	}
+	for _, file := range inherited {
+		if _, ok := credentials[file.Name()]; ok {
+			continue
+		}
+		err = os.Symlink(filepath.Join(inheritedDir, file.Name()), filepath.Join(f.credentialsDir, file.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render writes a rendered template to a file that does not exist yet.
+func (f *runFiles) render(path string, rendered []byte, fileMode os.FileMode) error {
+	_, err := os.Lstat(path)
+	if err == nil {
+		return ErrInjectFileExists(path)
+	}
+
+	err = writeFileAtomic(path, posix.AddNewLine(rendered), fileMode)
+	if err != nil {
+		return ErrCannotWrite(path, err)
+	}
+	f.rendered = append(f.rendered, path)
+	return nil
+}
+
+// create writes the secret to a new file and returns the path at which the command can read it.
+func (f *runFiles) create(name string, secret []byte) (string, error) {
+	file, ok, err := newMemoryFile(name, secret)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		f.extraFiles = append(f.extraFiles, file)
+		// The extra files of a command get the file descriptors after stdin, stdout and stderr.
+		return memoryFilePath(2 + len(f.extraFiles)), nil
+	}
+
+	if f.dir == "" {
+		f.dir, err = ioutil.TempDir(secretFileDir(), "secrethub-")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	path := filepath.Join(f.dir, name)
+	err = ioutil.WriteFile(path, secret, 0400)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// close removes all files. The rendered templates are overwritten with
+// zeros before they are removed, as they are regular files that can end
+// up on disk. Note that this is a best effort, as file systems can keep
+// copies of the old contents of a file.
+func (f *runFiles) close() error {
+	if f == nil {
+		return nil
+	}
+
+	var err error
+	for _, path := range f.rendered {
+		shredErr := shredFile(path)
+		if err == nil {
+			err = shredErr
+		}
+	}
+	f.rendered = nil
+
+	for _, file := range f.extraFiles {
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+	f.extraFiles = nil
+
+	if f.dir != "" {
+		removeErr := os.RemoveAll(f.dir)
+		if err == nil {
+			err = removeErr
+		}
+		f.dir = ""
+	}
+
+	if f.credentialsDir != "" {
+		removeErr := os.RemoveAll(f.credentialsDir)
+		if err == nil {
+			err = removeErr
+		}
+		f.credentialsDir = ""
+	}
+	return err
+}
+
+// shredFile overwrites the file with zeros and removes it.
+func shredFile(path string) error {
+	// The file can have been written with a read-only file mode.
+	_ = os.Chmod(path, 0600)
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err == nil {
+		var info os.FileInfo
+		info, err = file.Stat()
+		if err == nil {
+			_, err = file.Write(make([]byte, info.Size()))
+		}
+		if err == nil {
+			err = file.Sync()
+		}
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+
+	removeErr := os.Remove(path)
+	if err == nil && !os.IsNotExist(removeErr) {
+		err = removeErr
+	}
+	return err
+}
+
+// sortedKeys returns the keys of the map in lexical order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// credentialsDirectoryEnvVar is the environment variable with the path to the
+// directory with credentials, as set by systemd for LoadCredential=.
+const credentialsDirectoryEnvVar = "CREDENTIALS_DIRECTORY"
+
+// secretFileDir returns the directory to create the private directory with secret files in.
+// Directories that are usually on tmpfs are preferred, so the secrets are never written to disk.
+func secretFileDir() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir != "" {
+		return dir
+	}
+
+	info, err := os.Stat(sharedMemoryDir)
+	if err == nil && info.IsDir() {
+		return sharedMemoryDir
+	}
+	return os.TempDir()
+}
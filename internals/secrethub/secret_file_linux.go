@@ -0,0 +1,60 @@
+package secrethub
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// sharedMemoryDir is the tmpfs directory that is used for secret files when
+// memfd is not available and $XDG_RUNTIME_DIR is not set.
+const sharedMemoryDir = "/dev/shm"
+
+// newMemoryFile writes the secret to an anonymous in-memory file (memfd) that
+// can only be read by the user and is sealed against changes. The file exists
+// until the last file descriptor referring to it is closed. It returns false
+// when the kernel does not support memfd.
+func newMemoryFile(name string, secret []byte) (*os.File, bool, error) {
+	fd, err := unix.MemfdCreate("secrethub-"+name, unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err == unix.ENOSYS || err == unix.EPERM || err == unix.EINVAL {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	file := os.NewFile(uintptr(fd), "memfd:secrethub-"+name)
+
+	err = writeMemoryFile(file, secret)
+	if err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	return file, true, nil
+}
+
+// writeMemoryFile writes the secret to the in-memory file, makes it read-only
+// and seals it, so that it cannot be changed anymore.
+func writeMemoryFile(file *os.File, secret []byte) error {
+	_, err := file.Write(secret)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Seek(0, 0)
+	if err != nil {
+		return err
+	}
+
+	err = file.Chmod(0400)
+	if err != nil {
+		return err
+	}
+
+	_, err = unix.FcntlInt(file.Fd(), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL)
+	return err
+}
+
+// memoryFilePath returns the path at which a process can read its file descriptor.
+func memoryFilePath(fd int) string {
+	return fmt.Sprintf("/dev/fd/%d", fd)
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+// +build !linux
+
+package secrethub
+
+import (
+	"fmt"
+	"os"
+)
+
+// sharedMemoryDir is the tmpfs directory that is used for secret files
+// when $XDG_RUNTIME_DIR is not set, if it exists.
+const sharedMemoryDir = "/dev/shm"
+
+// newMemoryFile returns false, as anonymous in-memory files (memfd) only exist on Linux.
+func newMemoryFile(name string, secret []byte) (*os.File, bool, error) {
+	return nil, false, nil
+}
+
+// memoryFilePath returns the path at which a process can read its file descriptor.
+// It is never used, as newMemoryFile never creates a file.
+func memoryFilePath(fd int) string {
+	return fmt.Sprintf("/dev/fd/%d", fd)
+}
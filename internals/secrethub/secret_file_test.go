@@ -0,0 +1,166 @@
+package secrethub
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestRunFiles_Secrets(t *testing.T) {
+	files, err := newRunFiles(runFileContents{
+		secrets: map[string]string{
+			"TLS_CERT": "certificate",
+			"TLS_KEY":  "key",
+		},
+	}, 0600)
+	assert.OK(t, err)
+	defer files.close()
+
+	assert.Equal(t, len(files.env), 2)
+	assert.Equal(t, strings.HasPrefix(files.env[0], "TLS_CERT="), true)
+	assert.Equal(t, strings.HasPrefix(files.env[1], "TLS_KEY="), true)
+
+	// Files that are not in memory are read at their path, in memory files by their file descriptor.
+	var paths []string
+	for _, file := range files.extraFiles {
+		paths = append(paths, file.Name())
+		content, err := ioutil.ReadAll(file)
+		assert.OK(t, err)
+		assert.Equal(t, len(content) > 0, true)
+
+		info, err := file.Stat()
+		assert.OK(t, err)
+		assert.Equal(t, info.Mode().Perm(), os.FileMode(0400))
+
+		_, err = file.Write([]byte("changed"))
+		assert.Equal(t, err != nil, true)
+	}
+	if files.dir != "" {
+		for _, envvar := range files.env {
+			path := strings.SplitN(envvar, "=", 2)[1]
+			paths = append(paths, path)
+
+			info, err := os.Stat(path)
+			assert.OK(t, err)
+			assert.Equal(t, info.Mode().Perm(), os.FileMode(0400))
+		}
+	}
+	assert.Equal(t, len(paths), 2)
+
+	dir := files.dir
+	extraFiles := files.extraFiles
+	err = files.close()
+	assert.OK(t, err)
+
+	if dir != "" {
+		_, err = os.Stat(dir)
+		assert.Equal(t, os.IsNotExist(err), true)
+	}
+	for _, file := range extraFiles {
+		_, err = file.Stat()
+		assert.Equal(t, err != nil, true)
+	}
+}
+
+func TestRunFiles_Templates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-run-files")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.conf")
+	files, err := newRunFiles(runFileContents{
+		templates: map[string]string{
+			path: "password: secret",
+		},
+	}, 0400)
+	assert.OK(t, err)
+	defer files.close()
+
+	content, err := ioutil.ReadFile(path)
+	assert.OK(t, err)
+	assert.Equal(t, string(content), "password: secret\n")
+
+	info, err := os.Stat(path)
+	assert.OK(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0400))
+
+	err = files.close()
+	assert.OK(t, err)
+
+	_, err = os.Stat(path)
+	assert.Equal(t, os.IsNotExist(err), true)
+}
+
+func TestRunFiles_TemplateExists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrethub-run-files")
+	assert.OK(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.conf")
+	err = ioutil.WriteFile(path, []byte("existing"), 0600)
+	assert.OK(t, err)
+
+	_, err = newRunFiles(runFileContents{
+		templates: map[string]string{
+			path: "password: secret",
+		},
+	}, 0600)
+	assert.Equal(t, err, ErrInjectFileExists(path))
+
+	// The existing file must not be touched.
+	content, err := ioutil.ReadFile(path)
+	assert.OK(t, err)
+	assert.Equal(t, string(content), "existing")
+}
+
+func TestRunFiles_Credentials(t *testing.T) {
+	inherited, err := ioutil.TempDir("", "secrethub-credentials")
+	assert.OK(t, err)
+	defer os.RemoveAll(inherited)
+
+	err = ioutil.WriteFile(filepath.Join(inherited, "tls-key"), []byte("inherited key"), 0400)
+	assert.OK(t, err)
+	err = ioutil.WriteFile(filepath.Join(inherited, "db-password"), []byte("inherited password"), 0400)
+	assert.OK(t, err)
+
+	files, err := newRunFiles(runFileContents{
+		credentials: map[string]string{
+			"db-password": "password",
+		},
+		inheritedCredentialsDir: inherited,
+	}, 0600)
+	assert.OK(t, err)
+	defer files.close()
+
+	assert.Equal(t, files.env, []string{credentialsDirectoryEnvVar + "=" + files.credentialsDir})
+
+	info, err := os.Stat(files.credentialsDir)
+	assert.OK(t, err)
+	assert.Equal(t, info.Mode().Perm(), os.FileMode(0700))
+
+	// Inherited credentials are overridden by the credentials with the same name.
+	expected := map[string]string{
+		"db-password": "password",
+		"tls-key":     "inherited key",
+	}
+	for name, value := range expected {
+		content, err := ioutil.ReadFile(filepath.Join(files.credentialsDir, name))
+		assert.OK(t, err)
+		assert.Equal(t, string(content), value)
+	}
+
+	dir := files.credentialsDir
+	err = files.close()
+	assert.OK(t, err)
+
+	_, err = os.Stat(dir)
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	// The inherited credentials are not removed.
+	_, err = os.Stat(filepath.Join(inherited, "tls-key"))
+	assert.OK(t, err)
+}
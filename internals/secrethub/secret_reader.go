@@ -1,39 +1,179 @@
 package secrethub
 
 import (
+	"sync"
+
 	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl"
 	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
 )
 
-type secretReader struct {
-	newClient newClientFunc
+// secretFetchConcurrency is the maximum number of secrets that are fetched at the same time.
+const secretFetchConcurrency = 8
+
+// fetchResult is the result of fetching a secret version.
+type fetchResult struct {
+	secret *api.SecretVersion
+	err    error
+}
+
+type cachingSecretReader struct {
+	newClient   newClientFunc
+	cache       *secretCache
+	concurrency int
+	mutex       sync.Mutex
+	results     map[string]fetchResult
+}
+
+// newCachingSecretReader returns a secret reader that fetches every secret only once.
+// Secrets can be fetched concurrently in advance with the Prefetch function.
+// When the given cache is not nil, fetched secrets are stored in it and read
+// from it when the API cannot be reached.
+func newCachingSecretReader(newClient newClientFunc, cache *secretCache) *cachingSecretReader {
+	return &cachingSecretReader{
+		newClient:   newClient,
+		cache:       cache,
+		concurrency: secretFetchConcurrency,
+		results:     make(map[string]fetchResult),
+	}
 }
 
-// newSecretReader wraps a client to implement tpl.SecretReader.
-func newSecretReader(newClient newClientFunc) *secretReader {
-	return &secretReader{
-		newClient: newClient,
+// ReadSecret returns the secret from the cache or otherwise fetches it with the provided client.
+func (sr *cachingSecretReader) ReadSecret(path string) (string, error) {
+	result, ok := sr.result(path)
+	if !ok {
+		client, err := sr.newClient()
+		if err != nil {
+			return "", err
+		}
+		result = sr.fetch(client, path)
 	}
+
+	if result.err != nil {
+		return "", result.err
+	}
+	return string(result.secret.Data), nil
 }
 
-// ReadSecret reads the secret using the provided client.
-func (sr secretReader) ReadSecret(path string) (string, error) {
+// Prefetch fetches the given secrets that are not in the cache yet with a bounded
+// number of concurrent requests. Errors for individual secrets are cached and returned
+// when the secret is read.
+func (sr *cachingSecretReader) Prefetch(paths []string) error {
+	seen := make(map[string]bool, len(paths))
+	var todo []string
+	for _, path := range paths {
+		_, cached := sr.result(path)
+		if cached || seen[path] {
+			continue
+		}
+		seen[path] = true
+		todo = append(todo, path)
+	}
+
+	if len(todo) == 0 {
+		return nil
+	}
+
 	client, err := sr.newClient()
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	// The first secret is fetched before the others, so the client has
+	// cached the account key before it is used concurrently.
+	sr.fetch(client, todo[0])
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < sr.concurrency && i < len(todo)-1; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				sr.fetch(client, path)
+			}
+		}()
 	}
 
-	secret, err := client.Secrets().Versions().GetWithData(path)
+	for _, path := range todo[1:] {
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+
+	return nil
+}
+
+// Versions returns the versions of the secrets read with this secret reader, by path.
+func (sr *cachingSecretReader) Versions() map[string]int {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	versions := make(map[string]int, len(sr.results))
+	for path, result := range sr.results {
+		if result.err == nil {
+			versions[path] = result.secret.Version
+		}
+	}
+	return versions
+}
+
+func (sr *cachingSecretReader) result(path string) (fetchResult, bool) {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	result, ok := sr.results[path]
+	return result, ok
+}
+
+func (sr *cachingSecretReader) fetch(client secrethub.ClientInterface, path string) fetchResult {
+	var secret *api.SecretVersion
+	var err error
+	if sr.cache != nil {
+		secret, err = sr.cache.GetWithData(client.Secrets().Versions(), path)
+	} else {
+		secret, err = client.Secrets().Versions().GetWithData(path)
+	}
+	result := fetchResult{
+		secret: secret,
+		err:    err,
+	}
+
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+	sr.results[path] = result
+
+	return result
+}
+
+// secretVersionsChanged returns whether the latest version of any of the given secrets
+// differs from the given version. A secret that does not exist has version 0.
+func secretVersionsChanged(newClient newClientFunc, versions map[string]int) (bool, error) {
+	client, err := newClient()
 	if err != nil {
-		return "", err
+		return false, err
 	}
 
-	return string(secret.Data), nil
+	for path, version := range versions {
+		latest := 0
+		secret, err := client.Secrets().Versions().GetWithoutData(path)
+		if err == nil {
+			latest = secret.Version
+		} else if !api.IsErrNotFound(err) {
+			return false, err
+		}
+
+		if latest != version {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 type bufferedSecretReader struct {
 	secretReader tpl.SecretReader
 	secretsRead  []string
+	pathsRead    []string
 }
 
 // newBufferedSecretReader wraps a secret reader and stores the retrieved
@@ -52,22 +192,34 @@ func (sr *bufferedSecretReader) ReadSecret(path string) (string, error) {
 
 	if err == nil {
 		sr.secretsRead = append(sr.secretsRead, secret)
+		sr.pathsRead = append(sr.pathsRead, path)
 	}
 
 	return secret, err
 }
 
+// RecordValue stores a value that is derived from a secret, such as the result
+// of a template filter, for retrieval with the Values function.
+func (sr *bufferedSecretReader) RecordValue(value string) {
+	sr.secretsRead = append(sr.secretsRead, value)
+}
+
 type secretReaderNotAllowed struct{}
 
 func (sr secretReaderNotAllowed) ReadSecret(path string) (string, error) {
 	return "", ErrSecretsNotAllowedInKey
 }
 
-// Values returns a list of values read with this secret reader.
+// Values returns a list of values read with this secret reader, including the recorded values derived from them.
 func (sr bufferedSecretReader) Values() []string {
 	return sr.secretsRead
 }
 
+// Paths returns a list of the paths of the secrets read with this secret reader.
+func (sr bufferedSecretReader) Paths() []string {
+	return sr.pathsRead
+}
+
 type ignoreMissingSecretReader struct {
 	secretReader tpl.SecretReader
 }
@@ -0,0 +1,143 @@
+package secrethub
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+	"github.com/secrethub/secrethub-go/pkg/secrethub"
+	"github.com/secrethub/secrethub-go/pkg/secrethub/fakeclient"
+)
+
+func TestSecretVersionsChanged(t *testing.T) {
+	testErr := errors.New("test error")
+
+	cases := map[string]struct {
+		versions map[string]int
+		latest   *api.SecretVersion
+		getErr   error
+		expected bool
+		err      error
+	}{
+		"unchanged": {
+			versions: map[string]int{"namespace/repo/secret": 1},
+			latest:   &api.SecretVersion{Version: 1},
+			expected: false,
+		},
+		"new version": {
+			versions: map[string]int{"namespace/repo/secret": 1},
+			latest:   &api.SecretVersion{Version: 2},
+			expected: true,
+		},
+		"missing secret unchanged": {
+			versions: map[string]int{"namespace/repo/secret": 0},
+			getErr:   api.ErrSecretNotFound,
+			expected: false,
+		},
+		"secret deleted": {
+			versions: map[string]int{"namespace/repo/secret": 1},
+			getErr:   api.ErrSecretNotFound,
+			expected: true,
+		},
+		"get error": {
+			versions: map[string]int{"namespace/repo/secret": 1},
+			getErr:   testErr,
+			err:      testErr,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			newClient := func() (secrethub.ClientInterface, error) {
+				return fakeclient.Client{
+					SecretService: &fakeclient.SecretService{
+						VersionService: &fakeclient.SecretVersionService{
+							WithoutDataGetter: fakeclient.WithoutDataGetter{
+								ReturnsVersion: tc.latest,
+								Err:            tc.getErr,
+							},
+						},
+					},
+				}, nil
+			}
+
+			actual, err := secretVersionsChanged(newClient, tc.versions)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+// countingSecretVersionService returns a version with the path as data for
+// every secret in secrets and counts the requests per path.
+type countingSecretVersionService struct {
+	secrethub.SecretVersionService
+	secrets map[string]int
+	mutex   sync.Mutex
+	calls   map[string]int
+}
+
+func (s *countingSecretVersionService) GetWithData(path string) (*api.SecretVersion, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls[path]++
+
+	version, ok := s.secrets[path]
+	if !ok {
+		return nil, api.ErrSecretNotFound
+	}
+	return &api.SecretVersion{Version: version, Data: []byte(path)}, nil
+}
+
+func TestCachingSecretReader(t *testing.T) {
+	versionService := &countingSecretVersionService{
+		secrets: map[string]int{
+			"namespace/repo/foo": 1,
+			"namespace/repo/bar": 2,
+			"namespace/repo/baz": 3,
+		},
+		calls: map[string]int{},
+	}
+	newClient := func() (secrethub.ClientInterface, error) {
+		return fakeclient.Client{
+			SecretService: &fakeclient.SecretService{
+				VersionService: versionService,
+			},
+		}, nil
+	}
+
+	sr := newCachingSecretReader(newClient, nil)
+	sr.concurrency = 2
+
+	err := sr.Prefetch([]string{
+		"namespace/repo/foo",
+		"namespace/repo/bar",
+		"namespace/repo/foo",
+		"namespace/repo/missing",
+	})
+	assert.OK(t, err)
+
+	for _, path := range []string{"namespace/repo/foo", "namespace/repo/bar", "namespace/repo/baz", "namespace/repo/baz"} {
+		actual, err := sr.ReadSecret(path)
+		assert.OK(t, err)
+		assert.Equal(t, actual, path)
+	}
+
+	_, err = sr.ReadSecret("namespace/repo/missing")
+	assert.Equal(t, err, api.ErrSecretNotFound)
+
+	assert.Equal(t, versionService.calls, map[string]int{
+		"namespace/repo/foo":     1,
+		"namespace/repo/bar":     1,
+		"namespace/repo/baz":     1,
+		"namespace/repo/missing": 1,
+	})
+	assert.Equal(t, sr.Versions(), map[string]int{
+		"namespace/repo/foo": 1,
+		"namespace/repo/bar": 2,
+		"namespace/repo/baz": 3,
+	})
+}
@@ -9,21 +9,23 @@ import (
 type ServiceCommand struct {
 	io        ui.IO
 	newClient newClientFunc
+	output    *OutputConfig
 }
 
 // NewServiceCommand creates a new ServiceCommand.
-func NewServiceCommand(io ui.IO, newClient newClientFunc) *ServiceCommand {
+func NewServiceCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *ServiceCommand {
 	return &ServiceCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
 // Register registers the command and its sub-commands on the provided Registerer.
 func (cmd *ServiceCommand) Register(r command.Registerer) {
 	clause := r.Command("service", "Manage service accounts.")
-	NewServiceAWSCommand(cmd.io, cmd.newClient).Register(clause)
+	NewServiceAWSCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 	NewServiceDeployCommand(cmd.io).Register(clause)
 	NewServiceInitCommand(cmd.io, cmd.newClient).Register(clause)
-	NewServiceLsCommand(cmd.io, cmd.newClient).Register(clause)
+	NewServiceLsCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 }
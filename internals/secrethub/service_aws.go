@@ -9,13 +9,15 @@ import (
 type ServiceAWSCommand struct {
 	io        ui.IO
 	newClient newClientFunc
+	output    *OutputConfig
 }
 
 // NewServiceAWSCommand creates a new ServiceAWSCommand.
-func NewServiceAWSCommand(io ui.IO, newClient newClientFunc) *ServiceAWSCommand {
+func NewServiceAWSCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *ServiceAWSCommand {
 	return &ServiceAWSCommand{
 		io:        io,
 		newClient: newClient,
+		output:    output,
 	}
 }
 
@@ -23,5 +25,5 @@ func NewServiceAWSCommand(io ui.IO, newClient newClientFunc) *ServiceAWSCommand
 func (cmd *ServiceAWSCommand) Register(r command.Registerer) {
 	clause := r.Command("aws", "Manage AWS service accounts.")
 	NewServiceAWSInitCommand(cmd.io, cmd.newClient).Register(clause)
-	NewServiceAWSLsCommand(cmd.io, cmd.newClient).Register(clause)
+	NewServiceAWSLsCommand(cmd.io, cmd.newClient, cmd.output).Register(clause)
 }
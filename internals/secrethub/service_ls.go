@@ -2,8 +2,7 @@ package secrethub
 
 import (
 	"fmt"
-	"strings"
-	"text/tabwriter"
+	"time"
 
 	"github.com/secrethub/secrethub-cli/internals/cli/ui"
 	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
@@ -22,19 +21,21 @@ type ServiceLsCommand struct {
 	newServiceTable func(t TimeFormatter) serviceTable
 	filters         []func(service *api.Service) bool
 	help            string
+	output          *OutputConfig
 }
 
 // NewServiceLsCommand creates a new ServiceLsCommand.
-func NewServiceLsCommand(io ui.IO, newClient newClientFunc) *ServiceLsCommand {
+func NewServiceLsCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *ServiceLsCommand {
 	return &ServiceLsCommand{
 		io:              io,
 		newClient:       newClient,
 		newServiceTable: newKeyServiceTable,
 		help:            "List all service accounts in a given repository.",
+		output:          output,
 	}
 }
 
-func NewServiceAWSLsCommand(io ui.IO, newClient newClientFunc) *ServiceLsCommand {
+func NewServiceAWSLsCommand(io ui.IO, newClient newClientFunc, output *OutputConfig) *ServiceLsCommand {
 	return &ServiceLsCommand{
 		io:              io,
 		newClient:       newClient,
@@ -42,7 +43,8 @@ func NewServiceAWSLsCommand(io ui.IO, newClient newClientFunc) *ServiceLsCommand
 		filters: []func(service *api.Service) bool{
 			isAWSService,
 		},
-		help: "List all AWS service accounts in a given repository.",
+		help:   "List all AWS service accounts in a given repository.",
+		output: output,
 	}
 }
 
@@ -85,23 +87,40 @@ outer:
 		for _, service := range included {
 			fmt.Fprintf(cmd.io.Stdout(), "%s\n", service.ServiceID)
 		}
-	} else {
-		w := tabwriter.NewWriter(cmd.io.Stdout(), 0, 2, 2, ' ', 0)
-		serviceTable := cmd.newServiceTable(NewTimeFormatter(cmd.useTimestamps))
+		return nil
+	}
 
-		fmt.Fprintln(w, strings.Join(serviceTable.header(), "\t"))
+	serviceTable := cmd.newServiceTable(NewTimeFormatter(cmd.useTimestamps))
+	table := newOutputTable(2, serviceTable.header()...)
+	for _, service := range included {
+		table.add(newServiceOutput(service), serviceTable.row(service)...)
+	}
 
-		for _, service := range included {
-			fmt.Fprintln(w, strings.Join(serviceTable.row(service), "\t"))
-		}
+	return cmd.output.printTable(cmd.io.Stdout(), table)
+}
 
-		err = w.Flush()
-		if err != nil {
-			return err
-		}
-	}
+// serviceOutput is the structured output format of a listed service account.
+type serviceOutput struct {
+	ID          string    `json:"id" yaml:"id"`
+	Description string    `json:"description" yaml:"description"`
+	Type        string    `json:"type" yaml:"type"`
+	Role        string    `json:"role,omitempty" yaml:"role,omitempty"`
+	KMSKey      string    `json:"kms_key,omitempty" yaml:"kms_key,omitempty"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+}
 
-	return nil
+func newServiceOutput(service *api.Service) serviceOutput {
+	out := serviceOutput{
+		ID:          service.ServiceID,
+		Description: service.Description,
+		CreatedAt:   service.CreatedAt,
+	}
+	if service.Credential != nil {
+		out.Type = string(service.Credential.Type)
+		out.Role = service.Credential.Metadata[api.CredentialMetadataAWSRole]
+		out.KMSKey = service.Credential.Metadata[api.CredentialMetadataAWSKMSKey]
+	}
+	return out
 }
 
 type serviceTable interface {
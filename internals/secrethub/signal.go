@@ -0,0 +1,26 @@
+package secrethub
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// parseSignal returns the signal with the given name, e.g. SIGTERM or TERM, or number.
+func parseSignal(name string) (syscall.Signal, error) {
+	number, err := strconv.Atoi(name)
+	if err == nil && number > 0 {
+		return syscall.Signal(number), nil
+	}
+
+	normalized := strings.ToUpper(name)
+	if !strings.HasPrefix(normalized, "SIG") {
+		normalized = "SIG" + normalized
+	}
+
+	signal, ok := signalsByName[normalized]
+	if !ok {
+		return 0, ErrUnknownSignal(name)
+	}
+	return signal, nil
+}
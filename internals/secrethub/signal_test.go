@@ -0,0 +1,46 @@
+package secrethub
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestParseSignal(t *testing.T) {
+	cases := map[string]struct {
+		name     string
+		expected syscall.Signal
+		err      error
+	}{
+		"full name": {
+			name:     "SIGTERM",
+			expected: syscall.SIGTERM,
+		},
+		"without prefix": {
+			name:     "TERM",
+			expected: syscall.SIGTERM,
+		},
+		"lowercase": {
+			name:     "sigint",
+			expected: syscall.SIGINT,
+		},
+		"number": {
+			name:     "9",
+			expected: syscall.Signal(9),
+		},
+		"unknown": {
+			name: "SIGFOO",
+			err:  ErrUnknownSignal("SIGFOO"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseSignal(tc.name)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package secrethub
+
+import "syscall"
+
+// signalsByName contains the signals that can be configured by name.
+var signalsByName = map[string]syscall.Signal{
+	"SIGABRT":  syscall.SIGABRT,
+	"SIGALRM":  syscall.SIGALRM,
+	"SIGHUP":   syscall.SIGHUP,
+	"SIGINT":   syscall.SIGINT,
+	"SIGKILL":  syscall.SIGKILL,
+	"SIGQUIT":  syscall.SIGQUIT,
+	"SIGTERM":  syscall.SIGTERM,
+	"SIGUSR1":  syscall.SIGUSR1,
+	"SIGUSR2":  syscall.SIGUSR2,
+	"SIGWINCH": syscall.SIGWINCH,
+}
@@ -0,0 +1,24 @@
+package secrethub
+
+import (
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// SystemdCommand handles the integration with systemd.
+type SystemdCommand struct {
+	io ui.IO
+}
+
+// NewSystemdCommand creates a new SystemdCommand.
+func NewSystemdCommand(io ui.IO) *SystemdCommand {
+	return &SystemdCommand{
+		io: io,
+	}
+}
+
+// Register registers the command and its sub-commands on the provided Registerer.
+func (cmd *SystemdCommand) Register(r command.Registerer) {
+	clause := r.Command("systemd", "Run commands with secrets as systemd services.")
+	NewSystemdUnitCommand(cmd.io).Register(clause)
+}
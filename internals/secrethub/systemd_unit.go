@@ -0,0 +1,294 @@
+package secrethub
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+	"github.com/secrethub/secrethub-cli/internals/secrethub/command"
+)
+
+// Errors
+var (
+	ErrMissingUnitVars = errMain.Code("missing_unit_vars").ErrorPref("the env file uses template variables without a value: %s. Define them with --var or SECRETHUB_VAR_ environment variables")
+)
+
+// The service types of the units that can be generated.
+const (
+	systemdTypeSimple = "simple"
+	systemdTypeExec   = "exec"
+	systemdTypeNotify = "notify"
+)
+
+// SystemdUnitCommand generates a systemd unit that runs a command with the secrets of an env file.
+type SystemdUnitCommand struct {
+	io              ui.IO
+	osEnv           []string
+	readFile        func(filename string) ([]byte, error)
+	command         []string
+	envFile         string
+	templateVars    map[string]string
+	templateVersion string
+	serviceType     string
+	user            string
+	description     string
+	dropIn          bool
+	outFile         string
+	secrethubPath   string
+}
+
+// NewSystemdUnitCommand creates a new SystemdUnitCommand.
+func NewSystemdUnitCommand(io ui.IO) *SystemdUnitCommand {
+	return &SystemdUnitCommand{
+		io:           io,
+		osEnv:        os.Environ(),
+		readFile:     ioutil.ReadFile,
+		templateVars: make(map[string]string),
+	}
+}
+
+// Register registers the command, arguments and flags on the provided Registerer.
+func (cmd *SystemdUnitCommand) Register(r command.Registerer) {
+	clause := r.Command("unit", "Generate a systemd unit that runs a command with the secrets of an env file.")
+	clause.HelpLong("Generates a systemd service unit, or a drop-in for an existing unit with --drop-in, that starts the command with `secrethub run`. " +
+		"The env file is checked when the unit is generated. The template variables it uses are defined in the unit, " +
+		"so the unit does not depend on the environment it is generated in. " +
+		"Variables with a default value or an error message (${var:-default} or ${var:?message}) are left out when they have no value." +
+		"\n\nA service of Type=notify can send its notifications as usual, `secrethub run` passes them on to systemd.")
+	clause.Arg("command", "The command to execute").Required().StringsVar(&cmd.command)
+	clause.Flag("env-file", "The path to the env file with the environment of the command.").Default(defaultEnvFile).StringVar(&cmd.envFile)
+	clause.Flag("var", "Define the value for a template variable with `VAR=VALUE`, e.g. --var env=prod").Short('v').StringMapVar(&cmd.templateVars)
+	clause.Flag("template-version", "The template syntax version to be used. The options are v1, v2, v3, latest or auto to automatically detect the version.").Default("auto").StringVar(&cmd.templateVersion)
+	clause.Flag("type", "The Type= of the service.").Default(systemdTypeSimple).EnumVar(&cmd.serviceType, systemdTypeSimple, systemdTypeExec, systemdTypeNotify)
+	clause.Flag("user", "The User= to run the service as.").StringVar(&cmd.user)
+	clause.Flag("description", "The Description= of the unit. Defaults to a description of the command.").StringVar(&cmd.description)
+	clause.Flag("drop-in", "Generate a drop-in that replaces the ExecStart= of an existing unit, instead of a complete unit.").BoolVar(&cmd.dropIn)
+	clause.Flag("out-file", "Write the unit to a file instead of stdout.").Short('o').StringVar(&cmd.outFile)
+	clause.Flag("secrethub-path", "The path to the secrethub executable in the unit. Defaults to the path of this executable.").StringVar(&cmd.secrethubPath)
+
+	command.BindAction(clause, cmd.Run)
+}
+
+// Run generates the unit and writes it to stdout or the output file.
+func (cmd *SystemdUnitCommand) Run() error {
+	unit, err := cmd.unit()
+	if err != nil {
+		return err
+	}
+
+	if cmd.outFile == "" {
+		fmt.Fprint(cmd.io.Stdout(), unit)
+		return nil
+	}
+
+	err = ioutil.WriteFile(cmd.outFile, []byte(unit), 0644)
+	if err != nil {
+		return ErrCannotWrite(cmd.outFile, err)
+	}
+	fmt.Fprintln(cmd.io.Stdout(), cmd.outFile)
+	return nil
+}
+
+// unit returns the contents of the unit file.
+func (cmd *SystemdUnitCommand) unit() (string, error) {
+	envFile, err := filepath.Abs(cmd.envFile)
+	if err != nil {
+		return "", ErrCannotReadFile(cmd.envFile, err)
+	}
+
+	vars, err := cmd.unitVars(envFile)
+	if err != nil {
+		return "", err
+	}
+
+	secrethubPath := cmd.secrethubPath
+	if secrethubPath == "" {
+		secrethubPath, err = os.Executable()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	execStart := []string{secrethubPath, "run", "--env-file=" + envFile, "--no-prompt"}
+	if cmd.templateVersion != "auto" {
+		execStart = append(execStart, "--template-version="+cmd.templateVersion)
+	}
+	execStart = append(execStart, "--")
+	execStart = append(execStart, cmd.command...)
+
+	var unit bytes.Buffer
+	if !cmd.dropIn {
+		description := cmd.description
+		if description == "" {
+			description = filepath.Base(cmd.command[0]) + " with secrets from SecretHub"
+		}
+
+		fmt.Fprintln(&unit, "[Unit]")
+		fmt.Fprintf(&unit, "Description=%s\n", systemdEscapeSpecifiers(description))
+		fmt.Fprintln(&unit, "Wants=network-online.target")
+		fmt.Fprintln(&unit, "After=network-online.target")
+		fmt.Fprintln(&unit)
+	}
+
+	fmt.Fprintln(&unit, "[Service]")
+	if !cmd.dropIn {
+		fmt.Fprintf(&unit, "Type=%s\n", cmd.serviceType)
+	} else if cmd.serviceType != systemdTypeSimple {
+		fmt.Fprintf(&unit, "Type=%s\n", cmd.serviceType)
+	}
+	if cmd.user != "" {
+		fmt.Fprintf(&unit, "User=%s\n", systemdEscapeSpecifiers(cmd.user))
+	}
+	for _, name := range sortedKeys(vars) {
+		assignment := templateVarEnvVarPrefix + strings.ToUpper(name) + "=" + vars[name]
+		fmt.Fprintf(&unit, "Environment=%s\n", systemdQuote(systemdEscapeSpecifiers(assignment)))
+	}
+	if cmd.dropIn {
+		// An empty ExecStart= resets the ExecStart= of the unit, which would otherwise be extended.
+		fmt.Fprintln(&unit, "ExecStart=")
+	}
+	fmt.Fprintf(&unit, "ExecStart=%s\n", systemdCommandLine(execStart))
+	if !cmd.dropIn {
+		fmt.Fprintln(&unit, "Restart=on-failure")
+		fmt.Fprintln(&unit)
+		fmt.Fprintln(&unit, "[Install]")
+		fmt.Fprintln(&unit, "WantedBy=multi-user.target")
+	}
+	return unit.String(), nil
+}
+
+// unitVars checks the env file and returns the template variables it uses that have a value.
+// An error is returned when a variable without a default value or error message has no value.
+func (cmd *SystemdUnitCommand) unitVars(envFile string) (map[string]string, error) {
+	osEnvMap, _ := parseKeyValueStringsToMap(cmd.osEnv)
+	varReader, err := newVariableReader(osEnvMap, cmd.templateVars)
+	if err != nil {
+		return nil, err
+	}
+	recorder := &recordingVariableReader{
+		varReader: varReader.(*variableReader),
+		used:      make(map[string]string),
+		missing:   make(map[string]bool),
+	}
+
+	raw, err := cmd.readFile(envFile)
+	if err != nil {
+		return nil, ErrCannotReadFile(envFile, err)
+	}
+
+	parser, err := getTemplateParser(raw, cmd.templateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := ReadEnvFile(envFile, bytes.NewReader(raw), osEnvMap, recorder, parser)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := file.env()
+	if err != nil {
+		return nil, err
+	}
+
+	// The secrets are not read, only the variables in the templates are evaluated.
+	for _, value := range env {
+		_, err = value.resolve(secretReaderFunc(func(string) (string, error) {
+			return "", nil
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = parseEnvDirDirectives(raw, recorder, parser)
+	if err != nil {
+		return nil, ErrParsingTemplate(envFile, err)
+	}
+
+	if len(recorder.missing) > 0 {
+		missing := make([]string, 0, len(recorder.missing))
+		for name := range recorder.missing {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return nil, ErrMissingUnitVars(strings.Join(missing, ", "))
+	}
+	return recorder.used, nil
+}
+
+// recordingVariableReader is a template variable reader that records the variables
+// that are read. Missing variables are replaced by their name, so that the
+// evaluation of a template continues and all its variables are recorded.
+type recordingVariableReader struct {
+	varReader *variableReader
+	used      map[string]string
+	missing   map[string]bool
+}
+
+// ReadVariable records the variable and returns its value, or its name when it has no value.
+func (r *recordingVariableReader) ReadVariable(name string) (string, error) {
+	value, ok, _ := r.varReader.LookupVariable(name)
+	if !ok {
+		r.missing[name] = true
+		return name, nil
+	}
+	r.used[name] = value
+	return value, nil
+}
+
+// LookupVariable records the variable and returns its value. A missing variable is not
+// reported, as it has a default value or an error message.
+func (r *recordingVariableReader) LookupVariable(name string) (string, bool, error) {
+	value, ok, _ := r.varReader.LookupVariable(name)
+	if ok {
+		r.used[name] = value
+	}
+	return value, ok, nil
+}
+
+// systemdCommandLine returns the arguments as a command line of an Exec*= setting.
+func systemdCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		arg = strings.Replace(arg, "$", "$$", -1)
+		quoted[i] = systemdQuote(systemdEscapeSpecifiers(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// systemdEscapeSpecifiers escapes the % that systemd uses for specifiers, such as %n and %i.
+func systemdEscapeSpecifiers(s string) string {
+	return strings.Replace(s, "%", "%%", -1)
+}
+
+// systemdQuote returns the value in double quotes when it contains characters
+// that systemd would otherwise interpret, such as white space and quotes.
+func systemdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n\"'\\;") {
+		return s
+	}
+
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteRune(r)
+		case '\n':
+			quoted.WriteString(`\n`)
+		case '\t':
+			quoted.WriteString(`\t`)
+		default:
+			quoted.WriteRune(r)
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
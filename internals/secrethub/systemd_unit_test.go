@@ -0,0 +1,131 @@
+package secrethub
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/cli/ui"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestSystemdUnitCommand_Run(t *testing.T) {
+	cases := map[string]struct {
+		cmd     SystemdUnitCommand
+		envFile string
+		out     string
+		err     error
+	}{
+		"unit": {
+			cmd: SystemdUnitCommand{
+				command:         []string{"/usr/bin/app", "--name", "my app"},
+				templateVars:    map[string]string{"env": "prod", "unused": "value"},
+				templateVersion: "auto",
+				serviceType:     systemdTypeSimple,
+			},
+			envFile: "DB_PASSWORD={{ company/app/${env}/db/password }}",
+			out: "[Unit]\n" +
+				"Description=app with secrets from SecretHub\n" +
+				"Wants=network-online.target\n" +
+				"After=network-online.target\n" +
+				"\n" +
+				"[Service]\n" +
+				"Type=simple\n" +
+				"Environment=SECRETHUB_VAR_ENV=prod\n" +
+				"ExecStart=/usr/local/bin/secrethub run --env-file=/etc/app/secrethub.env --no-prompt -- /usr/bin/app --name \"my app\"\n" +
+				"Restart=on-failure\n" +
+				"\n" +
+				"[Install]\n" +
+				"WantedBy=multi-user.target\n",
+		},
+		"notify unit with user": {
+			cmd: SystemdUnitCommand{
+				command:         []string{"/usr/bin/app"},
+				osEnv:           []string{"SECRETHUB_VAR_REGION=eu west"},
+				templateVars:    map[string]string{},
+				templateVersion: "2",
+				serviceType:     systemdTypeNotify,
+				user:            "app",
+				description:     "My app",
+			},
+			envFile: "DB_HOST=db.${region}.example.com",
+			out: "[Unit]\n" +
+				"Description=My app\n" +
+				"Wants=network-online.target\n" +
+				"After=network-online.target\n" +
+				"\n" +
+				"[Service]\n" +
+				"Type=notify\n" +
+				"User=app\n" +
+				"Environment=\"SECRETHUB_VAR_REGION=eu west\"\n" +
+				"ExecStart=/usr/local/bin/secrethub run --env-file=/etc/app/secrethub.env --no-prompt --template-version=2 -- /usr/bin/app\n" +
+				"Restart=on-failure\n" +
+				"\n" +
+				"[Install]\n" +
+				"WantedBy=multi-user.target\n",
+		},
+		"drop-in": {
+			cmd: SystemdUnitCommand{
+				command:         []string{"/usr/bin/app", "--price=$5", "100%"},
+				templateVars:    map[string]string{},
+				templateVersion: "auto",
+				serviceType:     systemdTypeSimple,
+				dropIn:          true,
+			},
+			envFile: "DB_PASSWORD={{ company/app/db/password }}",
+			out: "[Service]\n" +
+				"ExecStart=\n" +
+				"ExecStart=/usr/local/bin/secrethub run --env-file=/etc/app/secrethub.env --no-prompt -- /usr/bin/app --price=$$5 100%%\n",
+		},
+		"optional variables without value": {
+			cmd: SystemdUnitCommand{
+				command:         []string{"/usr/bin/app"},
+				templateVars:    map[string]string{},
+				templateVersion: "auto",
+				serviceType:     systemdTypeSimple,
+				dropIn:          true,
+			},
+			envFile: "DB_PASSWORD={{ company/app/${env:-dev}/db/password }}",
+			out: "[Service]\n" +
+				"ExecStart=\n" +
+				"ExecStart=/usr/local/bin/secrethub run --env-file=/etc/app/secrethub.env --no-prompt -- /usr/bin/app\n",
+		},
+		"missing variables": {
+			cmd: SystemdUnitCommand{
+				command:         []string{"/usr/bin/app"},
+				templateVars:    map[string]string{},
+				templateVersion: "auto",
+				serviceType:     systemdTypeSimple,
+			},
+			envFile: "DB_PASSWORD={{ company/${project}/${env}/db/password }}\nDB_HOST=${region}.example.com",
+			err:     ErrMissingUnitVars("env, project, region"),
+		},
+		"invalid env file": {
+			cmd: SystemdUnitCommand{
+				command:         []string{"/usr/bin/app"},
+				templateVars:    map[string]string{},
+				templateVersion: "auto",
+				serviceType:     systemdTypeSimple,
+			},
+			envFile: "DB_PASSWORD",
+			err:     ErrParsingTemplate("/etc/app/secrethub.env", ErrTemplate(1, errors.New("template is not formatted as key=value pairs"))),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := ui.NewFakeIO()
+			tc.cmd.io = io
+			tc.cmd.envFile = "/etc/app/secrethub.env"
+			tc.cmd.secrethubPath = "/usr/local/bin/secrethub"
+			tc.cmd.readFile = func(filename string) ([]byte, error) {
+				assert.Equal(t, filename, "/etc/app/secrethub.env")
+				return []byte(tc.envFile), nil
+			}
+
+			err := tc.cmd.Run()
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, io.StdOut.String(), tc.out)
+		})
+	}
+}
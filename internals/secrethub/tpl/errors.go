@@ -7,6 +7,11 @@ import (
 // Evaluate errors
 var (
 	ErrTemplateVarNotFound = tplError.Code("template_var_not_found").ErrorPref("no value was supplied for template variable '%s'")
+	ErrTemplateVarRequired = tplError.Code("template_var_required").ErrorPref("template variable '%s' is required: %s")
+	ErrFilterFailed        = tplError.Code("filter_failed").ErrorPref("cannot apply filter '%s' to secret %s: %s")
+	ErrInvalidField        = tplError.Code("invalid_field").ErrorPref("invalid field '%s': a field is a list of keys separated by dots, e.g. database.user")
+	ErrSecretNotStructured = tplError.Code("secret_not_structured").ErrorPref("cannot read field '%s' of secret %s: the secret does not contain a JSON or YAML object or array")
+	ErrFieldNotFound       = tplError.Code("field_not_found").ErrorPref("field '%s' not found in secret %s")
 )
 
 // Parse errors
@@ -73,3 +78,43 @@ func ErrVariableTagNotClosed(lineNo, colNo int) error {
 		msg:    "expected the closing of a variable tag `}`, but reached the end of the template.",
 	}
 }
+
+// ErrIllegalFilterCharacter is returned when a filter name contains a character that is not allowed.
+func ErrIllegalFilterCharacter(lineNo, colNo int, char rune) error {
+	return templateSyntaxError{
+		lineNo: lineNo,
+		colNo:  colNo,
+		code:   "illegal_filter_character",
+		msg:    fmt.Sprintf("illegal character '%c'. Filter names can only contain lowercase letters, digits and underscores.", char),
+	}
+}
+
+// ErrUnknownFilter is returned when a secret tag contains a filter that does not exist.
+func ErrUnknownFilter(lineNo, colNo int, name string) error {
+	return templateSyntaxError{
+		lineNo: lineNo,
+		colNo:  colNo,
+		code:   "unknown_filter",
+		msg:    fmt.Sprintf("unknown filter '%s'. The options are %s.", name, filterNames()),
+	}
+}
+
+// ErrFilterArguments is returned when a filter is given the wrong number of arguments.
+func ErrFilterArguments(lineNo, colNo int, name string, expected int) error {
+	return templateSyntaxError{
+		lineNo: lineNo,
+		colNo:  colNo,
+		code:   "filter_arguments",
+		msg:    fmt.Sprintf("filter '%s' takes %d quoted argument(s)", name, expected),
+	}
+}
+
+// ErrIllegalFieldCharacter is returned when the field of a secret tag contains a character that is not allowed.
+func ErrIllegalFieldCharacter(lineNo, colNo int, char rune) error {
+	return templateSyntaxError{
+		lineNo: lineNo,
+		colNo:  colNo,
+		code:   "illegal_field_character",
+		msg:    fmt.Sprintf("illegal character '%c'. Fields can only contain letters, digits, underscores, hyphens and dots.", char),
+	}
+}
@@ -1,6 +1,6 @@
 package fakes
 
-import "errors"
+import "github.com/secrethub/secrethub-go/internals/api"
 
 // FakeSecretReader implements tpl.SecretReader.
 type FakeSecretReader struct {
@@ -13,5 +13,5 @@ func (fsr FakeSecretReader) ReadSecret(path string) (string, error) {
 	if ok {
 		return secret, nil
 	}
-	return "", errors.New("secret not found")
+	return "", api.ErrSecretNotFound
 }
@@ -0,0 +1,147 @@
+package tpl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ExtractField returns the value of a field of a secret that contains a JSON or
+// YAML document. The field is a dot-separated list of object keys and array
+// indices, e.g. `database.hosts.0`. A string field is returned as is, other
+// fields are returned as JSON.
+func ExtractField(path, value, field string) (string, error) {
+	res, found, err := extractField(path, value, field)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", ErrFieldNotFound(field, path)
+	}
+	return res, nil
+}
+
+// extractField returns the value of the field and whether the field exists.
+func extractField(path, value, field string) (string, bool, error) {
+	if !isValidField(field) {
+		return "", false, ErrInvalidField(field)
+	}
+
+	doc, err := decodeJSON(value)
+	if err != nil {
+		err = yaml.Unmarshal([]byte(value), &doc)
+	}
+	if err != nil || !isStructured(doc) {
+		return "", false, ErrSecretNotStructured(field, path)
+	}
+
+	current := doc
+	for _, key := range strings.Split(field, ".") {
+		var ok bool
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current, ok = node[key]
+		case map[interface{}]interface{}:
+			current, ok = node[key]
+		case []interface{}:
+			var i int
+			i, err = strconv.Atoi(key)
+			ok = err == nil && i >= 0 && i < len(node)
+			if ok {
+				current = node[i]
+			}
+		}
+		if !ok {
+			return "", false, nil
+		}
+	}
+
+	if s, ok := current.(string); ok {
+		return s, true, nil
+	}
+
+	out, err := json.Marshal(jsonCompatible(current))
+	if err != nil {
+		return "", false, err
+	}
+	return string(out), true, nil
+}
+
+// decodeJSON decodes a JSON document. Numbers are decoded as json.Number, so that
+// they are returned exactly as they are written, even when they do not fit in a float64.
+func decodeJSON(value string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+
+	var doc interface{}
+	err := decoder.Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = decoder.Token()
+	if err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+	return doc, nil
+}
+
+// SplitField splits a reference of the form path#field into the path and the field.
+// The field is empty when the reference does not contain a field.
+func SplitField(ref string) (string, string) {
+	i := strings.IndexByte(ref, '#')
+	if i < 0 {
+		return ref, ""
+	}
+	return ref[:i], ref[i+1:]
+}
+
+// isValidField returns whether the field is a dot-separated list of non-empty keys.
+func isValidField(field string) bool {
+	for _, key := range strings.Split(field, ".") {
+		if key == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isStructured returns whether the decoded document is an object or an array.
+func isStructured(doc interface{}) bool {
+	switch doc.(type) {
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// jsonCompatible converts the maps decoded from YAML, which can have
+// keys of any type, to maps with string keys so they can be encoded as JSON.
+func jsonCompatible(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(node))
+		for key, value := range node {
+			res[fmt.Sprint(key)] = jsonCompatible(value)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(node))
+		for key, value := range node {
+			res[key] = jsonCompatible(value)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(node))
+		for i, value := range node {
+			res[i] = jsonCompatible(value)
+		}
+		return res
+	}
+	return v
+}
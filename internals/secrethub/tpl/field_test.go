@@ -0,0 +1,111 @@
+package tpl
+
+import (
+	"testing"
+
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestExtractField(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		field    string
+		expected string
+		err      error
+	}{
+		"JSON string": {
+			value:    `{"client_email": "app@example.com"}`,
+			field:    "client_email",
+			expected: "app@example.com",
+		},
+		"JSON nested": {
+			value:    `{"db": {"user": "admin"}}`,
+			field:    "db.user",
+			expected: "admin",
+		},
+		"JSON array index": {
+			value:    `{"hosts": ["db1", "db2"]}`,
+			field:    "hosts.1",
+			expected: "db2",
+		},
+		"JSON number": {
+			value:    `{"port": 5432}`,
+			field:    "port",
+			expected: "5432",
+		},
+		"JSON integer above 2^53": {
+			value:    `{"id": 9007199254740993}`,
+			field:    "id",
+			expected: "9007199254740993",
+		},
+		"JSON object with integer above 2^53": {
+			value:    `{"account": {"id": 9007199254740993}}`,
+			field:    "account",
+			expected: `{"id":9007199254740993}`,
+		},
+		"JSON object": {
+			value:    `{"db": {"port": 5432}}`,
+			field:    "db",
+			expected: `{"port":5432}`,
+		},
+		"top-level array": {
+			value:    `["a", "b"]`,
+			field:    "0",
+			expected: "a",
+		},
+		"YAML": {
+			value:    "db:\n  user: admin\n  port: 5432\n",
+			field:    "db.user",
+			expected: "admin",
+		},
+		"YAML object": {
+			value:    "db:\n  port: 5432\n",
+			field:    "db",
+			expected: `{"port":5432}`,
+		},
+		"missing field": {
+			value: `{"db": {"user": "admin"}}`,
+			field: "db.password",
+			err:   ErrFieldNotFound("db.password", "path/to/secret"),
+		},
+		"field of string": {
+			value: `{"db": "admin"}`,
+			field: "db.user",
+			err:   ErrFieldNotFound("db.user", "path/to/secret"),
+		},
+		"index out of range": {
+			value: `["a"]`,
+			field: "1",
+			err:   ErrFieldNotFound("1", "path/to/secret"),
+		},
+		"not structured": {
+			value: "plain text",
+			field: "user",
+			err:   ErrSecretNotStructured("user", "path/to/secret"),
+		},
+		"invalid field": {
+			value: `{"db": {"user": "admin"}}`,
+			field: "db..user",
+			err:   ErrInvalidField("db..user"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ExtractField("path/to/secret", tc.value, tc.field)
+
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestSplitField(t *testing.T) {
+	path, field := SplitField("path/to/secret#db.user")
+	assert.Equal(t, path, "path/to/secret")
+	assert.Equal(t, field, "db.user")
+
+	path, field = SplitField("path/to/secret")
+	assert.Equal(t, path, "path/to/secret")
+	assert.Equal(t, field, "")
+}
@@ -0,0 +1,111 @@
+package tpl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// filterFunc transforms the value of a secret with the given arguments.
+type filterFunc func(value string, args []string) (string, error)
+
+// filterDef defines a filter that can be used in a secret tag.
+type filterDef struct {
+	args  int
+	apply filterFunc
+}
+
+// filters are the filters that can be used in v3 secret tags, by name.
+// The default filter is handled by the secret, as it also applies to missing secrets.
+var filters = map[string]filterDef{
+	"base64": {
+		apply: func(value string, _ []string) (string, error) {
+			return base64.StdEncoding.EncodeToString([]byte(value)), nil
+		},
+	},
+	"b64decode": {
+		apply: func(value string, _ []string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+			if err != nil {
+				return "", err
+			}
+			return string(decoded), nil
+		},
+	},
+	"json_escape": {
+		apply: func(value string, _ []string) (string, error) {
+			quoted, err := jsonQuote(value)
+			if err != nil {
+				return "", err
+			}
+			return quoted[1 : len(quoted)-1], nil
+		},
+	},
+	"yaml_quote": {
+		// A JSON string is also a valid double-quoted YAML scalar.
+		apply: func(value string, _ []string) (string, error) {
+			return jsonQuote(value)
+		},
+	},
+	"trim": {
+		apply: func(value string, _ []string) (string, error) {
+			return strings.TrimSpace(value), nil
+		},
+	},
+	"upper": {
+		apply: func(value string, _ []string) (string, error) {
+			return strings.ToUpper(value), nil
+		},
+	},
+	filterDefault: {
+		args: 1,
+		apply: func(value string, args []string) (string, error) {
+			if value == "" {
+				return args[0], nil
+			}
+			return value, nil
+		},
+	},
+}
+
+// filterDefault replaces empty and missing secrets with its argument.
+const filterDefault = "default"
+
+// jsonQuote returns the value as a JSON string, including the quotes.
+func jsonQuote(value string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// filterNames returns a comma separated list of the available filters.
+func filterNames() string {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// filter is a filter in a secret tag with its arguments.
+type filter struct {
+	name string
+	args []string
+}
+
+// apply applies the filter to the value of the secret at the given path.
+func (f filter) apply(path, value string) (string, error) {
+	res, err := filters[f.name].apply(value, f.args)
+	if err != nil {
+		return "", ErrFilterFailed(f.name, path, err)
+	}
+	return res, nil
+}
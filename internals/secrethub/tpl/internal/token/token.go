@@ -8,6 +8,13 @@ var (
 	Backslash = '\\'
 
 	tokens = []rune{Dollar, LBracket, RBracket, Backslash}
+
+	// Hash separates the field from the path in a secret tag.
+	Hash = '#'
+	// Pipe separates the filters in a secret tag.
+	Pipe = '|'
+	// DoubleQuote encloses the arguments of a filter.
+	DoubleQuote = '"'
 )
 
 // IsToken returns whether the given rune is a token.
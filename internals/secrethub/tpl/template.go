@@ -16,18 +16,28 @@ type Parser interface {
 	Parse(raw string, column, line int) (Template, error)
 }
 
+// Escaper is implemented by parsers that can escape text, so that it is
+// parsed as plain text instead of as tags.
+type Escaper interface {
+	Escape(text string) string
+}
+
 // Template contains secret and variable references. It can be evaluated to resolve to a string.
 type Template interface {
 	// Evaluate renders a template. It replaces all variable- and secret tags in the template.
 	// The supplied variables should have lowercase keys.
 	Evaluate(varReader VariableReader, sr SecretReader) (string, error)
 
+	// SecretPaths returns the paths of the secrets in the template, with the
+	// variables in the paths replaced by their values.
+	SecretPaths(varReader VariableReader) ([]string, error)
+
 	ContainsSecrets() bool
 }
 
 // NewParser returns a parser for the latest template syntax.
 func NewParser() Parser {
-	return NewV2Parser()
+	return NewV3Parser()
 }
 
 var v1SecretTag = regexp.MustCompile(`\${[\t ]*[_\-\.a-zA-Z0-9]+/[_\-\.a-zA-Z0-9]+(?:/[_\-\.a-zA-Z0-9]+)+(?::(?:[0-9]{1,9}|latest))?[\t ]*}`)
@@ -49,6 +49,11 @@ func (t templateV1) Evaluate(_ VariableReader, sr SecretReader) (string, error)
 	return t.template.Inject(secrets)
 }
 
+// SecretPaths returns the paths of the secrets in the template.
+func (t templateV1) SecretPaths(_ VariableReader) ([]string, error) {
+	return t.template.Keys(), nil
+}
+
 func (t templateV1) ContainsSecrets() bool {
 	return len(t.template.Keys()) > 0
 }
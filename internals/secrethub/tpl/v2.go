@@ -7,6 +7,8 @@ import (
 	"unicode"
 
 	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl/internal/token"
+
+	"github.com/secrethub/secrethub-go/internals/api"
 )
 
 // NewV2Parser returns a parser for the v2 template syntax.
@@ -20,6 +22,12 @@ import (
 // {{ ${app}/db/secret }}
 // Variables cannot be used outside of secret paths.
 //
+// Like in a shell, a variable can have a default value that is used
+// when the variable is not set or empty, or an error message that is
+// returned when the variable is not set or empty:
+// {{ ${env:-dev}/db/secret }}
+// {{ ${region:?must set region}/db/secret }}
+//
 // Spaces directly after opening delimiters (`{{` and `${`) and directly
 // before closing delimiters (`}}`, `}`) are ignored. They are not
 // included in the secret pahts and variable names.
@@ -36,15 +44,84 @@ func (ctx context) secret(path string) (string, error) {
 	return ctx.secretReader.ReadSecret(path)
 }
 
+// recordValue passes a value that is derived from a secret to the secret reader,
+// when the secret reader records the values it returns.
+func (ctx context) recordValue(value string) {
+	recorder, ok := ctx.secretReader.(ValueRecorder)
+	if ok {
+		recorder.RecordValue(value)
+	}
+}
+
 type node interface {
 	evaluate(ctx context) (string, error)
 }
 
 type secret struct {
-	path []node
+	path    []node
+	field   string
+	filters []filter
 }
 
+// evaluate returns the value of the secret, or of its field, with the filters
+// applied in order. A secret or field that does not exist is only allowed when it
+// is followed by a default filter. The filters before the default filter are then skipped.
+// The field and the result of every filter that is applied to the secret are recorded,
+// as they can differ from the secret as a whole.
 func (s secret) evaluate(ctx context) (string, error) {
+	path, err := s.evaluatePath(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := ctx.secret(path)
+	missing := api.IsErrNotFound(err)
+	if err != nil && !missing {
+		return "", err
+	}
+
+	if s.field != "" && !missing {
+		var found bool
+		value, found, err = extractField(path, value, s.field)
+		if err != nil {
+			return "", err
+		}
+		if found {
+			ctx.recordValue(value)
+		} else {
+			missing = true
+			err = ErrFieldNotFound(s.field, path)
+		}
+	}
+
+	// Once a default filter has replaced the secret, the value is no longer derived from it.
+	derived := !missing
+	for _, f := range s.filters {
+		if missing && f.name != filterDefault {
+			continue
+		}
+		if f.name == filterDefault && value == "" {
+			derived = false
+		}
+		missing = false
+
+		value, err = f.apply(path, value)
+		if err != nil {
+			return "", err
+		}
+		if derived {
+			ctx.recordValue(value)
+		}
+	}
+
+	if missing {
+		return "", err
+	}
+	return value, nil
+}
+
+// evaluatePath returns the path of the secret with all variables replaced.
+func (s secret) evaluatePath(ctx context) (string, error) {
 	var buffer bytes.Buffer
 	for _, p := range s.path {
 		eval, err := p.evaluate(ctx)
@@ -54,19 +131,64 @@ func (s secret) evaluate(ctx context) (string, error) {
 
 		buffer.WriteString(eval)
 	}
-	return ctx.secret(buffer.String())
+	return buffer.String(), nil
 }
 
+const (
+	// operatorDefault replaces an unset or empty variable with the operand: ${var:-default}.
+	operatorDefault = ":-"
+	// operatorRequired fails with the operand as message for an unset or empty variable: ${var:?message}.
+	operatorRequired = ":?"
+)
+
 type variable struct {
-	key string
+	key      string
+	operator string
+	operand  string
 }
 
 func (v variable) evaluate(ctx context) (string, error) {
-	res, err := ctx.varReader.ReadVariable(v.key)
+	if v.operator == "" {
+		res, err := ctx.varReader.ReadVariable(v.key)
+		if err != nil {
+			return "", err
+		}
+		return res, nil
+	}
+
+	res, ok, err := LookupVariable(ctx.varReader, v.key)
 	if err != nil {
 		return "", err
 	}
-	return res, nil
+	if ok && res != "" {
+		return res, nil
+	}
+
+	if v.operator == operatorRequired {
+		message := v.operand
+		if message == "" {
+			message = "no value was supplied"
+		}
+		return "", ErrTemplateVarRequired(v.key, message)
+	}
+	return v.operand, nil
+}
+
+// LookupVariable returns the variable and whether it is set, without falling back
+// to other sources of variables, such as asking the user, when the reader supports it.
+func LookupVariable(varReader VariableReader, name string) (string, bool, error) {
+	lookuper, ok := varReader.(VariableLookuper)
+	if ok {
+		return lookuper.LookupVariable(name)
+	}
+
+	res, err := varReader.ReadVariable(name)
+	if err == ErrTemplateVarNotFound(name) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return res, true, nil
 }
 
 type character rune
@@ -92,6 +214,9 @@ type parserV2 struct{}
 //   closing delimiter of a tag: {{ path/to/secret }} has the same output as
 //   {{path/to/secret}} has.
 // - Secret tags can also contain variable tags: `{{ path/with/${var}/to/secret }}`
+// - Variable tags can contain a default value: `${ var:-default }`, or an error
+//   message for when the variable is not set: `${ var:?message }`. A closing
+//   bracket and a backslash in them can be escaped with a backslash.
 // - Variable tags cannot contain secret tags.
 // - Secret tags cannot contain secret tags (they cannot be nested).
 // - Variable tags cannot contain variable tags (they cannot be nested).
@@ -108,6 +233,24 @@ func (p parserV2) Parse(raw string, line, column int) (Template, error) {
 	}, nil
 }
 
+// Escape escapes the tokens in text with a backslash, so that it is parsed as plain text.
+func (p parserV2) Escape(text string) string {
+	return escapeV2(text)
+}
+
+// escapeV2 escapes the tokens in text with a backslash, so that it is parsed as
+// plain text by the v2 and v3 parsers.
+func escapeV2(text string) string {
+	var res strings.Builder
+	for _, r := range text {
+		if token.IsToken(r) {
+			res.WriteRune(token.Backslash)
+		}
+		res.WriteRune(r)
+	}
+	return res.String()
+}
+
 func newV2Parser(buf *bytes.Buffer, line, column int) v2Parser {
 	return v2Parser{
 		buf:    buf,
@@ -124,6 +267,9 @@ type v2Parser struct {
 	lineNo   int
 	columnNo int
 
+	// v3 enables the filters and field extraction of the v3 syntax in secret tags.
+	v3 bool
+
 	current rune
 	next    rune
 }
@@ -289,10 +435,66 @@ func (p *v2Parser) parseVar() (node, error) {
 			continue
 		}
 
+		if p.next == ':' && buffer.Len() > 0 {
+			err := p.readRune()
+			if err != nil {
+				return nil, checkError(err)
+			}
+
+			if p.next != '-' && p.next != '?' {
+				return nil, ErrIllegalVariableCharacter(p.lineNo, p.columnNo, p.current)
+			}
+
+			err = p.readRune()
+			if err != nil {
+				return nil, checkError(err)
+			}
+			operator := string([]rune{':', p.current})
+
+			operand, err := p.parseVarOperand()
+			if err != nil {
+				return nil, checkError(err)
+			}
+
+			return variable{
+				key:      strings.ToLower(buffer.String()),
+				operator: operator,
+				operand:  operand,
+			}, nil
+		}
+
 		return nil, ErrIllegalVariableCharacter(p.lineNo, p.columnNo+1, p.next)
 	}
 }
 
+// parseVarOperand parses the default value or error message of a variable tag,
+// in which a closing bracket and a backslash can be escaped with a backslash.
+// Whitespace just before the closing delimiter is not included in the operand.
+// The current character should be the last character of the operator when
+// parseVarOperand is called.
+//
+// When parseVarOperand returns, the next character in the buffer is the closing
+// delimiter of the template variable ('}').
+func (p *v2Parser) parseVarOperand() (string, error) {
+	var buffer bytes.Buffer
+	for p.next != token.RBracket {
+		err := p.readRune()
+		if err != nil {
+			return "", err
+		}
+
+		if p.current == token.Backslash && (p.next == token.RBracket || p.next == token.Backslash) {
+			err := p.readRune()
+			if err != nil {
+				return "", err
+			}
+		}
+
+		buffer.WriteRune(p.current)
+	}
+	return strings.TrimRightFunc(buffer.String(), p.isAllowedWhiteSpace), nil
+}
+
 // parseSecret parses the contents of a secret tag up to the closing delimiter.
 // The next character should be the last character of the opening delimiter ('{')
 // when parseSecret is called.
@@ -354,12 +556,58 @@ func (p *v2Parser) parseSecret() (node, error) {
 			return nil, ErrIllegalSecretCharacter(p.lineNo, p.columnNo, p.current)
 		}
 
+		if p.v3 && p.current == token.Hash {
+			field, err := p.parseField()
+			if err != nil {
+				return nil, checkError(err)
+			}
+
+			if p.next != token.Pipe && p.next != token.RBracket && !p.isAllowedWhiteSpace(p.next) {
+				return nil, ErrIllegalFieldCharacter(p.lineNo, p.columnNo+1, p.next)
+			}
+
+			secret, err := p.parseSecretEnd(path, field)
+			if err != nil {
+				return nil, checkError(err)
+			}
+			return secret, nil
+		}
+
+		if p.v3 && p.current == token.Pipe {
+			filters, err := p.parseFilters()
+			if err != nil {
+				return nil, checkError(err)
+			}
+
+			return secret{
+				path:    path,
+				filters: filters,
+			}, nil
+		}
+
 		if p.isAllowedWhiteSpace(p.current) {
 			err := p.skipWhiteSpace()
 			if err != nil {
 				return nil, checkError(err)
 			}
 
+			if p.v3 && p.next == token.Pipe {
+				err = p.readRune()
+				if err != nil {
+					return nil, checkError(err)
+				}
+
+				filters, err := p.parseFilters()
+				if err != nil {
+					return nil, checkError(err)
+				}
+
+				return secret{
+					path:    path,
+					filters: filters,
+				}, nil
+			}
+
 			if p.next != token.RBracket {
 				return nil, ErrUnexpectedCharacter(p.lineNo, p.columnNo+1, p.next, token.RBracket)
 			}
@@ -396,6 +644,188 @@ func (p *v2Parser) parseSecret() (node, error) {
 	}
 }
 
+// parseField parses the field of a secret tag. The current character should be
+// the hash before the field when parseField is called.
+//
+// When parseField returns, the current character is the last character of the field.
+func (p *v2Parser) parseField() (string, error) {
+	var buffer bytes.Buffer
+	for p.isFieldRune(p.next) {
+		buffer.WriteRune(p.next)
+
+		err := p.readRune()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if buffer.Len() == 0 {
+		return "", ErrIllegalFieldCharacter(p.lineNo, p.columnNo+1, p.next)
+	}
+	return buffer.String(), nil
+}
+
+// parseSecretEnd parses the end of a secret tag after the field, which can
+// contain filters.
+//
+// When parseSecretEnd returns, the next character in the buffer is the last
+// character of the closing delimiter of the secret tag ('}').
+func (p *v2Parser) parseSecretEnd(path []node, field string) (node, error) {
+	err := p.skipWhiteSpace()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.readRune()
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []filter
+	if p.current == token.Pipe {
+		filters, err = p.parseFilters()
+		if err != nil {
+			return nil, err
+		}
+	} else if p.current != token.RBracket {
+		return nil, ErrUnexpectedCharacter(p.lineNo, p.columnNo, p.current, token.RBracket)
+	} else if p.next != token.RBracket {
+		return nil, ErrUnexpectedCharacter(p.lineNo, p.columnNo+1, p.next, token.RBracket)
+	}
+
+	return secret{
+		path:    path,
+		field:   field,
+		filters: filters,
+	}, nil
+}
+
+// parseFilters parses the filters of a secret tag up to the closing delimiter.
+// The current character should be the pipe before the first filter when
+// parseFilters is called.
+//
+// When parseFilters returns, the next character in the buffer is the last
+// character of the closing delimiter of the secret tag ('}').
+func (p *v2Parser) parseFilters() ([]filter, error) {
+	var res []filter
+	for {
+		err := p.skipWhiteSpace()
+		if err != nil {
+			return nil, err
+		}
+
+		lineNo, columnNo := p.lineNo, p.columnNo+1
+
+		var name bytes.Buffer
+		for p.isFilterNameRune(p.next) {
+			name.WriteRune(p.next)
+
+			err := p.readRune()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if name.Len() == 0 {
+			return nil, ErrIllegalFilterCharacter(p.lineNo, p.columnNo+1, p.next)
+		}
+
+		def, ok := filters[name.String()]
+		if !ok {
+			return nil, ErrUnknownFilter(lineNo, columnNo, name.String())
+		}
+
+		var args []string
+		for {
+			err := p.skipWhiteSpace()
+			if err != nil {
+				return nil, err
+			}
+
+			if p.next != token.DoubleQuote {
+				break
+			}
+
+			arg, err := p.parseFilterArgument()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+
+		if len(args) != def.args {
+			return nil, ErrFilterArguments(lineNo, columnNo, name.String(), def.args)
+		}
+
+		res = append(res, filter{
+			name: name.String(),
+			args: args,
+		})
+
+		err = p.readRune()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case p.current == token.Pipe:
+			continue
+		case p.current == token.RBracket && p.next == token.RBracket:
+			return res, nil
+		case p.current == token.RBracket:
+			return nil, ErrUnexpectedCharacter(p.lineNo, p.columnNo+1, p.next, token.RBracket)
+		default:
+			return nil, ErrUnexpectedCharacter(p.lineNo, p.columnNo, p.current, token.RBracket)
+		}
+	}
+}
+
+// parseFilterArgument parses a double-quoted filter argument, in which a quote
+// and a backslash can be escaped with a backslash. The next character should be
+// the opening quote when parseFilterArgument is called.
+//
+// When parseFilterArgument returns, the current character is the closing quote.
+func (p *v2Parser) parseFilterArgument() (string, error) {
+	var buffer bytes.Buffer
+
+	err := p.readRune()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		err := p.readRune()
+		if err != nil {
+			return "", err
+		}
+
+		if p.current == token.Backslash && (p.next == token.DoubleQuote || p.next == token.Backslash) {
+			err := p.readRune()
+			if err != nil {
+				return "", err
+			}
+			buffer.WriteRune(p.current)
+			continue
+		}
+
+		if p.current == token.DoubleQuote {
+			return buffer.String(), nil
+		}
+
+		buffer.WriteRune(p.current)
+	}
+}
+
+// isFieldRune returns whether the given rune is allowed to be used in the field of a secret tag.
+func (p v2Parser) isFieldRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// isFilterNameRune returns whether the given rune is allowed to be used in a filter name.
+func (p v2Parser) isFilterNameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_'
+}
+
 // isSecretPathRune returns whether the given rune is allowed to be used in
 // a secret path.
 func (p v2Parser) isSecretPathRune(r rune) bool {
@@ -434,11 +864,25 @@ type SecretReader interface {
 	ReadSecret(path string) (string, error)
 }
 
+// ValueRecorder is implemented by secret readers that record the secrets they return,
+// e.g. to mask them. The values that are derived from a secret in a template, such as
+// its fields and the results of filters, are recorded with it, as they must be masked as well.
+type ValueRecorder interface {
+	RecordValue(value string)
+}
+
 // VariableReader fetches a template variable by its name.
 type VariableReader interface {
 	ReadVariable(name string) (string, error)
 }
 
+// VariableLookuper is implemented by variable readers that can report that a
+// variable is not set, instead of falling back to asking the user for it.
+// It is used for variables with a default value or a custom error message.
+type VariableLookuper interface {
+	LookupVariable(name string) (string, bool, error)
+}
+
 // Evaluate renders a template. It replaces all variable- and secret tags in the template.
 // The supplied variables should have lowercase keys.
 func (t templateV2) Evaluate(varReader VariableReader, sr SecretReader) (string, error) {
@@ -460,6 +904,30 @@ func (t templateV2) Evaluate(varReader VariableReader, sr SecretReader) (string,
 	return buffer.String(), nil
 }
 
+// SecretPaths returns the paths of the secrets in the template, with the
+// variables in the paths replaced by their values.
+func (t templateV2) SecretPaths(varReader VariableReader) ([]string, error) {
+	ctx := context{
+		varReader: varReader,
+	}
+
+	var paths []string
+	for _, n := range t.nodes {
+		s, ok := n.(secret)
+		if !ok {
+			continue
+		}
+
+		path, err := s.evaluatePath(ctx)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
 func (t templateV2) ContainsSecrets() bool {
 	for _, node := range t.nodes {
 		_, ok := node.(secret)
@@ -126,6 +126,62 @@ func TestParserV2_parse(t *testing.T) {
 				character('r'),
 			},
 		},
+		"variable with default": {
+			input: "${ env:-dev }",
+			expected: []node{
+				variable{
+					key:      "env",
+					operator: operatorDefault,
+					operand:  "dev",
+				},
+			},
+		},
+		"variable with empty default": {
+			input: "${env:-}",
+			expected: []node{
+				variable{
+					key:      "env",
+					operator: operatorDefault,
+				},
+			},
+		},
+		"variable with default containing special characters": {
+			input: `${url:-{{ x \}\}\\ ${y}`,
+			expected: []node{
+				variable{
+					key:      "url",
+					operator: operatorDefault,
+					operand:  `{{ x }}\ ${y`,
+				},
+			},
+		},
+		"variable with error message": {
+			input: "${region:?must set region}",
+			expected: []node{
+				variable{
+					key:      "region",
+					operator: operatorRequired,
+					operand:  "must set region",
+				},
+			},
+		},
+		"variable with default in secret": {
+			input: "{{ ${app:-company/app}/db }}",
+			expected: []node{
+				secret{
+					path: []node{
+						variable{
+							key:      "app",
+							operator: operatorDefault,
+							operand:  "company/app",
+						},
+						character('/'),
+						character('d'),
+						character('b'),
+					},
+				},
+			},
+		},
 		"variable without brackets": {
 			input: "$var-foo",
 			expected: []node{
@@ -540,6 +596,14 @@ func TestParserV2_parse(t *testing.T) {
 			input: "${ var@var }",
 			err:   ErrIllegalVariableCharacter(1, 7, '@'),
 		},
+		"illegal variable operator": {
+			input: "${ var:=default }",
+			err:   ErrIllegalVariableCharacter(1, 7, ':'),
+		},
+		"variable operator without name": {
+			input: "${:-default}",
+			err:   ErrIllegalVariableCharacter(1, 3, ':'),
+		},
 		"illegal secret character": {
 			input: "{{ a@b }}",
 			err:   ErrIllegalSecretCharacter(1, 5, '@'),
@@ -604,6 +668,14 @@ func TestParserV2_parse(t *testing.T) {
 			input: "${ var ",
 			err:   ErrVariableTagNotClosed(1, 8),
 		},
+		"variable tag with default not closed": {
+			input: "${ var:-default",
+			err:   ErrVariableTagNotClosed(1, 16),
+		},
+		"variable tag with escaped bracket not closed": {
+			input: "${ var:-default\\}",
+			err:   ErrVariableTagNotClosed(1, 18),
+		},
 		"variable tag not closed at start of tag": {
 			input: "${",
 			err:   ErrVariableTagNotClosed(1, 3),
@@ -678,6 +750,42 @@ func TestV2(t *testing.T) {
 			},
 			evalErr: errors.New("variable not found: app"),
 		},
+		"default for missing var": {
+			raw: "hello {{ ${app:-company/helloworld}/greeting }}",
+			secrets: map[string]string{
+				"company/helloworld/greeting": "world",
+			},
+			expected: "hello world",
+		},
+		"default for empty var": {
+			raw: "env=${env:-dev}",
+			vars: map[string]string{
+				"env": "",
+			},
+			expected: "env=dev",
+		},
+		"default not used for set var": {
+			raw: "env=${env:-dev}",
+			vars: map[string]string{
+				"env": "prd",
+			},
+			expected: "env=prd",
+		},
+		"required var": {
+			raw: "region=${region:?must set region}",
+			vars: map[string]string{
+				"region": "eu-west-1",
+			},
+			expected: "region=eu-west-1",
+		},
+		"missing required var": {
+			raw:     "region=${region:?must set region}",
+			evalErr: ErrTemplateVarRequired("region", "must set region"),
+		},
+		"missing required var without message": {
+			raw:     "region=${region:?}",
+			evalErr: ErrTemplateVarRequired("region", "no value was supplied"),
+		},
 	}
 
 	for name, tc := range cases {
@@ -695,3 +803,46 @@ func TestV2(t *testing.T) {
 		})
 	}
 }
+
+func TestV2_SecretPaths(t *testing.T) {
+	cases := map[string]struct {
+		raw      string
+		vars     map[string]string
+		expected []string
+		err      error
+	}{
+		"no secrets": {
+			raw: "hello world",
+		},
+		"secrets": {
+			raw:      "{{ company/app/user }}:{{ company/app/password }}",
+			expected: []string{"company/app/user", "company/app/password"},
+		},
+		"template var in secret": {
+			raw: "hello {{ ${app}/greeting }}",
+			vars: map[string]string{
+				"app": "company/helloworld",
+			},
+			expected: []string{"company/helloworld/greeting"},
+		},
+		"missing var": {
+			raw: "hello {{ ${app}/greeting }}",
+			err: errors.New("variable not found: app"),
+		},
+		"default for missing var": {
+			raw:      "hello {{ ${app:-company/helloworld}/greeting }}",
+			expected: []string{"company/helloworld/greeting"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := NewV2Parser().Parse(tc.raw, 1, 1)
+			assert.OK(t, err)
+
+			actual, err := parsed.SecretPaths(fakes.FakeVariableReader{Variables: tc.vars})
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
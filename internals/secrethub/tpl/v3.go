@@ -0,0 +1,61 @@
+package tpl
+
+import (
+	"bytes"
+)
+
+// NewV3Parser returns a parser for the v3 template syntax.
+//
+// V3 templates extend the v2 syntax with fields and filters. A field
+// selects a value from a secret that contains a JSON or YAML document:
+// {{ path/to/secret#database.user }}
+//
+// Filters transform the value of a secret. Filters are separated from the secret path and
+// from each other by pipes and are applied from left to right:
+// {{ path/to/secret | trim | base64 }}
+//
+// Filters can take arguments, which are given between double quotes:
+// {{ path/to/secret | default "value" }}
+//
+// Every v2 template is a valid v3 template with the same output.
+func NewV3Parser() Parser {
+	return parserV3{}
+}
+
+type parserV3 struct{}
+
+// Escape escapes the tokens in text with a backslash, so that it is parsed as plain text.
+func (p parserV3) Escape(text string) string {
+	return escapeV2(text)
+}
+
+// Parse parses a secret template from a raw string.
+//
+// Syntax rules, in addition to the rules of the v2 syntax:
+// - A secret path can be followed by a hash and a field: `{{ path/to/secret#field }}`.
+//   Nested fields and array elements are separated by dots: `{{ path/to/secret#hosts.0 }}`.
+// - A secret tag can contain filters after the path: `{{ path/to/secret | upper }}`.
+// - Extra spaces can be added around the pipes and between a filter and its arguments.
+// - Filter arguments are enclosed in double quotes. Within an argument, a double
+//   quote and a backslash can be escaped with a backslash: `"say \"hi\""`.
+// - The available filters are:
+//   - base64: encodes the value with standard base64 encoding.
+//   - b64decode: decodes a value with standard base64 encoding.
+//   - json_escape: escapes the value for use in a JSON string.
+//   - yaml_quote: returns the value as a double-quoted YAML string.
+//   - trim: removes leading and trailing whitespace.
+//   - upper: converts the value to upper case.
+//   - default "x": replaces an empty value or a secret or field that does not exist with x.
+func (p parserV3) Parse(raw string, line, column int) (Template, error) {
+	parser := newV2Parser(bytes.NewBufferString(raw), line, column)
+	parser.v3 = true
+
+	nodes, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return templateV2{
+		nodes: nodes,
+	}, nil
+}
@@ -0,0 +1,360 @@
+package tpl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/secrethub/secrethub-cli/internals/secrethub/tpl/fakes"
+
+	"github.com/secrethub/secrethub-go/internals/api"
+	"github.com/secrethub/secrethub-go/internals/assert"
+)
+
+func TestParserV3_parse(t *testing.T) {
+	path := []node{character('a'), character('/'), character('b')}
+
+	cases := map[string]struct {
+		input    string
+		expected []node
+		err      error
+	}{
+		"secret without filters": {
+			input:    "{{ a/b }}",
+			expected: []node{secret{path: path}},
+		},
+		"filter": {
+			input: "{{ a/b | base64 }}",
+			expected: []node{secret{
+				path:    path,
+				filters: []filter{{name: "base64"}},
+			}},
+		},
+		"filter without spaces": {
+			input: "{{a/b|base64}}",
+			expected: []node{secret{
+				path:    path,
+				filters: []filter{{name: "base64"}},
+			}},
+		},
+		"multiple filters": {
+			input: "{{ a/b | trim |upper| base64 }}",
+			expected: []node{secret{
+				path:    path,
+				filters: []filter{{name: "trim"}, {name: "upper"}, {name: "base64"}},
+			}},
+		},
+		"filter with argument": {
+			input: `{{ a/b | default "x" }}`,
+			expected: []node{secret{
+				path:    path,
+				filters: []filter{{name: "default", args: []string{"x"}}},
+			}},
+		},
+		"filter with escaped argument": {
+			input: `{{ a/b | default "say \"hi\" \\ }}"}}`,
+			expected: []node{secret{
+				path:    path,
+				filters: []filter{{name: "default", args: []string{`say "hi" \ }}`}}},
+			}},
+		},
+		"filter with variable in path": {
+			input: "{{ ${app}/b | upper }}",
+			expected: []node{secret{
+				path:    []node{variable{key: "app"}, character('/'), character('b')},
+				filters: []filter{{name: "upper"}},
+			}},
+		},
+		"field": {
+			input: "{{ a/b#db.user }}",
+			expected: []node{secret{
+				path:  path,
+				field: "db.user",
+			}},
+		},
+		"field with filter": {
+			input: "{{a/b#hosts.0|upper}}",
+			expected: []node{secret{
+				path:    path,
+				field:   "hosts.0",
+				filters: []filter{{name: "upper"}},
+			}},
+		},
+		"field with spaces and filter": {
+			input: "{{ a/b#user | upper }}",
+			expected: []node{secret{
+				path:    path,
+				field:   "user",
+				filters: []filter{{name: "upper"}},
+			}},
+		},
+		"empty field": {
+			input: "{{ a/b# }}",
+			err:   ErrIllegalFieldCharacter(1, 8, ' '),
+		},
+		"illegal field character": {
+			input: "{{ a/b#db/user }}",
+			err:   ErrIllegalFieldCharacter(1, 10, '/'),
+		},
+		"unexpected character after field": {
+			input: "{{ a/b#user x }}",
+			err:   ErrUnexpectedCharacter(1, 13, 'x', '}'),
+		},
+		"field not closed": {
+			input: "{{ a/b#user",
+			err:   ErrSecretTagNotClosed(1, 12),
+		},
+		"unknown filter": {
+			input: "{{ a/b | lower }}",
+			err:   ErrUnknownFilter(1, 10, "lower"),
+		},
+		"unknown filter on second line": {
+			input: "foo\n{{ a/b | trim | foo }}",
+			err:   ErrUnknownFilter(2, 17, "foo"),
+		},
+		"missing filter name": {
+			input: "{{ a/b | }}",
+			err:   ErrIllegalFilterCharacter(1, 10, '}'),
+		},
+		"illegal filter character": {
+			input: "{{ a/b | Upper }}",
+			err:   ErrIllegalFilterCharacter(1, 10, 'U'),
+		},
+		"missing argument": {
+			input: "{{ a/b | default }}",
+			err:   ErrFilterArguments(1, 10, "default", 1),
+		},
+		"unexpected argument": {
+			input: `{{ a/b | upper "x" }}`,
+			err:   ErrFilterArguments(1, 10, "upper", 0),
+		},
+		"unexpected character after filter": {
+			input: "{{ a/b | upper! }}",
+			err:   ErrUnexpectedCharacter(1, 15, '!', '}'),
+		},
+		"filter not closed": {
+			input: "{{ a/b | upper",
+			err:   ErrSecretTagNotClosed(1, 15),
+		},
+		"argument not closed": {
+			input: `{{ a/b | default "x }}`,
+			err:   ErrSecretTagNotClosed(1, 23),
+		},
+		"single closing bracket": {
+			input: "{{ a/b | upper }x",
+			err:   ErrUnexpectedCharacter(1, 17, 'x', '}'),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parser := newV2Parser(bytes.NewBufferString(tc.input), 1, 1)
+			parser.v3 = true
+			actual, err := parser.parse()
+
+			assert.Equal(t, err, tc.err)
+			if tc.err == nil {
+				assert.Equal(t, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestV2_Filters(t *testing.T) {
+	_, err := NewV2Parser().Parse("{{ a/b | upper }}", 1, 1)
+	assert.Equal(t, err, ErrUnexpectedCharacter(1, 8, '|', '}'))
+}
+
+func TestV3(t *testing.T) {
+	cases := map[string]struct {
+		raw     string
+		secrets map[string]string
+
+		expected string
+		evalErr  error
+	}{
+		"no filters": {
+			raw:      "{{ app/db/pass }}",
+			secrets:  map[string]string{"app/db/pass": "secret"},
+			expected: "secret",
+		},
+		"base64": {
+			raw:      "{{ app/db/cert | base64 }}",
+			secrets:  map[string]string{"app/db/cert": "cert"},
+			expected: "Y2VydA==",
+		},
+		"b64decode": {
+			raw:      "{{ app/db/cert | b64decode }}",
+			secrets:  map[string]string{"app/db/cert": "Y2VydA==\n"},
+			expected: "cert",
+		},
+		"b64decode invalid": {
+			raw:     "{{ app/db/cert | b64decode }}",
+			secrets: map[string]string{"app/db/cert": "not base64"},
+			evalErr: ErrFilterFailed("b64decode", "app/db/cert", base64.CorruptInputError(3)),
+		},
+		"json_escape": {
+			raw:      `{"password": "{{ app/db/pass | json_escape }}"}`,
+			secrets:  map[string]string{"app/db/pass": "a\"b\\c\n<d>"},
+			expected: `{"password": "a\"b\\c\n<d>"}`,
+		},
+		"yaml_quote": {
+			raw:      "password: {{ app/db/pass | yaml_quote }}",
+			secrets:  map[string]string{"app/db/pass": "a: b\n# c"},
+			expected: `password: "a: b\n# c"`,
+		},
+		"trim": {
+			raw:      "{{ app/db/pass | trim }}",
+			secrets:  map[string]string{"app/db/pass": " \tsecret\n"},
+			expected: "secret",
+		},
+		"upper": {
+			raw:      "{{ app/db/user | upper }}",
+			secrets:  map[string]string{"app/db/user": "admin"},
+			expected: "ADMIN",
+		},
+		"chained filters": {
+			raw:      "{{ app/db/user | trim | upper | base64 }}",
+			secrets:  map[string]string{"app/db/user": "admin\n"},
+			expected: "QURNSU4=",
+		},
+		"default for empty secret": {
+			raw:      `{{ app/db/user | default "root" }}`,
+			secrets:  map[string]string{"app/db/user": ""},
+			expected: "root",
+		},
+		"default for existing secret": {
+			raw:      `{{ app/db/user | default "root" }}`,
+			secrets:  map[string]string{"app/db/user": "admin"},
+			expected: "admin",
+		},
+		"default for missing secret": {
+			raw:      `{{ app/db/user | trim | default "root" | upper }}`,
+			expected: "ROOT",
+		},
+		"field": {
+			raw:      "{{ app/db/config#user }}:{{ app/db/config#password }}",
+			secrets:  map[string]string{"app/db/config": `{"user": "admin", "password": "secret"}`},
+			expected: "admin:secret",
+		},
+		"nested field of YAML secret with filter": {
+			raw:      "{{ app/db/config#db.hosts.1 | upper }}",
+			secrets:  map[string]string{"app/db/config": "db:\n  hosts:\n    - db1\n    - db2\n"},
+			expected: "DB2",
+		},
+		"missing field": {
+			raw:     "{{ app/db/config#host }}",
+			secrets: map[string]string{"app/db/config": `{"user": "admin"}`},
+			evalErr: ErrFieldNotFound("host", "app/db/config"),
+		},
+		"missing field with default": {
+			raw:      `{{ app/db/config#host | default "localhost" }}`,
+			secrets:  map[string]string{"app/db/config": `{"user": "admin"}`},
+			expected: "localhost",
+		},
+		"field of unstructured secret": {
+			raw:     `{{ app/db/pass#user | default "admin" }}`,
+			secrets: map[string]string{"app/db/pass": "secret"},
+			evalErr: ErrSecretNotStructured("user", "app/db/pass"),
+		},
+		"missing secret without default": {
+			raw:     "{{ app/db/user | trim }}",
+			evalErr: api.ErrSecretNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := NewV3Parser().Parse(tc.raw, 1, 1)
+			assert.OK(t, err)
+
+			actual, err := parsed.Evaluate(fakes.FakeVariableReader{}, fakes.FakeSecretReader{Secrets: tc.secrets})
+			assert.Equal(t, err, tc.evalErr)
+			assert.Equal(t, actual, tc.expected)
+		})
+	}
+}
+
+func TestV3_RecordValue(t *testing.T) {
+	cases := map[string]struct {
+		raw      string
+		secrets  map[string]string
+		expected []string
+	}{
+		"no filters": {
+			raw:      "{{ app/db/pass }}",
+			secrets:  map[string]string{"app/db/pass": "secret"},
+			expected: nil,
+		},
+		"chained filters": {
+			raw:      "{{ app/db/pass | b64decode | trim | upper }}",
+			secrets:  map[string]string{"app/db/pass": "IHNlY3JldAo="},
+			expected: []string{" secret\n", "secret", "SECRET"},
+		},
+		"field": {
+			raw:      "{{ app/db/config#password | upper }}",
+			secrets:  map[string]string{"app/db/config": `{"user": "admin", "password": "secret"}`},
+			expected: []string{"secret", "SECRET"},
+		},
+		"missing field with default": {
+			raw:      `{{ app/db/config#password | default "root" }}`,
+			secrets:  map[string]string{"app/db/config": `{"user": "admin"}`},
+			expected: nil,
+		},
+		"default for existing secret": {
+			raw:      `{{ app/db/user | default "root" | upper }}`,
+			secrets:  map[string]string{"app/db/user": "admin"},
+			expected: []string{"admin", "ADMIN"},
+		},
+		"default for empty secret": {
+			raw:      `{{ app/db/user | default "root" | upper }}`,
+			secrets:  map[string]string{"app/db/user": ""},
+			expected: nil,
+		},
+		"default for missing secret": {
+			raw:      `{{ app/db/user | trim | default "root" | upper }}`,
+			expected: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := NewV3Parser().Parse(tc.raw, 1, 1)
+			assert.OK(t, err)
+
+			sr := &recordingSecretReader{FakeSecretReader: fakes.FakeSecretReader{Secrets: tc.secrets}}
+			_, err = parsed.Evaluate(fakes.FakeVariableReader{}, sr)
+			assert.OK(t, err)
+			assert.Equal(t, sr.recorded, tc.expected)
+		})
+	}
+}
+
+// recordingSecretReader records the values that are derived from the secrets it returns.
+type recordingSecretReader struct {
+	fakes.FakeSecretReader
+	recorded []string
+}
+
+func (sr *recordingSecretReader) RecordValue(value string) {
+	sr.recorded = append(sr.recorded, value)
+}
+
+func TestV3_SecretReaderError(t *testing.T) {
+	parsed, err := NewV3Parser().Parse(`{{ app/db/user | default "root" }}`, 1, 1)
+	assert.OK(t, err)
+
+	expected := errors.New("connection refused")
+	_, err = parsed.Evaluate(fakes.FakeVariableReader{}, errorSecretReader{err: expected})
+	assert.Equal(t, err, expected)
+}
+
+// errorSecretReader fails to read any secret with the given error.
+type errorSecretReader struct {
+	err error
+}
+
+func (sr errorSecretReader) ReadSecret(path string) (string, error) {
+	return "", sr.err
+}
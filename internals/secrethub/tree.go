@@ -16,13 +16,15 @@ type TreeCommand struct {
 	path      api.DirPath
 	io        ui.IO
 	newClient newClientFunc
+	output    *OutputConfig
 }
 
 // NewTreeCommand creates a new TreeCommand.
-func NewTreeCommand(io ui.IO, clientFactory newClientFunc) *TreeCommand {
+func NewTreeCommand(io ui.IO, clientFactory newClientFunc, output *OutputConfig) *TreeCommand {
 	return &TreeCommand{
 		io:        io,
 		newClient: clientFactory,
+		output:    output,
 	}
 }
 
@@ -38,6 +40,10 @@ func (cmd *TreeCommand) Run() error {
 		return err
 	}
 
+	if cmd.output.Format() != outputFormatTable {
+		return cmd.output.print(cmd.io.Stdout(), newTreeDirOutput(t.RootDir))
+	}
+
 	printTree(t, cmd.io.Stdout())
 	return nil
 }
@@ -98,3 +104,42 @@ func printDirContentsRecursively(dir *api.Dir, prefix string, w io.Writer) {
 		i++
 	}
 }
+
+// treeDirOutput is the structured output format of a directory in a tree.
+type treeDirOutput struct {
+	Name    string             `json:"name" yaml:"name"`
+	Status  string             `json:"status" yaml:"status"`
+	Dirs    []treeDirOutput    `json:"dirs" yaml:"dirs"`
+	Secrets []treeSecretOutput `json:"secrets" yaml:"secrets"`
+}
+
+// treeSecretOutput is the structured output format of a secret in a tree.
+type treeSecretOutput struct {
+	Name         string `json:"name" yaml:"name"`
+	Status       string `json:"status" yaml:"status"`
+	VersionCount int    `json:"version_count" yaml:"version_count"`
+}
+
+// newTreeDirOutput recursively converts a directory to its structured output format.
+func newTreeDirOutput(dir *api.Dir) treeDirOutput {
+	sort.Sort(api.SortDirByName(dir.SubDirs))
+	sort.Sort(api.SortSecretByName(dir.Secrets))
+
+	out := treeDirOutput{
+		Name:    dir.Name,
+		Status:  dir.Status,
+		Dirs:    make([]treeDirOutput, len(dir.SubDirs)),
+		Secrets: make([]treeSecretOutput, len(dir.Secrets)),
+	}
+	for i, sub := range dir.SubDirs {
+		out.Dirs[i] = newTreeDirOutput(sub)
+	}
+	for i, secret := range dir.Secrets {
+		out.Secrets[i] = treeSecretOutput{
+			Name:         secret.Name,
+			Status:       secret.Status,
+			VersionCount: secret.VersionCount,
+		}
+	}
+	return out
+}
@@ -51,6 +51,12 @@ func (v *variableReader) ReadVariable(name string) (string, error) {
 	return variable, nil
 }
 
+// LookupVariable fetches a template variable by name and returns whether it is set.
+func (v *variableReader) LookupVariable(name string) (string, bool, error) {
+	variable, ok := v.vars[name]
+	return variable, ok, nil
+}
+
 type promptMissingVariableReader struct {
 	reader  tpl.VariableReader
 	io      ui.IO
@@ -86,3 +92,20 @@ func (p *promptMissingVariableReader) ReadVariable(name string) (string, error)
 
 	return variable, err
 }
+
+// LookupVariable fetches a template variable without prompting the user if it is not found,
+// so that variables with a default value or a custom error message are never asked for.
+func (p *promptMissingVariableReader) LookupVariable(name string) (string, bool, error) {
+	variable, ok := p.answers[name]
+	if ok {
+		return variable, true, nil
+	}
+
+	variable, err := p.reader.ReadVariable(name)
+	if err == tpl.ErrTemplateVarNotFound(name) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return variable, true, nil
+}
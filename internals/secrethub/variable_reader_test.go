@@ -172,3 +172,41 @@ func TestPromptVariableReader(t *testing.T) {
 		})
 	}
 }
+
+func TestPromptVariableReader_Operators(t *testing.T) {
+	reader, err := newVariableReader(nil, map[string]string{"env": "prd"})
+	assert.OK(t, err)
+
+	cases := map[string]struct {
+		raw      string
+		expected string
+		err      error
+	}{
+		"default for set variable": {
+			raw:      "${env:-dev}",
+			expected: "prd",
+		},
+		"default for missing variable": {
+			raw:      "${region:-eu-west-1}",
+			expected: "eu-west-1",
+		},
+		"missing required variable": {
+			raw: "${region:?must set region}",
+			err: tpl.ErrTemplateVarRequired("region", "must set region"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			io := ui.NewFakeIO()
+
+			parsed, err := tpl.NewV2Parser().Parse(tc.raw, 1, 1)
+			assert.OK(t, err)
+
+			actual, err := parsed.Evaluate(newPromptMissingVariableReader(reader, io), nil)
+			assert.Equal(t, err, tc.err)
+			assert.Equal(t, actual, tc.expected)
+			assert.Equal(t, io.PromptOut.String(), "")
+		})
+	}
+}